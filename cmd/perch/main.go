@@ -1,20 +1,150 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kateleext/perch/internal/control"
+	"github.com/kateleext/perch/internal/debuglog"
+	"github.com/kateleext/perch/internal/git"
+	"github.com/kateleext/perch/internal/hooks"
 	"github.com/kateleext/perch/internal/ui"
+	"github.com/kateleext/perch/internal/watcher"
 )
 
 func main() {
-	// Get directory from args or use current
+	noNested := flag.Bool("no-nested", false, "don't scan for nested git repos")
+	nestedDepth := flag.Int("nested-depth", git.NestedRepoMaxDepth, "max directory depth scanned for nested git repos (0 = unlimited)")
+	logPath := flag.String("log", os.Getenv("PERCH_LOG"), "append debug logs to this file (or set PERCH_LOG)")
+	pprofAddr := flag.String("pprof", "", "serve net/http/pprof on this address (e.g. :6060)")
+	cpuProfile := flag.String("cpuprofile", "", "write a CPU profile to this file on exit")
+	memProfile := flag.String("memprofile", "", "write a heap profile to this file on exit")
+	perf := flag.Bool("perf", false, "overlay render, git refresh, and preview load times and goroutine/cache counts")
+	noSnapshots := flag.Bool("no-snapshots", false, "don't record versions of dirty files for the timeline (T)")
+	diffContext := flag.Int("context", ui.DiffContext, "lines of context around changes in diff-only view")
+	fetch := flag.Duration("fetch", 0, "run git fetch in the background at this interval (e.g. 5m) and show ↑/↓ counts; off by default")
+	conventional := flag.Bool("conventional", false, "guide commit messages into type(scope): subject form, with tab completion of types and recent scopes")
+	headerLimit := flag.Int("header-limit", ui.HeaderLimit, "longest commit header --conventional allows")
+	wip := flag.Duration("wip", 0, "commit the working tree to "+git.WIPRef+" at this interval (e.g. 5m), leaving HEAD and the index alone; off by default")
+	notifyMode := flag.String("notify", "off", "desktop notification on file changes: off, unfocused, or always")
+	bell := flag.String("bell", "", "ring the terminal bell when files change, for these kinds: new,modified,deleted,renamed or all")
+	flash := flag.String("flash", "", "flash the header when files change, for these kinds: new,modified,deleted,renamed or all")
+	churnDays := flag.Int("churn-days", ui.ChurnDays, "window of the churn dashboard (H), in days")
+	onChange := flag.String("on-change", os.Getenv("PERCH_ON_CHANGE"), "shell command to run after file changes, e.g. 'go test ./...' (or set PERCH_ON_CHANGE)")
+	onChangeTimeout := flag.Duration("on-change-timeout", ui.RunTimeout, "kill an --on-change run that takes longer than this")
+	socketPath := flag.String("socket", "", "serve the control API (select, refresh, status, quit) on this unix socket")
+	var hookSpecs stringList
+	flag.Var(&hookSpecs, "hook", "run a command on an event, as event=command (repeatable); events: "+strings.Join(hooks.Events, ", "))
+	accessible := flag.Bool("accessible", os.Getenv("PERCH_ACCESSIBLE") == "1", "screen-reader friendly output: no animation, box drawing, or backgrounds; selection announced (or set PERCH_ACCESSIBLE=1)")
+	reduceMotion := flag.Bool("reduce-motion", os.Getenv("PERCH_REDUCE_MOTION") == "1", "no sparkle, loading animation, or header flash, and no redraw on refreshes that found nothing (or set PERCH_REDUCE_MOTION=1)")
+	statusLine := flag.String("status-line", "off", "repo status line (branch, dirty files, ahead/behind, stashes): top, bottom, or off")
+	noLinks := flag.Bool("no-links", false, "don't emit OSC 8 hyperlinks for URLs in previews")
+	ambiguousWidth := flag.String("ambiguous-width", envOr("PERCH_AMBIGUOUS_WIDTH", "auto"), "columns for East Asian ambiguous-width characters: auto (from locale), narrow, or wide (or set PERCH_AMBIGUOUS_WIDTH)")
+	var include, exclude stringList
+	flag.Var(&include, "include", "only show paths matching these comma-separated git pathspecs, e.g. '*.go' (repeatable)")
+	flag.Var(&exclude, "exclude", "hide paths matching these comma-separated git pathspecs, e.g. 'vendor/**' (repeatable)")
+	skip := flag.String("skip", os.Getenv("PERCH_SKIP"), "comma-separated globs of extra files to hide, e.g. '*.generated.go,coverage/*' (or set PERCH_SKIP)")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: perch [flags] [dir]\n       perch [flags] view FILE\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *logPath != "" {
+		if err := debuglog.Open(*logPath); err != nil {
+			fmt.Printf("Error opening log file: %v\n", err)
+			os.Exit(1)
+		}
+		defer debuglog.Close()
+	}
+
+	stopProfiling, err := startProfiling(*pprofAddr, *cpuProfile, *memProfile)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	git.ScanNestedRepos = !*noNested
+	git.NestedRepoMaxDepth = *nestedDepth
+	ui.DiffContext = *diffContext
+	ui.FetchInterval = *fetch
+	ui.WIPInterval = *wip
+	ui.Conventional = *conventional
+	ui.HeaderLimit = *headerLimit
+	ui.ChurnDays = *churnDays
+	ui.OnChange = *onChange
+	ui.RunTimeout = *onChangeTimeout
+	ui.Accessible = *accessible
+	ui.ReduceMotion = *reduceMotion
+	ui.Hyperlinks = !*noLinks
+	ui.PerfOverlay = *perf
+	ui.Snapshots = !*noSnapshots
+	switch *statusLine {
+	case "top", "bottom":
+		ui.StatusLine = *statusLine
+	case "off", "":
+	default:
+		fmt.Printf("Unknown --status-line %q (want top, bottom, or off)\n", *statusLine)
+		os.Exit(2)
+	}
+	if err := ui.SetAmbiguousWidth(*ambiguousWidth); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	for _, spec := range hookSpecs {
+		if err := hooks.Register(spec); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	ui.BellOn = parseKindsFlag("bell", *bell)
+	ui.FlashOn = parseKindsFlag("flash", *flash)
+	switch *notifyMode {
+	case "off", "unfocused", "always":
+		ui.Notify = *notifyMode
+	default:
+		fmt.Printf("Invalid --notify value %q (want off, unfocused, or always)\n", *notifyMode)
+		os.Exit(1)
+	}
+	if *skip != "" {
+		var patterns []string
+		for _, p := range strings.Split(*skip, ",") {
+			patterns = append(patterns, strings.TrimSpace(p))
+		}
+		git.SetSkipPatterns(patterns)
+	}
+	git.SetPathspec(git.Pathspec{Include: include.split(), Exclude: exclude.split()})
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "include" || f.Name == "exclude" {
+			ui.RestoreFilters = false
+		}
+	})
+
+	// Get directory from args or use current; `view FILE` watches the
+	// file's directory and shows only that file
 	dir := "."
-	if len(os.Args) > 1 {
-		dir = os.Args[1]
+	viewFile := ""
+	if flag.Arg(0) == "view" {
+		if flag.NArg() != 2 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		info, err := os.Stat(flag.Arg(1))
+		if err != nil || info.IsDir() {
+			fmt.Printf("Not a file: %s\n", flag.Arg(1))
+			os.Exit(1)
+		}
+		dir = filepath.Dir(flag.Arg(1))
+		viewFile = filepath.Base(flag.Arg(1))
+	} else if flag.NArg() > 0 {
+		dir = flag.Arg(0)
 	}
 
 	// Convert to absolute path
@@ -31,28 +161,149 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Check if it's a git repo
-	cmd := exec.Command("git", "rev-parse", "--git-dir")
-	cmd.Dir = absDir
-	if err := cmd.Run(); err != nil {
-		fmt.Printf("Not a git repository: %s\n", absDir)
+	if !git.Available() {
+		fmt.Println("perch needs git, but it isn't on your PATH.")
+		fmt.Println("Install it (https://git-scm.com/downloads, `brew install git`, or `apt install git`) and run perch again.")
 		os.Exit(1)
 	}
 
+	// Outside a git repo, fall back to listing files by mtime
+	cmd := exec.Command("git", "rev-parse", "--git-dir")
+	cmd.Dir = absDir
+	plainDir := cmd.Run() != nil
+
 	// Check if this is a dev build
 	if os.Getenv("PERCH_DEV") == "1" {
 		ui.DevBuild = true
 	}
 
+	debuglog.Printf("perching on %s", absDir)
+
 	// Create and run the TUI
+	model := ui.New(absDir)
+	if plainDir {
+		debuglog.Printf("%s isn't a git repo; listing files by mtime", absDir)
+		model.SetPlainDir()
+	}
+	if viewFile != "" {
+		model.SetViewFile(viewFile)
+	}
 	p := tea.NewProgram(
-		ui.New(absDir),
+		model,
 		tea.WithAltScreen(),
 		tea.WithMouseAllMotion(),
+		tea.WithReportFocus(),
 	)
 
-	if _, err := p.Run(); err != nil {
+	// Watch for file changes in the background so startup isn't blocked
+	// walking a large tree; the 2s tick still refreshes if this fails
+	retarget := make(chan string, 1)
+	ui.WatchDir = func(dir string) {
+		// A newer target replaces one the watcher hasn't picked up yet
+		select {
+		case <-retarget:
+		default:
+		}
+		retarget <- dir
+	}
+	go watchChanges(p, absDir, retarget)
+
+	if *socketPath != "" {
+		srv, err := control.Listen(*socketPath)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer srv.Close()
+		go srv.Serve(func(command, arg string) control.Response {
+			return sendControl(p, command, arg)
+		})
+	}
+
+	final, err := p.Run()
+	stopProfiling()
+	if m, ok := final.(ui.Model); ok {
+		if err := m.SaveSession(); err != nil {
+			debuglog.Printf("saving session: %v", err)
+		}
+	}
+	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// watchChanges forwards file system events to the TUI as refreshes
+func watchChanges(p *tea.Program, dir string, retarget <-chan string) {
+	w, err := watcher.New(dir)
+	if err != nil {
+		debuglog.Printf("watcher disabled: %v", err)
+		return
+	}
+	w.Start()
+
+	for {
+		select {
+		case <-w.Changes:
+			p.Send(ui.FileChangedMsg{})
+		case <-w.NewRepos:
+			git.InvalidateNestedRepos()
+			p.Send(ui.RefreshMsg{})
+		case dir := <-retarget:
+			w.Retarget(dir)
+		}
+	}
+}
+
+// sendControl hands a control command to the TUI and waits for its answer
+func sendControl(p *tea.Program, command, arg string) control.Response {
+	reply := make(chan control.Response, 1)
+	p.Send(ui.ControlMsg{Command: command, Arg: arg, Reply: reply})
+	select {
+	case resp := <-reply:
+		return resp
+	case <-time.After(2 * time.Second):
+		return control.Error(errors.New("perch didn't respond"))
+	}
+}
+
+// parseKindsFlag parses a --bell/--flash value, exiting on unknown kinds
+func parseKindsFlag(name, value string) map[string]bool {
+	kinds, bad := ui.ParseKinds(value)
+	if bad != "" {
+		fmt.Printf("Invalid --%s kind %q (want new, modified, deleted, renamed, or all)\n", name, bad)
+		os.Exit(1)
+	}
+	return kinds
+}
+
+// envOr returns an environment variable, or def when it's unset
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// stringList collects a repeatable string flag
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ", ") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// split returns the values with comma-separated lists broken apart
+func (l stringList) split() []string {
+	var out []string
+	for _, v := range l {
+		for _, p := range strings.Split(v, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				out = append(out, p)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	_ "net/http/pprof" // registers /debug/pprof handlers
+	"os"
+	"runtime"
+	"runtime/pprof"
+
+	"github.com/kateleext/perch/internal/debuglog"
+)
+
+// startProfiling enables the requested profilers and returns a function that
+// writes any on-exit profiles. Errors are reported up front so a bad path
+// fails before the TUI takes over the terminal.
+func startProfiling(pprofAddr, cpuProfile, memProfile string) (stop func(), err error) {
+	if pprofAddr != "" {
+		go func() {
+			// Served on DefaultServeMux, which net/http/pprof registers into
+			if err := http.ListenAndServe(pprofAddr, nil); err != nil {
+				debuglog.Printf("pprof server on %s stopped: %v", pprofAddr, err)
+			}
+		}()
+		debuglog.Printf("pprof listening on %s", pprofAddr)
+	}
+
+	var cpuFile *os.File
+	if cpuProfile != "" {
+		cpuFile, err = os.Create(cpuProfile)
+		if err != nil {
+			return nil, fmt.Errorf("creating CPU profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(cpuFile); err != nil {
+			cpuFile.Close()
+			return nil, fmt.Errorf("starting CPU profile: %w", err)
+		}
+	}
+
+	stop = func() {
+		if cpuFile != nil {
+			pprof.StopCPUProfile()
+			cpuFile.Close()
+		}
+		if memProfile != "" {
+			f, err := os.Create(memProfile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error creating heap profile: %v\n", err)
+				return
+			}
+			defer f.Close()
+			runtime.GC() // report live objects, not garbage
+			if err := pprof.WriteHeapProfile(f); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing heap profile: %v\n", err)
+			}
+		}
+	}
+	return stop, nil
+}
@@ -0,0 +1,78 @@
+// Package clipboard copies text to the system clipboard from a terminal app
+package clipboard
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrUnavailable is returned when neither OSC 52 nor a clipboard tool could be used
+var ErrUnavailable = errors.New("no clipboard available")
+
+// OSC52 returns the escape sequence asking the terminal to set its clipboard,
+// wrapped for tmux passthrough when running under tmux
+func OSC52(text string) string {
+	seq := "\033]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if os.Getenv("TMUX") != "" {
+		seq = "\033Ptmux;\033" + seq + "\033\\"
+	}
+	return seq
+}
+
+// Copy sends text to the clipboard via OSC 52 (which works over SSH) and,
+// since terminals can't report whether they honoured it, also through a
+// native clipboard tool when one is installed
+func Copy(text string) error {
+	oscErr := writeTTY(OSC52(text))
+	nativeErr := copyNative(text)
+	if oscErr != nil && nativeErr != nil {
+		return ErrUnavailable
+	}
+	return nil
+}
+
+// writeTTY writes an escape sequence to the controlling terminal
+func writeTTY(seq string) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		_, err = os.Stdout.WriteString(seq)
+		return err
+	}
+	defer tty.Close()
+	_, err = tty.WriteString(seq)
+	return err
+}
+
+// nativeCommands lists clipboard tools to try, in order
+func nativeCommands() [][]string {
+	if runtime.GOOS == "darwin" {
+		return [][]string{{"pbcopy"}}
+	}
+	var cmds [][]string
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		cmds = append(cmds, []string{"wl-copy"})
+	}
+	return append(cmds,
+		[]string{"xclip", "-selection", "clipboard"},
+		[]string{"xsel", "--clipboard", "--input"},
+	)
+}
+
+// copyNative pipes text into the first available clipboard tool
+func copyNative(text string) error {
+	for _, args := range nativeCommands() {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err == nil {
+			return nil
+		}
+	}
+	return ErrUnavailable
+}
@@ -0,0 +1,109 @@
+// Package control serves a line-based command API on a unix socket so
+// editors and scripts can drive a running perch. Each request is one line,
+// "<command> [argument]", and gets one JSON object back.
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kateleext/perch/internal/debuglog"
+)
+
+// Response is the JSON object written back for each request
+type Response map[string]any
+
+// Error builds a failed response
+func Error(err error) Response {
+	return Response{"ok": false, "error": err.Error()}
+}
+
+// Handler answers one command
+type Handler func(command, arg string) Response
+
+// Server accepts connections on a unix socket
+type Server struct {
+	ln   net.Listener
+	path string
+}
+
+// Listen creates the socket at path, replacing a stale one left by a crash.
+// It refuses to replace a socket another perch is still serving, or a path
+// that isn't a socket.
+func Listen(path string) (*Server, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, errors.New(path + " exists and isn't a socket")
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, errors.New("socket " + path + " is in use")
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	// Bind inside a private directory and tighten the socket before moving
+	// it into place, so it is never reachable with umask permissions
+	tmp, err := os.MkdirTemp(filepath.Dir(path), ".perch-sock-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	bound := filepath.Join(tmp, "s")
+	ln, err := net.Listen("unix", bound)
+	if err != nil {
+		return nil, err
+	}
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := os.Chmod(bound, 0o600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	if err := os.Rename(bound, path); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return &Server{ln: ln, path: path}, nil
+}
+
+// Serve answers requests until the server is closed
+func (s *Server) Serve(handle Handler) {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.serveConn(conn, handle)
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn, handle Handler) {
+	defer conn.Close()
+	enc := json.NewEncoder(conn)
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		command, arg, _ := strings.Cut(line, " ")
+		debuglog.Printf("control: %s %q", command, arg)
+		if err := enc.Encode(handle(command, strings.TrimSpace(arg))); err != nil {
+			return
+		}
+	}
+}
+
+// Close stops accepting connections and removes the socket
+func (s *Server) Close() error {
+	err := s.ln.Close()
+	os.Remove(s.path)
+	return err
+}
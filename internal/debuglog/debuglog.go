@@ -0,0 +1,59 @@
+// Package debuglog writes an optional diagnostic log for perch. Logging is a
+// no-op until Open is called, so it never writes to the terminal the TUI owns.
+package debuglog
+
+import (
+	"log"
+	"os"
+	"sync"
+)
+
+var (
+	mu     sync.Mutex
+	logger *log.Logger
+	file   *os.File
+)
+
+// Open starts appending log lines to path
+func Open(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	file = f
+	logger = log.New(f, "", log.LstdFlags|log.Lmicroseconds)
+	logger.Printf("--- perch started (pid %d) ---", os.Getpid())
+	return nil
+}
+
+// Enabled reports whether a log file is open
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return logger != nil
+}
+
+// Printf writes one log line when logging is enabled
+func Printf(format string, args ...any) {
+	mu.Lock()
+	defer mu.Unlock()
+	if logger == nil {
+		return
+	}
+	logger.Printf(format, args...)
+}
+
+// Close flushes and closes the log file
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if file == nil {
+		return nil
+	}
+	err := file.Close()
+	file = nil
+	logger = nil
+	return err
+}
@@ -0,0 +1,127 @@
+// Package gh reads pull request status through the GitHub CLI
+package gh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Timeout bounds a single gh invocation (it talks to the network)
+var Timeout = 15 * time.Second
+
+// ErrNoPR is returned when the current branch has no pull request
+var ErrNoPR = errors.New("no pull request for this branch")
+
+// Available reports whether the gh CLI is installed
+func Available() bool {
+	_, err := exec.LookPath("gh")
+	return err == nil
+}
+
+// PRStatus summarizes the pull request for the current branch
+type PRStatus struct {
+	Number  int
+	State   string // "OPEN", "MERGED", "CLOSED"
+	Review  string // "APPROVED", "CHANGES_REQUESTED", "REVIEW_REQUIRED", or ""
+	CI      string // "passing", "failing", "pending", or "" when there are no checks
+	IsDraft bool
+}
+
+// check is one entry of gh's statusCheckRollup (check runs and commit statuses)
+type check struct {
+	Status     string `json:"status"`     // check runs: QUEUED, IN_PROGRESS, COMPLETED
+	Conclusion string `json:"conclusion"` // check runs: SUCCESS, FAILURE, ...
+	State      string `json:"state"`      // commit statuses: SUCCESS, PENDING, FAILURE, ERROR
+}
+
+// GetPRStatus returns the PR for the branch checked out in dir
+func GetPRStatus(ctx context.Context, dir string) (PRStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "gh", "pr", "view", "--json", "number,state,reviewDecision,isDraft,statusCheckRollup")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && strings.Contains(string(exitErr.Stderr), "no pull requests found") {
+			return PRStatus{}, ErrNoPR
+		}
+		return PRStatus{}, fmt.Errorf("gh pr view: %w", err)
+	}
+
+	var raw struct {
+		Number            int     `json:"number"`
+		State             string  `json:"state"`
+		ReviewDecision    string  `json:"reviewDecision"`
+		IsDraft           bool    `json:"isDraft"`
+		StatusCheckRollup []check `json:"statusCheckRollup"`
+	}
+	if err := json.Unmarshal(output, &raw); err != nil {
+		return PRStatus{}, fmt.Errorf("gh pr view: %w", err)
+	}
+	return PRStatus{
+		Number:  raw.Number,
+		State:   raw.State,
+		Review:  raw.ReviewDecision,
+		IsDraft: raw.IsDraft,
+		CI:      ciState(raw.StatusCheckRollup),
+	}, nil
+}
+
+// ciState folds individual checks into one state; any failure wins, then pending
+func ciState(checks []check) string {
+	if len(checks) == 0 {
+		return ""
+	}
+	pending := false
+	for _, c := range checks {
+		switch {
+		case c.Conclusion == "FAILURE" || c.Conclusion == "TIMED_OUT" || c.Conclusion == "CANCELLED" ||
+			c.Conclusion == "ACTION_REQUIRED" || c.State == "FAILURE" || c.State == "ERROR":
+			return "failing"
+		case c.State == "PENDING" || c.State == "EXPECTED" || (c.Status != "" && c.Status != "COMPLETED"):
+			pending = true
+		}
+	}
+	if pending {
+		return "pending"
+	}
+	return "passing"
+}
+
+// Summary renders the status compactly for a header, e.g. "#12 approved · ci ✓"
+func (s PRStatus) Summary() string {
+	parts := []string{fmt.Sprintf("#%d", s.Number)}
+	switch {
+	case s.State == "MERGED":
+		parts = append(parts, "merged")
+	case s.State == "CLOSED":
+		parts = append(parts, "closed")
+	case s.IsDraft:
+		parts = append(parts, "draft")
+	}
+	switch s.Review {
+	case "APPROVED":
+		parts = append(parts, "approved")
+	case "CHANGES_REQUESTED":
+		parts = append(parts, "changes requested")
+	case "REVIEW_REQUIRED":
+		parts = append(parts, "review pending")
+	}
+	summary := strings.Join(parts, " ")
+	switch s.CI {
+	case "passing":
+		summary += " · ci ✓"
+	case "failing":
+		summary += " · ci ✗"
+	case "pending":
+		summary += " · ci …"
+	}
+	return summary
+}
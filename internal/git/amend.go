@@ -0,0 +1,63 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrPushed is returned when amending would rewrite a commit a remote has
+var ErrPushed = errors.New("HEAD is already pushed")
+
+// AmendInfo is what the amend prompt starts from
+type AmendInfo struct {
+	Message string // HEAD's full message
+	Staged  int    // files staged for the amend
+}
+
+// CheckAmend reads HEAD's message and the staged file count, refusing when
+// there is no commit yet or a remote branch already contains HEAD
+func CheckAmend(ctx context.Context, dir string) (AmendInfo, error) {
+	if revParse(ctx, dir, "HEAD") == "" {
+		return AmendInfo{}, errors.New("no commit to amend")
+	}
+	output, err := runGit(ctx, dir, "branch", "--remotes", "--contains", "HEAD", "--format=%(refname:short)")
+	if err != nil {
+		return AmendInfo{}, err
+	}
+	if remotes := strings.Fields(string(output)); len(remotes) > 0 {
+		return AmendInfo{}, fmt.Errorf("%w to %s", ErrPushed, remotes[0])
+	}
+
+	message, err := runGit(ctx, dir, "log", "-1", "--format=%B")
+	if err != nil {
+		return AmendInfo{}, err
+	}
+	// NUL-separated, so paths with spaces or newlines count once
+	staged, err := runGit(ctx, dir, "diff", "--cached", "--name-only", "-z")
+	if err != nil {
+		return AmendInfo{}, err
+	}
+	return AmendInfo{
+		Message: strings.TrimRight(string(message), "\n"),
+		Staged:  strings.Count(string(staged), "\x00"),
+	}, nil
+}
+
+// Amend folds the staged changes into HEAD, replacing its message unless
+// message is "", and returns the new short hash
+func Amend(ctx context.Context, dir, message string) (string, error) {
+	args := []string{"commit", "--amend", "--no-edit"}
+	if message != "" {
+		args = []string{"commit", "--amend", "--message", message}
+	}
+	if _, err := runGit(ctx, dir, args...); err != nil {
+		return "", err
+	}
+	output, err := runGit(ctx, dir, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,189 @@
+package git
+
+import (
+	"container/list"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// diffStamp captures the worktree file and index state a diff was computed against
+type diffStamp struct {
+	fileMod   time.Time
+	fileSize  int64
+	indexMod  time.Time
+	indexSize int64
+}
+
+// diffCacheLines bounds the cache by the diff lines its entries hold
+const diffCacheLines = 200_000
+
+// diffCacheEntry holds cached diff results for one file
+type diffCacheEntry struct {
+	key      string
+	stamp    diffStamp
+	diff     []DiffLine
+	stats    DiffStats
+	hasDiff  bool
+	hasStats bool
+}
+
+// diffCache memoizes GetFileDiff/GetDiffStats until the file or index
+// changes, evicting the least recently used entries past diffCacheLines
+var diffCache = struct {
+	sync.Mutex
+	entries    map[string]*list.Element
+	order      *list.List // most recently used first
+	lines      int
+	indexPaths map[string]string // repo dir -> resolved .git/index path
+}{
+	entries:    make(map[string]*list.Element),
+	order:      list.New(),
+	indexPaths: make(map[string]string),
+}
+
+// indexPath resolves the index file for a repo, caching the lookup per dir
+func indexPath(ctx context.Context, dir string) string {
+	diffCache.Lock()
+	p, ok := diffCache.indexPaths[dir]
+	diffCache.Unlock()
+	if ok {
+		return p
+	}
+
+	output, err := runGit(ctx, dir, "rev-parse", "--git-path", "index")
+	if err != nil {
+		return ""
+	}
+	p = strings.TrimSpace(string(output))
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(dir, p)
+	}
+
+	diffCache.Lock()
+	diffCache.indexPaths[dir] = p
+	diffCache.Unlock()
+	return p
+}
+
+// currentStamp stats the file and index; ok is false if the file can't be stat'd
+func currentStamp(ctx context.Context, dir, path string) (diffStamp, bool) {
+	info, err := os.Stat(filepath.Join(dir, path))
+	if err != nil {
+		return diffStamp{}, false
+	}
+	stamp := diffStamp{fileMod: info.ModTime(), fileSize: info.Size()}
+	if idx := indexPath(ctx, dir); idx != "" {
+		if indexInfo, err := os.Stat(idx); err == nil {
+			stamp.indexMod = indexInfo.ModTime()
+			stamp.indexSize = indexInfo.Size()
+		}
+	}
+	return stamp, true
+}
+
+// cacheKey identifies a file's cache entry; variant separates diffs of the
+// same file computed differently (e.g. against a pre-rename path)
+func cacheKey(dir, path, variant string) string {
+	key := filepath.Join(dir, path)
+	if variant != "" {
+		key += "\x00" + variant
+	}
+	return key
+}
+
+// cachedEntry returns the entry for a file, marking it recently used and
+// resetting it if the stamp changed
+func cachedEntry(key string, stamp diffStamp) *diffCacheEntry {
+	if el, ok := diffCache.entries[key]; ok {
+		diffCache.order.MoveToFront(el)
+		entry := el.Value.(*diffCacheEntry)
+		if entry.stamp == stamp {
+			return entry
+		}
+		diffCache.lines -= len(entry.diff)
+		*entry = diffCacheEntry{key: key, stamp: stamp}
+		return entry
+	}
+	entry := &diffCacheEntry{key: key, stamp: stamp}
+	diffCache.entries[key] = diffCache.order.PushFront(entry)
+	diffCache.lines++
+	return entry
+}
+
+// evictDiffs drops least recently used entries until the cache is in budget
+func evictDiffs() {
+	for diffCache.lines > diffCacheLines && diffCache.order.Len() > 1 {
+		el := diffCache.order.Back()
+		entry := el.Value.(*diffCacheEntry)
+		diffCache.order.Remove(el)
+		delete(diffCache.entries, entry.key)
+		diffCache.lines -= len(entry.diff) + 1
+	}
+}
+
+// cachedFileDiff returns GetFileDiff results, recomputing only on change
+func cachedFileDiff(ctx context.Context, dir, path, variant string, compute func() ([]DiffLine, error)) ([]DiffLine, error) {
+	stamp, ok := currentStamp(ctx, dir, path)
+	if !ok {
+		return compute()
+	}
+	key := cacheKey(dir, path, variant)
+
+	diffCache.Lock()
+	entry := cachedEntry(key, stamp)
+	if entry.hasDiff {
+		diff := entry.diff
+		diffCache.Unlock()
+		return diff, nil
+	}
+	diffCache.Unlock()
+
+	diff, err := compute()
+	if err != nil {
+		return diff, err
+	}
+
+	diffCache.Lock()
+	entry = cachedEntry(key, stamp)
+	diffCache.lines += len(diff) - len(entry.diff)
+	entry.diff = diff
+	entry.hasDiff = true
+	evictDiffs()
+	diffCache.Unlock()
+	return diff, nil
+}
+
+// cachedDiffStats returns GetDiffStats results, recomputing only on change
+func cachedDiffStats(ctx context.Context, dir, path, variant string, compute func() (DiffStats, error)) DiffStats {
+	stamp, ok := currentStamp(ctx, dir, path)
+	if !ok {
+		stats, _ := compute()
+		return stats
+	}
+	key := cacheKey(dir, path, variant)
+
+	diffCache.Lock()
+	entry := cachedEntry(key, stamp)
+	if entry.hasStats {
+		stats := entry.stats
+		diffCache.Unlock()
+		return stats
+	}
+	diffCache.Unlock()
+
+	stats, err := compute()
+	if err != nil {
+		return stats
+	}
+
+	diffCache.Lock()
+	entry = cachedEntry(key, stamp)
+	entry.stats = stats
+	entry.hasStats = true
+	diffCache.Unlock()
+	return stats
+}
@@ -0,0 +1,73 @@
+package git
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// ConventionalTypes are the commit types offered for completion, most used
+// first
+var ConventionalTypes = []string{
+	"feat", "fix", "docs", "refactor", "test", "chore",
+	"perf", "style", "build", "ci", "revert",
+}
+
+// Conventional is a parsed `type(scope)!: subject` header
+type Conventional struct {
+	Type     string
+	Scope    string
+	Breaking bool
+	Subject  string
+}
+
+// ParseConventional splits a commit header into its parts, reporting false
+// when it isn't in conventional form
+func ParseConventional(header string) (Conventional, bool) {
+	prefix, subject, ok := strings.Cut(header, ": ")
+	if !ok {
+		return Conventional{}, false
+	}
+	var c Conventional
+	c.Subject = strings.TrimSpace(subject)
+	if strings.HasSuffix(prefix, "!") {
+		c.Breaking = true
+		prefix = strings.TrimSuffix(prefix, "!")
+	}
+	if open := strings.IndexByte(prefix, '('); open >= 0 {
+		if !strings.HasSuffix(prefix, ")") {
+			return Conventional{}, false
+		}
+		c.Scope = prefix[open+1 : len(prefix)-1]
+		prefix = prefix[:open]
+		if c.Scope == "" || strings.ContainsAny(c.Scope, "() ") {
+			return Conventional{}, false
+		}
+	}
+	c.Type = prefix
+	if c.Type == "" || strings.ContainsAny(c.Type, " ()!") || c.Subject == "" {
+		return Conventional{}, false
+	}
+	return c, true
+}
+
+// RecentScopes lists the scopes used in the last n commit headers, most
+// recent first
+func RecentScopes(ctx context.Context, dir string, n int) ([]string, error) {
+	output, err := runGit(ctx, dir, "log", "-n", strconv.Itoa(n), "--format=%s")
+	if err != nil {
+		// A repo with no commits has no scopes
+		return nil, nil
+	}
+	var scopes []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(string(output), "\n") {
+		c, ok := ParseConventional(line)
+		if !ok || c.Scope == "" || seen[c.Scope] {
+			continue
+		}
+		seen[c.Scope] = true
+		scopes = append(scopes, c.Scope)
+	}
+	return scopes, nil
+}
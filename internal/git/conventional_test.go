@@ -0,0 +1,27 @@
+package git
+
+import "testing"
+
+func TestParseConventional(t *testing.T) {
+	tests := []struct {
+		header string
+		want   Conventional
+		ok     bool
+	}{
+		{"feat: add stash keys", Conventional{Type: "feat", Subject: "add stash keys"}, true},
+		{"fix(ui): keep the toast", Conventional{Type: "fix", Scope: "ui", Subject: "keep the toast"}, true},
+		{"refactor(git)!: drop runGit", Conventional{Type: "refactor", Scope: "git", Breaking: true, Subject: "drop runGit"}, true},
+		{"feat!: rename flags", Conventional{Type: "feat", Breaking: true, Subject: "rename flags"}, true},
+		{"Add stash keys", Conventional{}, false},
+		{"feat(ui: broken", Conventional{}, false},
+		{"feat(): empty scope", Conventional{}, false},
+		{"feat: ", Conventional{}, false},
+		{"two words: not a type", Conventional{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseConventional(tt.header)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseConventional(%q) = %+v, %v; want %+v, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
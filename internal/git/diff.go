@@ -1,23 +1,208 @@
 package git
 
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
 // DiffLine represents a line in a diff
 type DiffLine struct {
-	Number  int
-	Content string
-	Type    string // "add", "remove", "context"
+	Number    int    // line number in the new file (0 for removed lines)
+	OldNumber int    // line number in the old file (0 for added lines)
+	Content   string // line text without the +/-/space prefix, the raw "@@" header, or the file path
+	Type      string // "add", "remove", "context", "hunk", "file"
+}
+
+// GetFileDiff returns the parsed worktree diff for a specific file
+func GetFileDiff(ctx context.Context, dir, path string) ([]DiffLine, error) {
+	return cachedFileDiff(ctx, dir, path, "", func() ([]DiffLine, error) {
+		output, err := runGit(ctx, dir, "diff", "--", path)
+		if err != nil {
+			return nil, err
+		}
+		return ParseUnifiedDiff(string(output)), nil
+	})
+}
+
+// DiffFiles returns the parsed diff between two files on disk, which
+// needn't be in a repo
+func DiffFiles(ctx context.Context, oldPath, newPath string) ([]DiffLine, error) {
+	output, err := runGit(ctx, "", "diff", "--no-index", "--", oldPath, newPath)
+	// Exit status 1 just means the files differ
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseUnifiedDiff(string(output)), nil
+}
+
+// GetRenameDiff returns the diff of a renamed file against its pre-rename
+// path in HEAD, so edits made alongside the rename show as changes
+func GetRenameDiff(ctx context.Context, dir, oldPath, path string) ([]DiffLine, error) {
+	return cachedFileDiff(ctx, dir, path, "rename:"+oldPath, func() ([]DiffLine, error) {
+		output, err := runGit(ctx, dir, "diff", "-M", "HEAD", "--", oldPath, path)
+		if err != nil {
+			return nil, err
+		}
+		return ParseUnifiedDiff(string(output)), nil
+	})
 }
 
-// GetFileDiff returns the diff for a specific file
-func GetFileDiff(dir, path string) ([]DiffLine, error) {
-	// TODO: exec git diff <path>
-	// TODO: parse unified diff format
-	// TODO: return line-by-line with markers
-	return nil, nil
+// GetDeletedContent returns the last recorded content of a file that is gone
+// from the worktree: HEAD's version, or the index's for a file that was
+// staged but never committed. The second result names where it came from.
+func GetDeletedContent(ctx context.Context, dir, path string) ([]byte, string, error) {
+	path = filepath.ToSlash(path)
+	output, err := runGit(ctx, dir, "show", "HEAD:"+path)
+	if err == nil {
+		return output, "HEAD", nil
+	}
+	if staged, indexErr := runGit(ctx, dir, "show", ":"+path); indexErr == nil {
+		return staged, "index", nil
+	}
+	return nil, "", err
 }
 
 // GetFileWithDiff returns full file content with diff markers
-func GetFileWithDiff(dir, path string) ([]DiffLine, error) {
-	// TODO: read file content
-	// TODO: overlay diff markers on full content
-	return nil, nil
+func GetFileWithDiff(ctx context.Context, dir, path string) ([]DiffLine, error) {
+	content, err := os.ReadFile(filepath.Join(dir, path))
+	if err != nil {
+		return nil, err
+	}
+	diff, err := GetFileDiff(ctx, dir, path)
+	if err != nil {
+		return nil, err
+	}
+	return OverlayDiff(strings.Split(string(content), "\n"), diff), nil
+}
+
+// ParseUnifiedDiff parses `git diff` output into hunk headers and line records.
+// Hunk records carry the new/old line the hunk starts at in Number/OldNumber.
+func ParseUnifiedDiff(output string) []DiffLine {
+	var result []DiffLine
+	oldNum, newNum := 0, 0
+	inHunk := false
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, "@@") {
+			oldStart, newStart := parseHunkHeader(line)
+			oldNum, newNum = oldStart, newStart
+			inHunk = true
+			result = append(result, DiffLine{Number: newStart, OldNumber: oldStart, Content: line, Type: "hunk"})
+			continue
+		}
+
+		// A new file section ends the current hunk
+		if strings.HasPrefix(line, "diff ") {
+			inHunk = false
+			path := line
+			if i := strings.LastIndex(line, " b/"); i >= 0 {
+				path = line[i+len(" b/"):]
+			}
+			result = append(result, DiffLine{Content: path, Type: "file"})
+			continue
+		}
+		if !inHunk || line == "" {
+			continue
+		}
+
+		switch line[0] {
+		case '+':
+			result = append(result, DiffLine{Number: newNum, Content: line[1:], Type: "add"})
+			newNum++
+		case '-':
+			result = append(result, DiffLine{OldNumber: oldNum, Content: line[1:], Type: "remove"})
+			oldNum++
+		case ' ':
+			result = append(result, DiffLine{Number: newNum, OldNumber: oldNum, Content: line[1:], Type: "context"})
+			newNum++
+			oldNum++
+		}
+		// "\ No newline at end of file" markers are skipped
+	}
+
+	return result
+}
+
+// parseHunkHeader extracts the first old and new line numbers a hunk covers.
+// A zero-length range names the line before it, so it is shifted forward by one.
+func parseHunkHeader(header string) (oldStart, newStart int) {
+	var oldCount, newCount int
+	fields := strings.Fields(header)
+	if len(fields) < 3 {
+		return 0, 0
+	}
+	oldStart, oldCount = parseRange(strings.TrimPrefix(fields[1], "-"))
+	newStart, newCount = parseRange(strings.TrimPrefix(fields[2], "+"))
+	if oldCount == 0 {
+		oldStart++
+	}
+	if newCount == 0 {
+		newStart++
+	}
+	return oldStart, newStart
+}
+
+// parseRange parses "start,count" (count defaults to 1)
+func parseRange(r string) (start, count int) {
+	count = 1
+	if i := strings.Index(r, ","); i >= 0 {
+		fmt.Sscanf(r[i+1:], "%d", &count)
+		r = r[:i]
+	}
+	fmt.Sscanf(r, "%d", &start)
+	return start, count
+}
+
+// OverlayDiff merges diff records onto the full new-file content, returning
+// every file line (as "add" or "context") with removed lines interleaved
+// before the line they preceded
+func OverlayDiff(lines []string, diff []DiffLine) []DiffLine {
+	added := make(map[int]bool)
+	removedBefore := make(map[int][]DiffLine)
+	next := 1
+	for _, d := range diff {
+		switch d.Type {
+		case "hunk":
+			next = d.Number
+		case "add", "context":
+			if d.Type == "add" {
+				added[d.Number] = true
+			}
+			next = d.Number + 1
+		case "remove":
+			removedBefore[next] = append(removedBefore[next], d)
+		}
+	}
+
+	result := make([]DiffLine, 0, len(lines)+len(removedBefore))
+	for i, line := range lines {
+		lineNum := i + 1
+		result = append(result, removedBefore[lineNum]...)
+		lineType := "context"
+		if added[lineNum] {
+			lineType = "add"
+		}
+		result = append(result, DiffLine{Number: lineNum, Content: line, Type: lineType})
+	}
+
+	// Removed lines past the end of the new file
+	for lineNum := len(lines) + 1; lineNum <= next; lineNum++ {
+		result = append(result, removedBefore[lineNum]...)
+	}
+
+	return result
 }
@@ -0,0 +1,83 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,4 +1,4 @@
+ package main
+-func old() {}
++func renamed() {}
+ 
+ // end
+@@ -9 +8,0 @@ func tail() {
+-// trailing comment
+`
+
+func TestParseUnifiedDiff(t *testing.T) {
+	diff := ParseUnifiedDiff(sampleDiff)
+
+	var types []string
+	for _, d := range diff {
+		types = append(types, d.Type)
+	}
+	want := "file hunk context remove add context context hunk remove"
+	if got := strings.Join(types, " "); got != want {
+		t.Fatalf("types = %q, want %q", got, want)
+	}
+
+	if diff[0].Content != "main.go" {
+		t.Errorf("file record = %+v", diff[0])
+	}
+	if diff[3].Content != "func old() {}" || diff[3].OldNumber != 2 {
+		t.Errorf("removed line = %+v", diff[3])
+	}
+	if diff[4].Content != "func renamed() {}" || diff[4].Number != 2 {
+		t.Errorf("added line = %+v", diff[4])
+	}
+	// Zero-length new range "+8,0" anchors before new line 9
+	if diff[7].Number != 9 || diff[7].OldNumber != 9 {
+		t.Errorf("second hunk = %+v", diff[7])
+	}
+}
+
+func TestOverlayDiff(t *testing.T) {
+	lines := []string{"package main", "func renamed() {}", "", "// end", "a", "b", "c", "d"}
+	rows := OverlayDiff(lines, ParseUnifiedDiff(sampleDiff))
+
+	if len(rows) != len(lines)+2 {
+		t.Fatalf("got %d rows, want %d", len(rows), len(lines)+2)
+	}
+	if rows[1].Type != "remove" || rows[1].Content != "func old() {}" {
+		t.Errorf("row 1 = %+v, want removed old line", rows[1])
+	}
+	if rows[2].Type != "add" || rows[2].Number != 2 {
+		t.Errorf("row 2 = %+v, want added line 2", rows[2])
+	}
+	last := rows[len(rows)-1]
+	if last.Type != "remove" || last.Content != "// trailing comment" {
+		t.Errorf("last row = %+v, want trailing removal", last)
+	}
+}
+
+func TestSplitPatch(t *testing.T) {
+	header, hunks := SplitPatch(sampleDiff)
+
+	if !strings.HasPrefix(header, "diff --git") || !strings.HasSuffix(header, "+++ b/main.go\n") {
+		t.Errorf("header = %q", header)
+	}
+	if len(hunks) != 2 {
+		t.Fatalf("got %d hunks, want 2", len(hunks))
+	}
+	if hunks[0].NewStart != 1 || hunks[0].NewCount != 4 || !strings.HasSuffix(hunks[0].Text, " // end\n") {
+		t.Errorf("hunk 0 = %+v", hunks[0])
+	}
+	if hunks[1].NewStart != 8 || hunks[1].NewCount != 0 {
+		t.Errorf("hunk 1 = %+v", hunks[1])
+	}
+}
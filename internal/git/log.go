@@ -0,0 +1,152 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Commit is one entry of the current branch's history
+type Commit struct {
+	Hash    string // short hash
+	Subject string
+	Author  string
+	TimeAgo string // "2 hours ago"
+	Refs    string // HEAD and tags pointing at it, e.g. "HEAD, v1.2.0"
+}
+
+// GetLog lists up to n commits of the current branch that touch paths under
+// dir (and the pathspec), newest first
+func GetLog(ctx context.Context, dir string, n int) ([]Commit, error) {
+	args := []string{"log", logFormat, "-n", fmt.Sprint(n)}
+	output, err := runGit(ctx, dir, append(args, pathspec.args("")...)...)
+	if err != nil {
+		return nil, err
+	}
+	return parseLog(string(output)), nil
+}
+
+// GetFileLog lists up to n commits that touched path, newest first
+func GetFileLog(ctx context.Context, dir, path string, n int) ([]Commit, error) {
+	output, err := runGit(ctx, dir, "log", logFormat, "-n", fmt.Sprint(n), "--", path)
+	if err != nil {
+		return nil, err
+	}
+	return parseLog(string(output)), nil
+}
+
+// parseLog parses git log output written with logFormat
+func parseLog(output string) []Commit {
+	var commits []Commit
+	for _, line := range strings.Split(output, "\n") {
+		if c, ok := parseLogLine(line); ok {
+			commits = append(commits, c)
+		}
+	}
+	return commits
+}
+
+// logFormat is the --format that parseLogLine reads
+const logFormat = "--format=%h%x1f%s%x1f%an%x1f%ar%x1f%D"
+
+// parseLogLine parses one commit written with logFormat
+func parseLogLine(line string) (Commit, bool) {
+	parts := strings.Split(line, "\x1f")
+	if len(parts) < 5 {
+		return Commit{}, false
+	}
+	return Commit{
+		Hash:    parts[0],
+		Subject: parts[1],
+		Author:  parts[2],
+		TimeAgo: parts[3],
+		Refs:    parseDecoration(parts[4]),
+	}, true
+}
+
+// GetCommit looks up one commit
+func GetCommit(ctx context.Context, dir, hash string) (Commit, error) {
+	output, err := runGit(ctx, dir, "log", "-1", logFormat, hash)
+	if err != nil {
+		return Commit{}, err
+	}
+	c, ok := parseLogLine(strings.TrimSpace(string(output)))
+	if !ok {
+		return Commit{}, fmt.Errorf("no commit %s", hash)
+	}
+	return c, nil
+}
+
+// CommitFile is one file a commit changed
+type CommitFile struct {
+	Path    string // relative to the directory the commit was read in
+	Status  string // "A", "M", "D", or "T"
+	Added   int
+	Deleted int
+}
+
+// GetCommitFiles lists the files a commit changed under dir (and the
+// pathspec); renames show as a delete and an add
+func GetCommitFiles(ctx context.Context, dir, hash string) ([]CommitFile, error) {
+	args := []string{"show", "--format=", "--first-parent", "-m", "--relative", "--no-renames"}
+	statuses, err := runGit(ctx, dir, append(append(args, "--name-status", hash), pathspec.args("")...)...)
+	if err != nil {
+		return nil, err
+	}
+	numstat, err := runGit(ctx, dir, append(append(args, "--numstat", hash), pathspec.args("")...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []CommitFile
+	index := make(map[string]int)
+	for _, line := range strings.Split(string(statuses), "\n") {
+		status, path, ok := strings.Cut(line, "\t")
+		if !ok || index[path] > 0 {
+			continue
+		}
+		files = append(files, CommitFile{Path: path, Status: status[:1]})
+		index[path] = len(files)
+	}
+	for _, line := range strings.Split(string(numstat), "\n") {
+		added, deleted, path, ok := parseNumstatLine(line)
+		if i := index[path]; ok && i > 0 {
+			files[i-1].Added, files[i-1].Deleted = added, deleted
+		}
+	}
+	return files, nil
+}
+
+// GetCommitFileDiff returns the parsed patch a commit made to one file
+func GetCommitFileDiff(ctx context.Context, dir, hash, path string) ([]DiffLine, error) {
+	output, err := runGit(ctx, dir, "show", "--format=", "--patch", "--first-parent", "-m", "--relative", "--no-renames", hash, "--", path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseUnifiedDiff(string(output)), nil
+}
+
+// GetCommitDiff returns the parsed patch a commit introduced under dir;
+// merges are shown against their first parent
+func GetCommitDiff(ctx context.Context, dir, hash string) ([]DiffLine, error) {
+	args := []string{"show", "--format=", "--patch", "--first-parent", "-m", "--relative", hash}
+	output, err := runGit(ctx, dir, append(args, pathspec.args("")...)...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseUnifiedDiff(string(output)), nil
+}
+
+// GetFileDiffBetween returns the parsed diff of path from one commit to
+// another; an empty to compares against the working tree
+func GetFileDiffBetween(ctx context.Context, dir, from, to, path string) ([]DiffLine, error) {
+	args := []string{"diff", from}
+	if to != "" {
+		args = append(args, to)
+	}
+	output, err := runGit(ctx, dir, append(args, "--", path)...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseUnifiedDiff(string(output)), nil
+}
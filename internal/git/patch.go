@@ -0,0 +1,119 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Hunk is one "@@" section of a unified diff, kept verbatim
+type Hunk struct {
+	NewStart int    // first new-file line the hunk covers
+	NewCount int    // number of new-file lines the hunk covers
+	Text     string // the "@@" header and its lines, newline-terminated
+}
+
+// emptyTree is git's empty tree, which stands in for HEAD before the first
+// commit
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// GetFilePatch returns the raw patch of one file's uncommitted changes,
+// staged and unstaged, against HEAD; oldPath, when set, diffs a rename
+// against its pre-rename path. An untracked file is diffed as new.
+func GetFilePatch(ctx context.Context, dir, oldPath, path string) (string, error) {
+	if _, err := runGit(ctx, dir, "ls-files", "--error-unmatch", "--", path); err != nil {
+		output, err := runGit(ctx, dir, "diff", "--no-index", "--", os.DevNull, path)
+		// Exit status 1 just means the files differ
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			err = nil
+		}
+		return string(output), err
+	}
+	base := "HEAD"
+	if revParse(ctx, dir, "HEAD") == "" {
+		base = emptyTree
+	}
+	args := []string{"diff", base, "--", path}
+	if oldPath != "" {
+		args = []string{"diff", "-M", base, "--", oldPath, path}
+	}
+	output, err := runGit(ctx, dir, args...)
+	return string(output), err
+}
+
+// SplitPatch splits a single-file patch into its file header (the "diff",
+// "---", and "+++" lines) and hunks
+func SplitPatch(patch string) (header string, hunks []Hunk) {
+	var b strings.Builder
+	var current *Hunk
+	for _, line := range strings.SplitAfter(patch, "\n") {
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "@@") {
+			if current != nil {
+				current.Text = b.String()
+				hunks = append(hunks, *current)
+			} else {
+				header = b.String()
+			}
+			b.Reset()
+			current = &Hunk{}
+			fields := strings.Fields(line)
+			if len(fields) >= 3 {
+				current.NewStart, current.NewCount = parseRange(strings.TrimPrefix(fields[2], "+"))
+			}
+		}
+		b.WriteString(line)
+	}
+	if current != nil {
+		current.Text = b.String()
+		hunks = append(hunks, *current)
+	} else {
+		header = b.String()
+	}
+	return header, hunks
+}
+
+// GetWorkingTreePatch returns the patch of all tracked changes (staged and
+// unstaged) against HEAD, limited to dir when it is a subdirectory
+func GetWorkingTreePatch(ctx context.Context, dir string) (string, error) {
+	output, err := runGit(ctx, dir, append([]string{"diff", "HEAD", "--relative"}, pathspec.args("")...)...)
+	return string(output), err
+}
+
+// GetWorkingTreeStats totals added/deleted lines of all tracked changes
+// against HEAD under dir
+func GetWorkingTreeStats(ctx context.Context, dir string) (DiffStats, error) {
+	output, err := runGit(ctx, dir, append([]string{"diff", "HEAD", "--numstat", "--relative"}, pathspec.args("")...)...)
+	if err != nil {
+		return DiffStats{}, err
+	}
+	var stats DiffStats
+	for _, line := range strings.Split(string(output), "\n") {
+		if added, deleted, _, ok := parseNumstatLine(line); ok {
+			stats.Added += added
+			stats.Deleted += deleted
+		}
+	}
+	return stats, nil
+}
+
+// GetWorkingTreeFileStats lists the added/deleted lines of each tracked file
+// changed against HEAD under dir, in path order, like `git diff --stat`
+func GetWorkingTreeFileStats(ctx context.Context, dir string) ([]FileActivity, error) {
+	output, err := runGit(ctx, dir, append([]string{"diff", "HEAD", "--numstat", "--relative", "--no-renames"}, pathspec.args("")...)...)
+	if err != nil {
+		return nil, err
+	}
+	var files []FileActivity
+	for _, line := range strings.Split(string(output), "\n") {
+		if added, deleted, path, ok := parseNumstatLine(line); ok {
+			files = append(files, FileActivity{Path: path, Added: added, Deleted: deleted})
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,163 @@
+package git
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Pathspec limits which paths perch looks at. Patterns use git's default
+// pathspec matching: `*` also crosses directories, so "*.go" matches Go
+// files anywhere and "vendor/**" (or just "vendor") a whole directory.
+type Pathspec struct {
+	Include []string // only these paths (all when empty)
+	Exclude []string // never these
+}
+
+// pathspec holds the --include/--exclude patterns of this run
+var pathspec Pathspec
+
+// SetPathspec limits status, log, and diff to the given patterns, relative
+// to the directory perch is watching
+func SetPathspec(p Pathspec) {
+	pathspec = p
+}
+
+// ActivePathspec returns the patterns set with SetPathspec
+func ActivePathspec() Pathspec {
+	return pathspec
+}
+
+// IsEmpty reports whether the pathspec lets every path through
+func (p Pathspec) IsEmpty() bool {
+	return len(p.Include) == 0 && len(p.Exclude) == 0
+}
+
+// args returns the patterns as git pathspec arguments, after "--", with
+// prefix (the watched dir relative to where git runs) put in front of each
+func (p Pathspec) args(prefix string) []string {
+	args := []string{"--"}
+	for _, pattern := range p.Include {
+		args = append(args, prefix+pattern)
+	}
+	if len(p.Include) == 0 && prefix == "" {
+		args = append(args, ".")
+	} else if len(p.Include) == 0 {
+		args = append(args, prefix)
+	}
+	for _, pattern := range p.Exclude {
+		args = append(args, ":(exclude)"+prefix+pattern)
+	}
+	return args
+}
+
+// Match reports whether a slash-separated path, relative to the watched
+// directory, passes the pathspec
+func (p Pathspec) Match(rel string) bool {
+	for _, pattern := range p.Exclude {
+		if matchPathspec(pattern, rel) {
+			return false
+		}
+	}
+	if len(p.Include) == 0 {
+		return true
+	}
+	for _, pattern := range p.Include {
+		if matchPathspec(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// Narrow limits the pathspec to the given top-level paths as well: each
+// include pattern is intersected with them, so paths narrow the include
+// rather than replace it. Only paths is used when there is no include.
+func (p Pathspec) Narrow(paths []string) Pathspec {
+	if len(p.Include) == 0 {
+		p.Include = paths
+		return p
+	}
+	var include []string
+	seen := map[string]bool{}
+	add := func(pattern string) {
+		if !seen[pattern] {
+			seen[pattern] = true
+			include = append(include, pattern)
+		}
+	}
+	for _, r := range paths {
+		for _, pattern := range p.Include {
+			glob := strings.ContainsAny(pattern, "*?[")
+			switch {
+			case matchPathspec(pattern, r):
+				add(r)
+			case strings.HasPrefix(pattern, r+"/"):
+				add(pattern)
+			case glob && strings.HasPrefix(pattern, "*"):
+				// "*" crosses "/", so the pattern also matches under r
+				add(r + "/" + pattern)
+			}
+		}
+	}
+	p.Include = include
+	return p
+}
+
+// ExcludesDir reports whether every path under the slash-separated
+// directory rel is excluded, so a walk can skip it
+func (p Pathspec) ExcludesDir(rel string) bool {
+	for _, pattern := range p.Exclude {
+		// A wildcard pattern excludes the directory when it matches any
+		// child name, e.g. "vendor/**" but not "vendor/*.go"
+		if matchPathspec(pattern, rel) && !strings.ContainsAny(pattern, "*?[") || matchPathspec(pattern, rel+"/\x00") {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPathspec matches one pattern the way git does without the glob
+// magic: wildcards may span "/", and a plain path also matches anything
+// below it
+func matchPathspec(pattern, rel string) bool {
+	pattern = strings.TrimPrefix(path.Clean(pattern), "./")
+	if !strings.ContainsAny(pattern, "*?[") {
+		return rel == pattern || pattern == "." || strings.HasPrefix(rel, pattern+"/")
+	}
+	re, err := pathspecRegexp(pattern)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(rel)
+}
+
+// pathspecRegexp translates a wildcard pattern into an anchored regexp
+func pathspecRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,45 @@
+package git
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPathspecMatch(t *testing.T) {
+	p := Pathspec{Include: []string{"*.go", "docs"}, Exclude: []string{"vendor/**"}}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"main.go", true},
+		{"internal/ui/model.go", true},
+		{"docs/index.md", true},
+		{"docsite/index.md", false},
+		{"README.md", false},
+		{"vendor/github.com/x/y.go", false},
+	}
+	for _, tt := range tests {
+		if got := p.Match(tt.path); got != tt.want {
+			t.Errorf("Match(%q) = %v; want %v", tt.path, got, tt.want)
+		}
+	}
+
+	for dir, want := range map[string]bool{"vendor": true, "docs": false, "internal": false} {
+		if got := p.ExcludesDir(dir); got != want {
+			t.Errorf("ExcludesDir(%q) = %v; want %v", dir, got, want)
+		}
+	}
+	if (Pathspec{Exclude: []string{"*.go"}}).ExcludesDir("internal") {
+		t.Error(`"*.go" shouldn't exclude whole directories`)
+	}
+
+	narrowed := Pathspec{Include: []string{"*.go", "docs/api", "web"}}.Narrow([]string{"docs", "internal", "README.md"})
+	want := []string{"docs/*.go", "docs/api", "internal/*.go", "README.md/*.go"}
+	if fmt.Sprint(narrowed.Include) != fmt.Sprint(want) {
+		t.Errorf("Narrow = %v; want %v", narrowed.Include, want)
+	}
+
+	if !(Pathspec{}).Match("anything/at/all.txt") {
+		t.Error("an empty pathspec should match everything")
+	}
+}
@@ -0,0 +1,166 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kateleext/perch/internal/debuglog"
+	"github.com/kateleext/perch/internal/ignore"
+)
+
+// PlainFileLimit caps how many of the most recently modified files
+// GetDirStatus returns
+var PlainFileLimit = 50
+
+// A plain scan stops after PlainScanTimeout or PlainScanMaxEntries visited
+// entries, listing what it found so far, so a home directory can't peg the
+// CPU on every refresh
+var (
+	PlainScanTimeout    = 3 * time.Second
+	PlainScanMaxEntries = 100_000
+)
+
+// errScanCapped stops a plain scan that hit PlainScanMaxEntries
+var errScanCapped = errors.New("scan capped")
+
+// plainSkipDirs are directories GetDirStatus doesn't descend into, besides
+// hidden ones
+var plainSkipDirs = map[string]bool{"node_modules": true, "vendor": true, "__pycache__": true}
+
+// GetDirStatus lists the most recently modified files under a directory that
+// isn't a git repo, for perch's plain mode. The files have Status "plain"
+// and nothing to diff against.
+func GetDirStatus(ctx context.Context, dir string) ([]FileStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, PlainScanTimeout)
+	defer cancel()
+	hidden := ignore.LoadDir(dir)
+	now := time.Now()
+	var files []FileStatus
+	visited := 0
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // unreadable entries are skipped
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if visited++; visited > PlainScanMaxEntries {
+			return errScanCapped
+		}
+		rel, _ := filepath.Rel(dir, path)
+		slashRel := filepath.ToSlash(rel)
+		if d.IsDir() {
+			if path != dir && (strings.HasPrefix(d.Name(), ".") || plainSkipDirs[d.Name()] || hidden.Match(slashRel, true) || pathspec.ExcludesDir(slashRel)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() && d.Type()&fs.ModeSymlink == 0 {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") || shouldSkipFile(rel, skipPatterns) || hidden.Match(slashRel, false) || !pathspec.Match(slashRel) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		files = append(files, FileStatus{
+			Status:   "plain",
+			Path:     rel,
+			FullPath: rel,
+			TimeAgo:  timeAgo(info.ModTime(), now),
+			IsFile:   true,
+			ModTime:  info.ModTime(),
+		})
+		return nil
+	})
+	switch {
+	case errors.Is(err, errScanCapped), errors.Is(err, context.DeadlineExceeded):
+		// The newest files among those reached are still worth showing
+		debuglog.Printf("plain scan of %s stopped after %d entries: %v", dir, visited, err)
+	case err != nil:
+		return nil, err
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime.After(files[j].ModTime)
+	})
+	if PlainFileLimit > 0 && len(files) > PlainFileLimit {
+		files = files[:PlainFileLimit]
+	}
+	return files, nil
+}
+
+// timeAgo phrases how long ago t was the way git's %ar does ("3 hours ago")
+func timeAgo(t, now time.Time) string {
+	d := now.Sub(t)
+	if d < 0 {
+		d = 0
+	}
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", unit)
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+	switch {
+	case d < time.Minute:
+		return plural(int(d.Seconds()), "second")
+	case d < time.Hour:
+		return plural(int(d.Minutes()), "minute")
+	case d < 24*time.Hour:
+		return plural(int(d.Hours()), "hour")
+	case d < 14*24*time.Hour:
+		return plural(int(d.Hours()/24), "day")
+	case d < 60*24*time.Hour:
+		return plural(int(d.Hours()/(24*7)), "week")
+	case d < 365*24*time.Hour:
+		return plural(int(d.Hours()/(24*30)), "month")
+	default:
+		return plural(int(d.Hours()/(24*365)), "year")
+	}
+}
+
+// RecentPaths returns the entries directly inside dir that were modified
+// since the given time, looking one level into each directory, for
+// narrowing a scan of an enormous repo down with a pathspec
+func RecentPaths(dir string, since time.Time) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	recent := func(path string) bool {
+		info, err := os.Lstat(path)
+		return err == nil && !info.ModTime().Before(since)
+	}
+	var paths []string
+	for _, e := range entries {
+		if e.Name() == ".git" {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		if recent(path) {
+			paths = append(paths, e.Name())
+			continue
+		}
+		if !e.IsDir() {
+			continue
+		}
+		children, _ := os.ReadDir(path)
+		for _, c := range children {
+			if recent(filepath.Join(path, c.Name())) {
+				paths = append(paths, e.Name())
+				break
+			}
+		}
+	}
+	return paths
+}
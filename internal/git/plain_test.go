@@ -0,0 +1,28 @@
+package git
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeAgo(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{-time.Minute, "0 seconds ago"},
+		{30 * time.Second, "30 seconds ago"},
+		{time.Minute, "1 minute ago"},
+		{5 * time.Hour, "5 hours ago"},
+		{3 * 24 * time.Hour, "3 days ago"},
+		{21 * 24 * time.Hour, "3 weeks ago"},
+		{90 * 24 * time.Hour, "3 months ago"},
+		{800 * 24 * time.Hour, "2 years ago"},
+	}
+	for _, tt := range tests {
+		if got := timeAgo(now.Add(-tt.ago), now); got != tt.want {
+			t.Errorf("timeAgo(-%v) = %q; want %q", tt.ago, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// GetRemoteURL returns the fetch URL of the origin remote
+func GetRemoteURL(ctx context.Context, dir string) (string, error) {
+	output, err := runGit(ctx, dir, "remote", "get-url", "origin")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetCurrentRef returns the checked-out branch name, or the HEAD commit hash
+// when detached
+func GetCurrentRef(ctx context.Context, dir string) (string, error) {
+	if output, err := runGit(ctx, dir, "symbolic-ref", "--quiet", "--short", "HEAD"); err == nil {
+		return strings.TrimSpace(string(output)), nil
+	}
+	return GetHeadCommit(ctx, dir)
+}
+
+// GetHeadCommit returns the full hash of HEAD
+func GetHeadCommit(ctx context.Context, dir string) (string, error) {
+	output, err := runGit(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// HeadLine maps line n of path in the worktree to its line at HEAD, so a
+// permalink to a dirty file points at the same code
+func HeadLine(ctx context.Context, dir, path string, n int) (int, error) {
+	output, err := runGit(ctx, dir, "diff", "HEAD", "--", path)
+	if err != nil {
+		return 0, err
+	}
+	return headLine(ParseUnifiedDiff(string(output)), n), nil
+}
+
+// headLine maps new-file line n through diff to its old-file line. An added
+// line maps to the old line just before it.
+func headLine(diff []DiffLine, n int) int {
+	delta := 0 // old line minus new line outside the hunks seen so far
+	prevOld := 0
+	for _, row := range diff {
+		switch row.Type {
+		case "context":
+			if row.Number > n {
+				return n + delta
+			}
+			if row.Number == n {
+				return row.OldNumber
+			}
+			delta = row.OldNumber - row.Number
+			prevOld = row.OldNumber
+		case "remove":
+			if row.OldNumber > n+delta {
+				return n + delta
+			}
+			delta++
+			prevOld = row.OldNumber
+		case "add":
+			if row.Number > n {
+				return n + delta
+			}
+			if row.Number == n {
+				return max(prevOld, 1)
+			}
+			delta--
+		}
+	}
+	return n + delta
+}
+
+// parseRemote extracts the host and "owner/repo" path from an scp-style
+// (git@host:owner/repo.git), ssh://, or http(s):// remote URL
+func parseRemote(remote string) (host, repoPath string, err error) {
+	if !strings.Contains(remote, "://") {
+		// scp-like syntax: [user@]host:path; an "@" after the colon is part
+		// of the path
+		colon := strings.Index(remote, ":")
+		if colon < 0 {
+			return "", "", fmt.Errorf("unrecognized remote URL %q", remote)
+		}
+		host = remote[:colon]
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+		repoPath = remote[colon+1:]
+	} else {
+		u, perr := url.Parse(remote)
+		if perr != nil {
+			return "", "", fmt.Errorf("unrecognized remote URL %q", remote)
+		}
+		host = u.Hostname()
+		repoPath = u.Path
+	}
+	repoPath = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	if host == "" || repoPath == "" {
+		return "", "", fmt.Errorf("unrecognized remote URL %q", remote)
+	}
+	return host, repoPath, nil
+}
+
+// WebURL builds the browser URL for path at ref on the remote's host
+// (GitHub, GitLab, or Bitbucket). A line > 0 adds a line anchor.
+func WebURL(remote, ref, path string, line int) (string, error) {
+	host, repoPath, err := parseRemote(remote)
+	if err != nil {
+		return "", err
+	}
+	base := "https://" + host + "/" + repoPath
+	path = strings.TrimPrefix(path, "/")
+
+	switch {
+	case strings.Contains(host, "gitlab"):
+		u := base + "/-/blob/" + ref + "/" + path
+		if line > 0 {
+			u += fmt.Sprintf("#L%d", line)
+		}
+		return u, nil
+	case strings.Contains(host, "bitbucket"):
+		u := base + "/src/" + ref + "/" + path
+		if line > 0 {
+			u += fmt.Sprintf("#lines-%d", line)
+		}
+		return u, nil
+	case strings.Contains(host, "github"):
+		u := base + "/blob/" + ref + "/" + path
+		if line > 0 {
+			u += fmt.Sprintf("#L%d", line)
+		}
+		return u, nil
+	default:
+		return "", fmt.Errorf("unsupported remote host %s", host)
+	}
+}
+
+// GetHeadSubject returns the full hash and subject of HEAD
+func GetHeadSubject(ctx context.Context, dir string) (hash, subject string, err error) {
+	output, err := runGit(ctx, dir, "log", "-1", "--format=%H%x1f%s")
+	if err != nil {
+		return "", "", err
+	}
+	hash, subject, _ = strings.Cut(strings.TrimSpace(string(output)), "\x1f")
+	return hash, subject, nil
+}
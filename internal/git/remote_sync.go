@@ -0,0 +1,72 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kateleext/perch/internal/debuglog"
+)
+
+// FetchTimeout bounds a background fetch, which talks to the network
+var FetchTimeout = 2 * time.Minute
+
+// Fetch runs `git fetch` for dir's repo with all credential and host-key
+// prompts disabled, so it fails instead of waiting for input
+func Fetch(ctx context.Context, dir string) error {
+	ctx, cancel := context.WithTimeout(ctx, FetchTimeout)
+	defer cancel()
+
+	env := append(os.Environ(),
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_ASKPASS=",
+		"SSH_ASKPASS=",
+		"GCM_INTERACTIVE=never",
+	)
+	// Keep a custom ssh command (keys, proxies, wrappers): a GIT_SSH_COMMAND
+	// from the environment is left alone, a configured core.sshCommand gets
+	// BatchMode added, and only plain ssh is replaced
+	var args []string
+	if os.Getenv("GIT_SSH_COMMAND") == "" {
+		if output, err := runGit(ctx, dir, "config", "--get", "core.sshCommand"); err == nil && strings.TrimSpace(string(output)) != "" {
+			args = append(args, "-c", "core.sshCommand="+strings.TrimSpace(string(output))+" -o BatchMode=yes")
+		} else {
+			env = append(env, "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+		}
+	}
+	args = append(args, "fetch", "--quiet", "--no-write-fetch-head")
+	cmd := gitCmd(ctx, args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	start := time.Now()
+	output, err := cmd.CombinedOutput()
+	debuglog.Printf("git fetch (in %s) took %s err=%v", dir, time.Since(start).Round(time.Millisecond), err)
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%w: git fetch after %s", ErrTimeout, FetchTimeout)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(strings.SplitN(string(output), "\n", 2)[0]); msg != "" {
+			return fmt.Errorf("git fetch: %s", msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// ErrNoUpstream is returned when the current branch doesn't track a remote branch
+var ErrNoUpstream = errors.New("no upstream branch")
+
+// GetAheadBehind returns how many commits HEAD is ahead of and behind its upstream
+func GetAheadBehind(ctx context.Context, dir string) (ahead, behind int, err error) {
+	output, err := runGit(ctx, dir, "rev-list", "--left-right", "--count", "HEAD...@{upstream}")
+	if err != nil {
+		return 0, 0, ErrNoUpstream
+	}
+	if _, err := fmt.Sscanf(string(output), "%d %d", &ahead, &behind); err != nil {
+		return 0, 0, err
+	}
+	return ahead, behind, nil
+}
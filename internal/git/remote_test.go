@@ -0,0 +1,76 @@
+package git
+
+import "testing"
+
+func TestWebURL(t *testing.T) {
+	tests := []struct {
+		remote string
+		line   int
+		want   string
+	}{
+		{"git@github.com:kateleext/perch.git", 0, "https://github.com/kateleext/perch/blob/main/cmd/perch/main.go"},
+		{"https://github.com/kateleext/perch", 42, "https://github.com/kateleext/perch/blob/main/cmd/perch/main.go#L42"},
+		{"ssh://git@gitlab.com/group/sub/perch.git", 7, "https://gitlab.com/group/sub/perch/-/blob/main/cmd/perch/main.go#L7"},
+		{"git@bitbucket.org:team/perch.git", 3, "https://bitbucket.org/team/perch/src/main/cmd/perch/main.go#lines-3"},
+	}
+	for _, tt := range tests {
+		got, err := WebURL(tt.remote, "main", "cmd/perch/main.go", tt.line)
+		if err != nil || got != tt.want {
+			t.Errorf("WebURL(%q) = %q, %v; want %q", tt.remote, got, err, tt.want)
+		}
+	}
+
+	if _, err := WebURL("git@example.com:me/perch.git", "main", "a.go", 0); err == nil {
+		t.Error("expected an error for an unknown host")
+	}
+}
+
+func TestParseRemote(t *testing.T) {
+	tests := []struct {
+		remote, host, path string
+	}{
+		{"git@github.com:kateleext/perch.git", "github.com", "kateleext/perch"},
+		{"ssh.example.com:team@scope/repo.git", "ssh.example.com", "team@scope/repo"},
+		{"host:path/with@sign", "host", "path/with@sign"},
+	}
+	for _, tt := range tests {
+		host, path, err := parseRemote(tt.remote)
+		if err != nil || host != tt.host || path != tt.path {
+			t.Errorf("parseRemote(%q) = %q, %q, %v; want %q, %q", tt.remote, host, path, err, tt.host, tt.path)
+		}
+	}
+}
+
+func TestHeadLine(t *testing.T) {
+	// HEAD had a..f; the worktree drops b, adds x after d, and appends y
+	diff := ParseUnifiedDiff(`diff --git a/f b/f
+--- a/f
++++ b/f
+@@ -1,3 +1,2 @@
+ a
+-b
+ c
+@@ -3,3 +2,4 @@
+ c
+ d
++x
+ e
+@@ -6 +6,2 @@
+ f
++y
+`)
+	tests := []struct{ line, want int }{
+		{1, 1}, // a
+		{2, 3}, // c
+		{3, 4}, // d
+		{4, 4}, // x, added after d
+		{5, 5}, // e
+		{6, 6}, // f
+		{7, 6}, // y, appended
+	}
+	for _, tt := range tests {
+		if got := headLine(diff, tt.line); got != tt.want {
+			t.Errorf("headLine(%d) = %d; want %d", tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"strings"
+)
+
+// StashEntry is one entry from `git stash list`
+type StashEntry struct {
+	Ref     string // "stash@{0}"
+	Message string // e.g. "WIP on main: 1a2b3c4 subject"
+	TimeAgo string // "2 hours ago"
+}
+
+// GetStashes lists stash entries, newest first
+func GetStashes(ctx context.Context, dir string) ([]StashEntry, error) {
+	output, err := runGit(ctx, dir, "stash", "list", "--format=%gd|%ar|%gs")
+	if err != nil {
+		return nil, err
+	}
+
+	var stashes []StashEntry
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), "|", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		stashes = append(stashes, StashEntry{Ref: parts[0], TimeAgo: parts[1], Message: parts[2]})
+	}
+	return stashes, nil
+}
+
+// GetStashDiff returns the parsed patch stored in a stash entry
+func GetStashDiff(ctx context.Context, dir, ref string) ([]DiffLine, error) {
+	output, err := runGit(ctx, dir, "stash", "show", "-p", ref)
+	if err != nil {
+		return nil, err
+	}
+	return ParseUnifiedDiff(string(output)), nil
+}
+
+// ApplyStash applies a stash entry, keeping it in the stash list
+func ApplyStash(ctx context.Context, dir, ref string) error {
+	_, err := runGit(ctx, dir, "stash", "apply", ref)
+	return err
+}
+
+// PopStash applies a stash entry and removes it from the stash list
+func PopStash(ctx context.Context, dir, ref string) error {
+	_, err := runGit(ctx, dir, "stash", "pop", ref)
+	return err
+}
+
+// DropStash removes a stash entry without applying it
+func DropStash(ctx context.Context, dir, ref string) error {
+	_, err := runGit(ctx, dir, "stash", "drop", ref)
+	return err
+}
+
+// PushStash stashes the working tree, untracked files included, as message
+func PushStash(ctx context.Context, dir, message string) error {
+	_, err := runGit(ctx, dir, "stash", "push", "--include-untracked", "--message", message)
+	return err
+}
@@ -2,61 +2,167 @@ package git
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/kateleext/perch/internal/debuglog"
+	"github.com/kateleext/perch/internal/ignore"
+	"golang.org/x/sync/errgroup"
 )
 
+// CommandTimeout bounds how long a single git invocation may run, so a hung
+// git (slow network FS, credential prompt) can't stall refreshes forever
+var CommandTimeout = 10 * time.Second
+
+// ErrTimeout is returned when a git command exceeds CommandTimeout
+var ErrTimeout = errors.New("git command timed out")
+
+// ErrNotInstalled is returned when there is no git on PATH
+var ErrNotInstalled = errors.New("git isn't installed (not found on PATH)")
+
+// Available reports whether git is installed
+func Available() bool {
+	_, err := exec.LookPath("git")
+	return err == nil
+}
+
 // gitCmd creates a git command with --no-optional-locks to avoid lock contention
-func gitCmd(args ...string) *exec.Cmd {
+func gitCmd(ctx context.Context, args ...string) *exec.Cmd {
 	fullArgs := append([]string{"--no-optional-locks"}, args...)
-	return exec.Command("git", fullArgs...)
+	return exec.CommandContext(ctx, "git", fullArgs...)
+}
+
+// runGit runs git in dir and returns its stdout, bounded by ctx and CommandTimeout
+func runGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	return runGitEnv(ctx, dir, nil, args...)
+}
+
+// runGitEnv is runGit with extra environment variables, e.g. GIT_INDEX_FILE
+func runGitEnv(ctx context.Context, dir string, env []string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, CommandTimeout)
+	defer cancel()
+
+	cmd := gitCmd(ctx, args...)
+	cmd.Dir = dir
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	start := time.Now()
+	output, err := cmd.Output()
+	debuglog.Printf("git %s (in %s) took %s err=%v", strings.Join(args, " "), dir, time.Since(start).Round(time.Microsecond), err)
+	if ctx.Err() == context.DeadlineExceeded {
+		return output, fmt.Errorf("%w: git %s after %s", ErrTimeout, args[0], CommandTimeout)
+	}
+	if errors.Is(err, exec.ErrNotFound) {
+		return output, ErrNotInstalled
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		// Keep git's own explanation (first line of stderr) for status messages
+		msg := strings.TrimSpace(strings.SplitN(string(exitErr.Stderr), "\n", 2)[0])
+		return output, fmt.Errorf("git %s: %s: %w", args[0], msg, err)
+	}
+	return output, err
 }
 
 // FileStatus represents a file's git status
 type FileStatus struct {
-	Status   string    // "uncommitted" or "committed"
-	GitCode  string    // "??", "M ", "A ", etc. for uncommitted files
-	Path     string    // display path (relative to target directory)
-	FullPath string    // path relative to GitRoot (for git commands)
-	GitRoot  string    // git root for this file (may differ for submodules)
-	Commit   string    // short hash for committed files
-	TimeAgo  string    // "2 hours ago" for committed files
-	IsFile   bool      // true if it's a file (not directory)
-	ModTime  time.Time // file modification time for sorting
+	Status      string    // "uncommitted", "committed", or "plain" outside a repo
+	GitCode     string    // "??", "M ", "A ", etc. for uncommitted files
+	Path        string    // display path (relative to target directory)
+	FullPath    string    // path relative to GitRoot (for git commands)
+	OldPath     string    // display path before a rename/copy, if any
+	OldFullPath string    // pre-rename path relative to GitRoot
+	GitRoot     string    // git root for this file (may differ for submodules)
+	Commit      string    // short hash for committed files
+	TimeAgo     string    // "2 hours ago" for committed files
+	Author      string    // commit author name for committed files
+	Refs        string    // "HEAD, v1.2.0" when the commit is the tip or tagged
+	IsFile      bool      // true if it's a file (not directory)
+	ModTime     time.Time // file modification time for sorting
 }
 
 // ChangeType returns a human-readable description of the change
 func (f FileStatus) ChangeType() string {
+	if f.Status == "plain" {
+		return "modified " + f.TimeAgo
+	}
 	if f.Status == "committed" {
-		return f.TimeAgo + " · " + f.Commit
+		commit := f.Commit
+		if f.Refs != "" {
+			commit += " (" + f.Refs + ")"
+		}
+		if f.Author != "" {
+			return f.TimeAgo + " · " + commit + " · " + f.Author
+		}
+		return f.TimeAgo + " · " + commit
 	}
 
 	// Parse git status code
 	switch {
+	case f.GitCode == "  ":
+		return "unchanged"
 	case f.GitCode == "??" || f.GitCode == "A " || f.GitCode == "AM":
 		return "new file"
 	case strings.Contains(f.GitCode, "D"):
 		return "deleted"
 	case strings.Contains(f.GitCode, "R"):
+		if f.OldPath != "" {
+			return "renamed from " + f.OldPath
+		}
 		return "renamed"
+	case strings.Contains(f.GitCode, "T"):
+		// git's typechange: a file became a symlink or the other way round
+		return "type changed"
 	default:
 		return "modified"
 	}
 }
 
+// parseDecoration keeps HEAD and tags from a %D ref list:
+// "HEAD -> main, tag: v1.2.0, origin/main" becomes "HEAD, v1.2.0"
+func parseDecoration(refs string) string {
+	var kept []string
+	for _, ref := range strings.Split(refs, ", ") {
+		switch {
+		case ref == "HEAD" || strings.HasPrefix(ref, "HEAD -> "):
+			kept = append(kept, "HEAD")
+		case strings.HasPrefix(ref, "tag: "):
+			kept = append(kept, strings.TrimPrefix(ref, "tag: "))
+		}
+	}
+	return strings.Join(kept, ", ")
+}
+
 // DiffStats holds line addition/deletion counts
 type DiffStats struct {
 	Added   int
 	Deleted int
 }
 
-// shouldSkipFile returns true for temp/binary files that shouldn't be displayed
-func shouldSkipFile(path string) bool {
+// skipPatterns holds user-supplied globs (gitignore syntax) of files to skip
+var skipPatterns *ignore.Matcher
+
+// SetSkipPatterns extends the built-in skip list with gitignore-style globs
+// such as "*.generated.go" or "coverage/*", matched relative to the repo root
+func SetSkipPatterns(patterns []string) {
+	skipPatterns = ignore.Parse(patterns)
+}
+
+// shouldSkipFile returns true for temp/binary files that shouldn't be displayed,
+// or that match the scan's extra skip globs
+func shouldSkipFile(path string, skip *ignore.Matcher) bool {
+	if skip.Match(filepath.ToSlash(path), false) {
+		return true
+	}
 	base := filepath.Base(path)
 	// Vim swap files
 	if strings.HasSuffix(base, ".swp") || strings.HasSuffix(base, ".swo") {
@@ -77,18 +183,39 @@ func shouldSkipFile(path string) bool {
 	return false
 }
 
-// GetDiffStats returns +/- line counts for a file
-func GetDiffStats(dir, path string) DiffStats {
-	cmd := gitCmd("diff", "--numstat", "--", path)
-	cmd.Dir = dir
-	output, err := cmd.Output()
+// GetDiffStats returns +/- line counts for a file, cached until the file or index changes
+func GetDiffStats(ctx context.Context, dir, path string) DiffStats {
+	return cachedDiffStats(ctx, dir, path, "", func() (DiffStats, error) {
+		return diffStats(ctx, dir, path)
+	})
+}
+
+// GetRenameDiffStats returns +/- line counts for a renamed file against its
+// pre-rename path in HEAD, so an edited rename isn't counted as all-new
+func GetRenameDiffStats(ctx context.Context, dir, oldPath, path string) DiffStats {
+	return cachedDiffStats(ctx, dir, path, "rename:"+oldPath, func() (DiffStats, error) {
+		output, err := runGit(ctx, dir, "diff", "--numstat", "-M", "HEAD", "--", oldPath, path)
+		if err != nil {
+			return DiffStats{}, err
+		}
+		return parseNumstat(output), nil
+	})
+}
+
+func diffStats(ctx context.Context, dir, path string) (DiffStats, error) {
+	output, err := runGit(ctx, dir, "diff", "--numstat", "--", path)
 	if err != nil {
 		// Try for untracked files - compare to empty
-		cmd = gitCmd("diff", "--numstat", "/dev/null", path)
-		cmd.Dir = dir
-		output, _ = cmd.Output()
+		output, err = runGit(ctx, dir, "diff", "--numstat", "/dev/null", path)
+		if ctx.Err() != nil || errors.Is(err, ErrTimeout) {
+			return DiffStats{}, err
+		}
 	}
+	return parseNumstat(output), nil
+}
 
+// parseNumstat reads the added/deleted counts from `git diff --numstat` output
+func parseNumstat(output []byte) DiffStats {
 	stats := DiffStats{}
 	line := strings.TrimSpace(string(output))
 	if line == "" {
@@ -103,55 +230,9 @@ func GetDiffStats(dir, path string) DiffStats {
 	return stats
 }
 
-// GetDiffLines returns which lines were added/deleted/unchanged
-func GetDiffLines(dir, path string) map[int]string {
-	result := make(map[int]string)
-
-	// Get unified diff
-	cmd := gitCmd("diff", "-U0", "--", path)
-	cmd.Dir = dir
-	output, err := cmd.Output()
-	if err != nil {
-		return result
-	}
-
-	lineNum := 0
-	scanner := bufio.NewScanner(strings.NewReader(string(output)))
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		// Parse hunk header: @@ -start,count +start,count @@
-		if strings.HasPrefix(line, "@@") {
-			// Extract the +start number
-			parts := strings.Split(line, "+")
-			if len(parts) >= 2 {
-				numPart := strings.Split(parts[1], ",")[0]
-				numPart = strings.Split(numPart, " ")[0]
-				fmt.Sscanf(numPart, "%d", &lineNum)
-				lineNum-- // Will be incremented on first content line
-			}
-			continue
-		}
-
-		if strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++") {
-			lineNum++
-			result[lineNum] = "added"
-		} else if strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---") {
-			// Deleted lines don't have a line number in new file
-			result[lineNum] = "deleted"
-		} else if !strings.HasPrefix(line, "\\") && lineNum > 0 {
-			lineNum++
-		}
-	}
-
-	return result
-}
-
 // GetGitRoot returns the root of the git repository
-func GetGitRoot(dir string) (string, error) {
-	cmd := gitCmd("rev-parse", "--show-toplevel")
-	cmd.Dir = dir
-	output, err := cmd.Output()
+func GetGitRoot(ctx context.Context, dir string) (string, error) {
+	output, err := runGit(ctx, dir, "rev-parse", "--show-toplevel")
 	if err != nil {
 		return "", err
 	}
@@ -159,15 +240,20 @@ func GetGitRoot(dir string) (string, error) {
 }
 
 // GetSubmodules returns paths of submodules within a directory
-func GetSubmodules(dir string) []string {
-	cmd := gitCmd("submodule", "status", "--recursive")
+func GetSubmodules(ctx context.Context, dir string) []string {
+	ctx, cancel := context.WithTimeout(ctx, CommandTimeout)
+	defer cancel()
+
+	cmd := gitCmd(ctx, "submodule", "status", "--recursive")
 	cmd.Dir = dir
 
 	// Capture stdout separately - git may output valid data before hitting errors
 	var stdout, stderr strings.Builder
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	cmd.Run() // Ignore exit code - parse whatever stdout we got
+	start := time.Now()
+	err := cmd.Run() // Ignore exit code - parse whatever stdout we got
+	debuglog.Printf("git submodule status --recursive (in %s) took %s err=%v", dir, time.Since(start).Round(time.Microsecond), err)
 
 	var submodules []string
 	scanner := bufio.NewScanner(strings.NewReader(stdout.String()))
@@ -182,13 +268,31 @@ func GetSubmodules(dir string) []string {
 	return submodules
 }
 
+// ScanOptions tunes a status scan
+type ScanOptions struct {
+	Nested      bool            // also list files of nested repos that aren't submodules
+	NestedDepth int             // max directory depth walked for nested repos (0 = unlimited)
+	Skip        *ignore.Matcher // extra files to hide, on top of the built-in list
+	Paths       Pathspec        // --include/--exclude patterns, relative to the scanned dir
+}
+
+// DefaultScanOptions returns the options GetStatus scans with
+func DefaultScanOptions() ScanOptions {
+	return ScanOptions{Nested: ScanNestedRepos, NestedDepth: NestedRepoMaxDepth, Skip: skipPatterns, Paths: pathspec}
+}
+
 // GetStatus returns files from git status and recent commits
-func GetStatus(dir string) ([]FileStatus, error) {
+func GetStatus(ctx context.Context, dir string) ([]FileStatus, error) {
+	return GetStatusWith(ctx, dir, DefaultScanOptions())
+}
+
+// GetStatusWith is GetStatus with explicit scan options
+func GetStatusWith(ctx context.Context, dir string, opts ScanOptions) ([]FileStatus, error) {
 	var files []FileStatus
 	seen := make(map[string]bool)
 
 	// Find git root and calculate prefix for filtering
-	gitRoot, err := GetGitRoot(dir)
+	gitRoot, err := GetGitRoot(ctx, dir)
 	if err != nil {
 		return nil, err
 	}
@@ -207,61 +311,61 @@ func GetStatus(dir string) ([]FileStatus, error) {
 		}
 	}
 
-	// Get uncommitted files first
-	uncommitted, err := getUncommitted(gitRoot, relPrefix, gitRoot)
-	if err != nil {
-		return nil, err
-	}
-	for _, f := range uncommitted {
-		if !seen[f.Path] {
-			files = append(files, f)
-			seen[f.Path] = true
-		}
-	}
-
-	// Get recently committed files
-	committed, err := getRecentlyCommitted(gitRoot, relPrefix, gitRoot)
-	if err != nil {
-		return nil, err
-	}
-	for _, f := range committed {
-		if !seen[f.Path] {
-			files = append(files, f)
-			seen[f.Path] = true
-		}
+	// Let git narrow status and log to the watched subdirectory and the
+	// pathspec, so a busy monorepo root doesn't slow every refresh; other
+	// repos' files are checked against the pathspec when merging below
+	var specArgs []string
+	if relPrefix != "" || !opts.Paths.IsEmpty() {
+		specArgs = opts.Paths.args(relPrefix)
 	}
 
-	// Check submodules within target directory
-	submodules := GetSubmodules(gitRoot)
-	for _, subPath := range submodules {
-		subFullPath := filepath.Join(gitRoot, subPath)
-
+	// Collect uncommitted, committed, submodule, and nested-repo files
+	// concurrently so refresh latency is the slowest scan, not the sum
+	var uncommitted, committed, submoduleFiles, nestedFiles []FileStatus
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		var err error
+		uncommitted, err = getUncommitted(gctx, gitRoot, relPrefix, gitRoot, opts.Skip, specArgs)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		committed, err = getRecentlyCommitted(gctx, gitRoot, relPrefix, gitRoot, opts.Skip, specArgs)
+		return err
+	})
+	g.Go(func() error {
 		// Only process submodules within our target directory
-		if !strings.HasPrefix(subFullPath, dir) {
-			continue
-		}
-
-		// Get files from this submodule
-		subFiles, err := getNestedRepoFiles(subFullPath, dir)
-		if err != nil {
-			continue
-		}
-		for _, f := range subFiles {
-			if !seen[f.Path] {
-				files = append(files, f)
-				seen[f.Path] = true
+		var repos []string
+		for _, subPath := range GetSubmodules(gctx, gitRoot) {
+			subFullPath := filepath.Join(gitRoot, subPath)
+			if strings.HasPrefix(subFullPath, dir) {
+				repos = append(repos, subFullPath)
 			}
 		}
+		submoduleFiles = collectRepoFiles(gctx, repos, dir, opts.Skip)
+		return nil
+	})
+	g.Go(func() error {
+		// Also check for nested git repos that aren't submodules
+		var nested []string
+		if opts.Nested {
+			nested = findNestedRepos(gctx, dir, gitRoot, opts.NestedDepth)
+		}
+		nestedFiles = collectRepoFiles(gctx, nested, dir, opts.Skip)
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
 	}
 
-	// Also check for nested git repos that aren't submodules
-	nestedRepos := findNestedRepos(dir, gitRoot)
-	for _, repoPath := range nestedRepos {
-		subFiles, err := getNestedRepoFiles(repoPath, dir)
-		if err != nil {
-			continue
-		}
-		for _, f := range subFiles {
+	// Merge in priority order: the first occurrence of a path wins.
+	// Paths hidden via .perchignore or outside the pathspec are dropped.
+	hidden := ignore.LoadDir(dir)
+	for _, group := range [][]FileStatus{uncommitted, committed, submoduleFiles, nestedFiles} {
+		for _, f := range group {
+			if hidden.Match(filepath.ToSlash(f.Path), false) || !opts.Paths.Match(filepath.ToSlash(f.Path)) {
+				continue
+			}
 			if !seen[f.Path] {
 				files = append(files, f)
 				seen[f.Path] = true
@@ -277,18 +381,75 @@ func GetStatus(dir string) ([]FileStatus, error) {
 	return files, nil
 }
 
-// findNestedRepos finds git repositories nested within a directory that aren't submodules
-func findNestedRepos(dir, parentGitRoot string) []string {
+// ScanNestedRepos enables discovery of nested git repos that aren't submodules
+var ScanNestedRepos = true
+
+// NestedRepoMaxDepth limits how many directory levels below the target dir
+// are walked when looking for nested repos (0 = unlimited)
+var NestedRepoMaxDepth = 6
+
+// nestedRepoKey identifies one nested-repo walk
+type nestedRepoKey struct {
+	dir   string
+	depth int
+}
+
+// nestedRepoCache holds discovered nested repos per target dir until invalidated
+var nestedRepoCache = struct {
+	sync.Mutex
+	repos map[nestedRepoKey][]string
+}{repos: make(map[nestedRepoKey][]string)}
+
+// InvalidateNestedRepos forgets discovered nested repos so the next GetStatus
+// re-walks the tree. Call it when a .git entry appears or disappears.
+func InvalidateNestedRepos() {
+	nestedRepoCache.Lock()
+	nestedRepoCache.repos = make(map[nestedRepoKey][]string)
+	nestedRepoCache.Unlock()
+}
+
+// findNestedRepos returns cached nested repos for dir, walking the tree only
+// on the first call or after InvalidateNestedRepos
+func findNestedRepos(ctx context.Context, dir, parentGitRoot string, maxDepth int) []string {
+	key := nestedRepoKey{dir: dir, depth: maxDepth}
+	nestedRepoCache.Lock()
+	repos, ok := nestedRepoCache.repos[key]
+	nestedRepoCache.Unlock()
+	if ok {
+		return repos
+	}
+
+	start := time.Now()
+	repos = walkNestedRepos(ctx, dir, parentGitRoot, maxDepth)
+	debuglog.Printf("nested repo scan of %s found %d repos in %s", dir, len(repos), time.Since(start).Round(time.Microsecond))
+	if ctx.Err() != nil {
+		return repos // partial walk, don't cache
+	}
+
+	nestedRepoCache.Lock()
+	nestedRepoCache.repos[key] = repos
+	nestedRepoCache.Unlock()
+	return repos
+}
+
+// walkNestedRepos finds git repositories nested within a directory that aren't submodules
+func walkNestedRepos(ctx context.Context, dir, parentGitRoot string, maxDepth int) []string {
 	var repos []string
 	submodules := make(map[string]bool)
 
 	// Get list of known submodules to exclude
-	for _, sub := range GetSubmodules(parentGitRoot) {
+	for _, sub := range GetSubmodules(ctx, parentGitRoot) {
 		submodules[filepath.Join(parentGitRoot, sub)] = true
 	}
 
-	// Walk directory looking for .git directories
+	// Directories excluded by the repo's ignore rules aren't traversed
+	ignored := ignoredDirs(ctx, parentGitRoot)
+
+	// Walk directory looking for .git directories and .git files
 	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		if err != nil {
 			return filepath.SkipDir
 		}
@@ -298,6 +459,23 @@ func findNestedRepos(dir, parentGitRoot string) []string {
 			return filepath.SkipDir
 		}
 
+		// Don't descend into ignored trees, but still notice an ignored
+		// directory that is itself a repo (a common way to vendor one)
+		if info.IsDir() && ignored[path] {
+			if _, err := os.Stat(filepath.Join(path, ".git")); err == nil && !submodules[path] {
+				repos = append(repos, path)
+			}
+			return filepath.SkipDir
+		}
+
+		// Bound the walk depth below the target directory
+		if info.IsDir() && maxDepth > 0 && path != dir && info.Name() != ".git" {
+			rel, _ := filepath.Rel(dir, path)
+			if strings.Count(rel, string(filepath.Separator))+1 > maxDepth {
+				return filepath.SkipDir
+			}
+		}
+
 		// Skip common ignored directories
 		if info.IsDir() {
 			name := info.Name()
@@ -314,6 +492,12 @@ func findNestedRepos(dir, parentGitRoot string) []string {
 			case "node_modules", "vendor", ".next", "__pycache__", "archive", ".cache", "tmp", "build", "dist":
 				return filepath.SkipDir
 			}
+		} else if info.Name() == ".git" && filepath.Dir(path) != parentGitRoot {
+			// A .git file marks a linked worktree (or unregistered submodule)
+			repoPath := filepath.Dir(path)
+			if !submodules[repoPath] && isGitFile(path) {
+				repos = append(repos, repoPath)
+			}
 		}
 
 		return nil
@@ -322,14 +506,41 @@ func findNestedRepos(dir, parentGitRoot string) []string {
 	return repos
 }
 
+// ignoredDirs returns absolute paths of directories ignored by the repo's
+// .gitignore/exclude rules, collapsed to the top-most ignored directory
+func ignoredDirs(ctx context.Context, gitRoot string) map[string]bool {
+	ignored := make(map[string]bool)
+	output, err := runGit(ctx, gitRoot, "ls-files", "--others", "--ignored", "--exclude-standard", "--directory", "-z")
+	if err != nil {
+		return ignored
+	}
+	for _, entry := range strings.Split(string(output), "\x00") {
+		if strings.HasSuffix(entry, "/") {
+			ignored[filepath.Join(gitRoot, entry)] = true
+		}
+	}
+	return ignored
+}
+
+// collectRepoFiles gathers files from each nested repo, skipping repos that fail
+func collectRepoFiles(ctx context.Context, repos []string, targetDir string, skip *ignore.Matcher) []FileStatus {
+	var files []FileStatus
+	for _, repoPath := range repos {
+		repoFiles, err := getNestedRepoFiles(ctx, repoPath, targetDir, skip)
+		if err != nil {
+			continue
+		}
+		files = append(files, repoFiles...)
+	}
+	return files
+}
+
 // getNestedRepoFiles gets files from a nested repo, with paths relative to targetDir
-func getNestedRepoFiles(repoPath, targetDir string) ([]FileStatus, error) {
+func getNestedRepoFiles(ctx context.Context, repoPath, targetDir string, skip *ignore.Matcher) ([]FileStatus, error) {
 	var files []FileStatus
 
 	// Get uncommitted files
-	cmd := gitCmd("status", "--porcelain", "-uall")
-	cmd.Dir = repoPath
-	output, err := cmd.Output()
+	output, err := runGit(ctx, repoPath, "status", "--porcelain", "-uall")
 	if err != nil {
 		return nil, err
 	}
@@ -341,43 +552,46 @@ func getNestedRepoFiles(repoPath, targetDir string) ([]FileStatus, error) {
 			continue
 		}
 
-		gitCode := line[:2]
-		path := line[3:]
+		gitCode, path, oldPath := parsePorcelainLine(line)
 
 		// Skip temp/binary files
-		if shouldSkipFile(path) {
+		if shouldSkipFile(path, skip) {
 			continue
 		}
 
 		fullPath := filepath.Join(repoPath, path)
-		info, err := os.Stat(fullPath)
-		if err != nil || info.IsDir() {
+		modTime, ok := changedModTime(fullPath, gitCode)
+		if !ok {
 			continue
 		}
 
 		// Path relative to target directory
 		displayPath, _ := filepath.Rel(targetDir, fullPath)
+		displayOldPath := ""
+		if oldPath != "" {
+			displayOldPath, _ = filepath.Rel(targetDir, filepath.Join(repoPath, oldPath))
+		}
 
 		files = append(files, FileStatus{
-			Status:   "uncommitted",
-			GitCode:  gitCode,
-			Path:     displayPath,
-			FullPath: path,
-			GitRoot:  repoPath,
-			IsFile:   true,
-			ModTime:  info.ModTime(),
+			Status:      "uncommitted",
+			GitCode:     gitCode,
+			Path:        displayPath,
+			FullPath:    path,
+			OldPath:     displayOldPath,
+			OldFullPath: oldPath,
+			GitRoot:     repoPath,
+			IsFile:      true,
+			ModTime:     modTime,
 		})
 	}
 
 	// Also get recently committed files from nested repo
-	cmd = gitCmd("log", "--name-only", "--pretty=format:%h|%ar", "-n", "5")
-	cmd.Dir = repoPath
-	output, err = cmd.Output()
+	output, err = runGit(ctx, repoPath, "log", "--name-only", "--pretty=format:%h|%ar|%D|%an", "-n", "5")
 	if err != nil {
 		return files, nil // Return what we have
 	}
 
-	var currentCommit, currentTime string
+	var currentCommit, currentTime, currentRefs, currentAuthor string
 	seenInRepo := make(map[string]bool)
 	for _, f := range files {
 		seenInRepo[f.FullPath] = true
@@ -391,9 +605,14 @@ func getNestedRepoFiles(repoPath, targetDir string) ([]FileStatus, error) {
 		}
 
 		if strings.Contains(line, "|") {
-			parts := strings.SplitN(line, "|", 2)
+			parts := strings.SplitN(line, "|", 4)
+			if len(parts) < 4 {
+				continue
+			}
 			currentCommit = parts[0]
 			currentTime = parts[1]
+			currentRefs = parseDecoration(parts[2])
+			currentAuthor = parts[3]
 			continue
 		}
 
@@ -401,7 +620,7 @@ func getNestedRepoFiles(repoPath, targetDir string) ([]FileStatus, error) {
 			continue
 		}
 
-		if shouldSkipFile(line) {
+		if shouldSkipFile(line, skip) {
 			continue
 		}
 
@@ -420,6 +639,8 @@ func getNestedRepoFiles(repoPath, targetDir string) ([]FileStatus, error) {
 			GitRoot:  repoPath,
 			Commit:   currentCommit,
 			TimeAgo:  currentTime,
+			Author:   currentAuthor,
+			Refs:     currentRefs,
 			IsFile:   true,
 			ModTime:  info.ModTime(),
 		})
@@ -429,10 +650,22 @@ func getNestedRepoFiles(repoPath, targetDir string) ([]FileStatus, error) {
 	return files, nil
 }
 
-func getUncommitted(gitRoot, prefix, fileGitRoot string) ([]FileStatus, error) {
-	cmd := gitCmd("status", "--porcelain", "-uall")
-	cmd.Dir = gitRoot
-	output, err := cmd.Output()
+// parsePorcelainLine splits a `git status --porcelain` line into its code and
+// path. Renames and copies ("R  old -> new") also return the original path.
+func parsePorcelainLine(line string) (gitCode, path, oldPath string) {
+	gitCode = line[:2]
+	path = line[3:]
+	if strings.ContainsAny(gitCode, "RC") {
+		if i := strings.Index(path, " -> "); i >= 0 {
+			oldPath = path[:i]
+			path = path[i+len(" -> "):]
+		}
+	}
+	return gitCode, path, oldPath
+}
+
+func getUncommitted(ctx context.Context, gitRoot, prefix, fileGitRoot string, skip *ignore.Matcher, specArgs []string) ([]FileStatus, error) {
+	output, err := runGit(ctx, gitRoot, append([]string{"status", "--porcelain", "-uall"}, specArgs...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -445,57 +678,78 @@ func getUncommitted(gitRoot, prefix, fileGitRoot string) ([]FileStatus, error) {
 			continue
 		}
 
-		gitCode := line[:2]
-		path := line[3:]
-
-		// Filter by prefix (subdirectory)
-		if prefix != "" && !strings.HasPrefix(path, prefix) {
-			continue
-		}
+		gitCode, path, oldPath := parsePorcelainLine(line)
 
 		// Skip temp/binary files
-		if shouldSkipFile(path) {
+		if shouldSkipFile(path, skip) {
 			continue
 		}
 
-		// Check if it's a file
+		// Check if it's a file (or a deleted one)
 		fullPath := filepath.Join(gitRoot, path)
-		info, err := os.Stat(fullPath)
-		if err != nil || info.IsDir() {
+		modTime, ok := changedModTime(fullPath, gitCode)
+		if !ok {
 			continue
 		}
 
 		// Store path relative to target directory for display
 		displayPath := path
+		displayOldPath := oldPath
 		if prefix != "" {
 			displayPath = strings.TrimPrefix(path, prefix)
+			displayOldPath = strings.TrimPrefix(oldPath, prefix)
 		}
 
 		files = append(files, FileStatus{
-			Status:   "uncommitted",
-			GitCode:  gitCode,
-			Path:     displayPath,
-			FullPath: path,
-			GitRoot:  fileGitRoot,
-			IsFile:   true,
-			ModTime:  info.ModTime(),
+			Status:      "uncommitted",
+			GitCode:     gitCode,
+			Path:        displayPath,
+			FullPath:    path,
+			OldPath:     displayOldPath,
+			OldFullPath: oldPath,
+			GitRoot:     fileGitRoot,
+			IsFile:      true,
+			ModTime:     modTime,
 		})
 	}
 
 	return files, nil
 }
 
-func getRecentlyCommitted(gitRoot, prefix, fileGitRoot string) ([]FileStatus, error) {
-	// Get last 5 commits with files
-	cmd := gitCmd("log", "--name-only", "--pretty=format:%h|%ar", "-n", "5")
-	cmd.Dir = gitRoot
-	output, err := cmd.Output()
+// changedModTime returns when a changed path was last modified, and false
+// when it isn't a file or symlink. A deleted file has no mtime of its own, so it takes
+// its nearest remaining directory's, which removing it updated.
+func changedModTime(fullPath, gitCode string) (time.Time, bool) {
+	info, err := os.Stat(fullPath)
+	if err == nil {
+		return info.ModTime(), !info.IsDir()
+	}
+	if link, err := os.Lstat(fullPath); err == nil && link.Mode()&os.ModeSymlink != 0 {
+		// A broken symlink is still a changed file
+		return link.ModTime(), true
+	}
+	if !strings.Contains(gitCode, "D") {
+		return time.Time{}, false
+	}
+	for dir := filepath.Dir(fullPath); ; dir = filepath.Dir(dir) {
+		if info, err := os.Stat(dir); err == nil {
+			return info.ModTime(), true
+		}
+		if filepath.Dir(dir) == dir {
+			return time.Time{}, true
+		}
+	}
+}
+
+func getRecentlyCommitted(ctx context.Context, gitRoot, prefix, fileGitRoot string, skip *ignore.Matcher, specArgs []string) ([]FileStatus, error) {
+	// Get last 5 commits with files (touching the pathspec, if there is one)
+	output, err := runGit(ctx, gitRoot, append([]string{"log", "--name-only", "--pretty=format:%h|%ar|%D|%an", "-n", "5"}, specArgs...)...)
 	if err != nil {
 		return nil, err
 	}
 
 	var files []FileStatus
-	var currentCommit, currentTime string
+	var currentCommit, currentTime, currentRefs, currentAuthor string
 
 	scanner := bufio.NewScanner(strings.NewReader(string(output)))
 	for scanner.Scan() {
@@ -506,9 +760,14 @@ func getRecentlyCommitted(gitRoot, prefix, fileGitRoot string) ([]FileStatus, er
 
 		// Check if it's a commit line (contains |)
 		if strings.Contains(line, "|") {
-			parts := strings.SplitN(line, "|", 2)
+			parts := strings.SplitN(line, "|", 4)
+			if len(parts) < 4 {
+				continue
+			}
 			currentCommit = parts[0]
 			currentTime = parts[1]
+			currentRefs = parseDecoration(parts[2])
+			currentAuthor = parts[3]
 			continue
 		}
 
@@ -517,13 +776,8 @@ func getRecentlyCommitted(gitRoot, prefix, fileGitRoot string) ([]FileStatus, er
 			continue
 		}
 
-		// Filter by prefix (subdirectory)
-		if prefix != "" && !strings.HasPrefix(line, prefix) {
-			continue
-		}
-
 		// Skip temp/binary files
-		if shouldSkipFile(line) {
+		if shouldSkipFile(line, skip) {
 			continue
 		}
 
@@ -547,6 +801,8 @@ func getRecentlyCommitted(gitRoot, prefix, fileGitRoot string) ([]FileStatus, er
 			GitRoot:  fileGitRoot,
 			Commit:   currentCommit,
 			TimeAgo:  currentTime,
+			Author:   currentAuthor,
+			Refs:     currentRefs,
 			IsFile:   true,
 			ModTime:  info.ModTime(),
 		})
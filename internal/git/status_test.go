@@ -0,0 +1,22 @@
+package git
+
+import "testing"
+
+func TestParseDecoration(t *testing.T) {
+	tests := []struct {
+		refs string
+		want string
+	}{
+		{"", ""},
+		{"HEAD -> main, origin/main", "HEAD"},
+		{"HEAD -> main, tag: v1.2.0, origin/main", "HEAD, v1.2.0"},
+		{"tag: v1.1.0", "v1.1.0"},
+		{"HEAD", "HEAD"},
+		{"origin/feature", ""},
+	}
+	for _, tt := range tests {
+		if got := parseDecoration(tt.refs); got != tt.want {
+			t.Errorf("parseDecoration(%q) = %q; want %q", tt.refs, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FileActivity is the change volume for one file over a period
+type FileActivity struct {
+	Path    string
+	Added   int
+	Deleted int
+	Commits int // commits touching the file (0 for working-tree-only changes)
+}
+
+// CommitSummary is one commit in a period summary
+type CommitSummary struct {
+	Hash    string
+	Subject string
+	TimeAgo string
+}
+
+// DaySummary aggregates the commits and working-tree changes since a time
+type DaySummary struct {
+	Commits []CommitSummary
+	Files   []FileActivity // busiest (most lines changed) first
+	Added   int
+	Deleted int
+}
+
+// GetDaySummary aggregates commits since `since` plus uncommitted changes,
+// for paths under dir
+func GetDaySummary(ctx context.Context, dir string, since time.Time) (DaySummary, error) {
+	var summary DaySummary
+	files := make(map[string]*FileActivity)
+	activity := func(path string) *FileActivity {
+		if files[path] == nil {
+			files[path] = &FileActivity{Path: path}
+		}
+		return files[path]
+	}
+
+	commits, err := logActivity(ctx, dir, since.Format(time.RFC3339), activity)
+	if err != nil {
+		return summary, err
+	}
+	summary.Commits = commits
+
+	// Uncommitted changes (staged and unstaged) on top
+	if output, err := runGit(ctx, dir, append([]string{"diff", "HEAD", "--no-renames", "--numstat", "--relative"}, pathspec.args("")...)...); err == nil {
+		for _, line := range strings.Split(string(output), "\n") {
+			added, deleted, path, ok := parseNumstatLine(line)
+			if !ok {
+				continue
+			}
+			a := activity(path)
+			a.Added += added
+			a.Deleted += deleted
+		}
+	}
+
+	for _, a := range files {
+		summary.Files = append(summary.Files, *a)
+		summary.Added += a.Added
+		summary.Deleted += a.Deleted
+	}
+	sort.Slice(summary.Files, func(i, j int) bool {
+		ci := summary.Files[i].Added + summary.Files[i].Deleted
+		cj := summary.Files[j].Added + summary.Files[j].Deleted
+		if ci != cj {
+			return ci > cj
+		}
+		return summary.Files[i].Path < summary.Files[j].Path
+	})
+	return summary, nil
+}
+
+// logActivity walks commits since `since` (any date git accepts) under dir,
+// adding each file's line counts to the entry returned by activity
+func logActivity(ctx context.Context, dir, since string, activity func(path string) *FileActivity) ([]CommitSummary, error) {
+	args := []string{"log", "--since=" + since, "--no-renames",
+		"--numstat", "--relative", "--format=%x00%h%x1f%ar%x1f%s"}
+	output, err := runGit(ctx, dir, append(args, pathspec.args("")...)...)
+	if err != nil {
+		return nil, err
+	}
+	var commits []CommitSummary
+	for _, record := range strings.Split(string(output), "\x00") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		header := strings.SplitN(lines[0], "\x1f", 3)
+		if len(header) < 3 {
+			continue
+		}
+		commits = append(commits, CommitSummary{Hash: header[0], TimeAgo: header[1], Subject: header[2]})
+		for _, line := range lines[1:] {
+			added, deleted, path, ok := parseNumstatLine(line)
+			if !ok {
+				continue
+			}
+			a := activity(path)
+			a.Added += added
+			a.Deleted += deleted
+			a.Commits++
+		}
+	}
+	return commits, nil
+}
+
+// GetChurn ranks files under dir by how many commits touched them in the
+// last `days` days (ties broken by lines changed), and returns the commit count
+func GetChurn(ctx context.Context, dir string, days int) ([]FileActivity, int, error) {
+	files := make(map[string]*FileActivity)
+	commits, err := logActivity(ctx, dir, fmt.Sprintf("%d days ago", days), func(path string) *FileActivity {
+		if files[path] == nil {
+			files[path] = &FileActivity{Path: path}
+		}
+		return files[path]
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ranked := make([]FileActivity, 0, len(files))
+	for _, a := range files {
+		ranked = append(ranked, *a)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Commits != ranked[j].Commits {
+			return ranked[i].Commits > ranked[j].Commits
+		}
+		ci, cj := ranked[i].Added+ranked[i].Deleted, ranked[j].Added+ranked[j].Deleted
+		if ci != cj {
+			return ci > cj
+		}
+		return ranked[i].Path < ranked[j].Path
+	})
+	return ranked, len(commits), nil
+}
+
+// parseNumstatLine parses one "added<TAB>deleted<TAB>path" line; binary
+// files ("-") count as zero lines
+func parseNumstatLine(line string) (added, deleted int, path string, ok bool) {
+	parts := strings.SplitN(line, "\t", 3)
+	if len(parts) < 3 || parts[2] == "" {
+		return 0, 0, "", false
+	}
+	fmt.Sscanf(parts[0], "%d", &added)
+	fmt.Sscanf(parts[1], "%d", &deleted)
+	return added, deleted, parts[2], true
+}
@@ -0,0 +1,90 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WIPRef is the hidden ref SaveWIP commits the working tree to
+const WIPRef = "refs/perch/wip"
+
+// wipIdentity authors WIP commits, so they work without user.name/email
+var wipIdentity = []string{
+	"GIT_AUTHOR_NAME=perch", "GIT_AUTHOR_EMAIL=perch@localhost",
+	"GIT_COMMITTER_NAME=perch", "GIT_COMMITTER_EMAIL=perch@localhost",
+}
+
+// SaveWIP commits the whole working tree, untracked (but not ignored) files
+// included, to WIPRef. It stages into a copy of the index, so HEAD, the real
+// index, and the branch are untouched. Each WIP commit's parents are the
+// previous one and HEAD. It returns "" when there is nothing new to save:
+// the tree matches the last WIP commit, or HEAD with no WIP commit yet.
+func SaveWIP(ctx context.Context, dir string) (string, error) {
+	gitDir, err := GetGitDir(ctx, dir)
+	if err != nil {
+		return "", err
+	}
+	tmp, err := os.MkdirTemp("", "perch-wip-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	// Starting from the real index keeps git's stat cache, so only files
+	// that changed are re-read
+	index := filepath.Join(tmp, "index")
+	if data, err := os.ReadFile(filepath.Join(gitDir, "index")); err == nil {
+		if err := os.WriteFile(index, data, 0o644); err != nil {
+			return "", err
+		}
+	}
+	env := append([]string{"GIT_INDEX_FILE=" + index}, wipIdentity...)
+	if _, err := runGitEnv(ctx, dir, env, "add", "--all"); err != nil {
+		return "", err
+	}
+	output, err := runGitEnv(ctx, dir, env, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	tree := strings.TrimSpace(string(output))
+
+	var parents []string
+	prev := revParse(ctx, dir, WIPRef)
+	head := revParse(ctx, dir, "HEAD")
+	for _, p := range []string{prev, head} {
+		if p != "" {
+			parents = append(parents, "-p", p)
+		}
+	}
+	last := head
+	if prev != "" {
+		last = prev
+	}
+	if last != "" && revParse(ctx, dir, last+"^{tree}") == tree {
+		return "", nil
+	}
+
+	args := append([]string{"commit-tree", tree, "-m", "perch wip"}, parents...)
+	output, err = runGitEnv(ctx, dir, env, args...)
+	if err != nil {
+		return "", err
+	}
+	commit := strings.TrimSpace(string(output))
+	// Passing the old value makes the update fail rather than clobber a
+	// WIP commit another perch saved in the meantime
+	if _, err := runGit(ctx, dir, "update-ref", WIPRef, commit, prev); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
+
+// revParse resolves rev to an object name, or "" when it doesn't exist
+func revParse(ctx context.Context, dir, rev string) string {
+	output, err := runGit(ctx, dir, "rev-parse", "--verify", "--quiet", rev)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -0,0 +1,100 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Worktree is one entry from `git worktree list`
+type Worktree struct {
+	Path    string // absolute checkout path
+	Head    string // short commit hash
+	Branch  string // branch name, empty when detached
+	Current bool   // true for the worktree containing the queried dir
+}
+
+// isGitFile reports whether path is a "gitdir: ..." pointer file, as used by
+// linked worktrees and submodules in place of a .git directory
+func isGitFile(path string) bool {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(string(content), "gitdir:")
+}
+
+// IsLinkedWorktree reports whether dir is inside a linked (non-main) worktree
+func IsLinkedWorktree(ctx context.Context, dir string) bool {
+	output, err := runGit(ctx, dir, "rev-parse", "--absolute-git-dir", "--git-common-dir")
+	if err != nil {
+		return false
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) < 2 {
+		return false
+	}
+	gitDir := lines[0]
+	commonDir := lines[1]
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(dir, commonDir)
+	}
+	return filepath.Clean(gitDir) != filepath.Clean(commonDir)
+}
+
+// GetGitDir returns the absolute git directory for dir, following .git files
+func GetGitDir(ctx context.Context, dir string) (string, error) {
+	output, err := runGit(ctx, dir, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetGitCommonDir returns the absolute directory holding refs shared by all
+// worktrees of dir's repo (the same as GetGitDir outside linked worktrees)
+func GetGitCommonDir(ctx context.Context, dir string) (string, error) {
+	output, err := runGit(ctx, dir, "rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+	commonDir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(dir, commonDir)
+	}
+	return filepath.Clean(commonDir), nil
+}
+
+// ListWorktrees returns the main worktree and all linked worktrees of dir's repo
+func ListWorktrees(ctx context.Context, dir string) ([]Worktree, error) {
+	output, err := runGit(ctx, dir, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	root, _ := GetGitRoot(ctx, dir)
+
+	var worktrees []Worktree
+	var current *Worktree
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			worktrees = append(worktrees, Worktree{Path: strings.TrimPrefix(line, "worktree ")})
+			current = &worktrees[len(worktrees)-1]
+			current.Current = current.Path == root
+		case current == nil:
+			continue
+		case strings.HasPrefix(line, "HEAD "):
+			current.Head = strings.TrimPrefix(line, "HEAD ")
+			if len(current.Head) > 7 {
+				current.Head = current.Head[:7]
+			}
+		case strings.HasPrefix(line, "branch "):
+			current.Branch = strings.TrimPrefix(strings.TrimPrefix(line, "branch "), "refs/heads/")
+		}
+	}
+	return worktrees, nil
+}
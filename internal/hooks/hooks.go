@@ -0,0 +1,98 @@
+// Package hooks runs user commands on perch lifecycle events. Each command
+// gets the event as a JSON object on stdin and its name in $PERCH_EVENT.
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kateleext/perch/internal/debuglog"
+)
+
+// Lifecycle events hooks can subscribe to
+const (
+	FileSelected   = "file-selected"
+	ChangeDetected = "change-detected"
+	CommitCreated  = "commit-created"
+)
+
+// Events lists every supported event name
+var Events = []string{FileSelected, ChangeDetected, CommitCreated}
+
+// Timeout bounds a single hook run so a stuck script can't pile up processes
+var Timeout = 30 * time.Second
+
+var (
+	mu       sync.RWMutex
+	commands = map[string][]string{}
+)
+
+// Register parses an "event=command" spec and adds the hook
+func Register(spec string) error {
+	event, command, ok := strings.Cut(spec, "=")
+	event = strings.TrimSpace(event)
+	if !ok || strings.TrimSpace(command) == "" {
+		return fmt.Errorf("hook %q: want event=command", spec)
+	}
+	known := false
+	for _, e := range Events {
+		known = known || e == event
+	}
+	if !known {
+		return fmt.Errorf("hook %q: unknown event %q (want one of %s)", spec, event, strings.Join(Events, ", "))
+	}
+	mu.Lock()
+	commands[event] = append(commands[event], command)
+	mu.Unlock()
+	return nil
+}
+
+// Has reports whether any hook listens for event, so callers can skip
+// gathering payloads nobody will read
+func Has(event string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return len(commands[event]) > 0
+}
+
+// Fire runs every hook for event in dir, blocking until they finish.
+// The payload is sent as JSON with an added "event" field.
+func Fire(event, dir string, payload map[string]any) {
+	mu.RLock()
+	cmds := commands[event]
+	mu.RUnlock()
+	if len(cmds) == 0 {
+		return
+	}
+
+	body := map[string]any{"event": event, "dir": dir}
+	for k, v := range payload {
+		body[k] = v
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		debuglog.Printf("hook %s: %v", event, err)
+		return
+	}
+
+	for _, command := range cmds {
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Dir = dir
+		cmd.Stdin = bytes.NewReader(data)
+		cmd.Env = append(os.Environ(), "PERCH_EVENT="+event)
+		if err := cmd.Start(); err != nil {
+			debuglog.Printf("hook %s %q: %v", event, command, err)
+			continue
+		}
+		timer := time.AfterFunc(Timeout, func() { cmd.Process.Kill() })
+		err := cmd.Wait()
+		timer.Stop()
+		debuglog.Printf("hook %s %q: err=%v", event, command, err)
+	}
+}
@@ -0,0 +1,189 @@
+// Package ignore implements gitignore-style path patterns for .perchignore
+// and user-supplied skip globs
+package ignore
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// rule is one compiled pattern line
+type rule struct {
+	negate   bool // "!pattern" re-includes a path
+	dirOnly  bool // "pattern/" matches directories only
+	anchored bool // pattern contains a slash, so it matches from the root
+	re       *regexp.Regexp
+}
+
+// Matcher tests slash-separated relative paths against gitignore-style rules.
+// A nil Matcher matches nothing.
+type Matcher struct {
+	rules []rule
+}
+
+// Parse compiles pattern lines; blank lines and "#" comments are skipped
+func Parse(lines []string) *Matcher {
+	m := &Matcher{}
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r := rule{}
+		if strings.HasPrefix(line, "!") {
+			r.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			r.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		re, err := regexp.Compile("^" + globToRegexp(line) + "$")
+		if err != nil {
+			continue
+		}
+		r.re = re
+		m.rules = append(m.rules, r)
+	}
+	return m
+}
+
+// Load reads patterns from a file such as .perchignore
+func Load(path string) (*Matcher, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return Parse(lines), scanner.Err()
+}
+
+// Empty reports whether the matcher has no rules
+func (m *Matcher) Empty() bool {
+	return m == nil || len(m.rules) == 0
+}
+
+// Match reports whether path (relative, slash-separated) is ignored. As in
+// git, a path inside an ignored directory is ignored too.
+func (m *Matcher) Match(path string, isDir bool) bool {
+	if m.Empty() {
+		return false
+	}
+	path = strings.Trim(path, "/")
+	parts := strings.Split(path, "/")
+	for i := 1; i < len(parts); i++ {
+		if m.matchOne(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return m.matchOne(path, isDir)
+}
+
+// matchOne applies the rules to a single path; the last matching rule wins
+func (m *Matcher) matchOne(path string, isDir bool) bool {
+	base := path
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		base = path[i+1:]
+	}
+	ignored := false
+	for _, r := range m.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		target := base
+		if r.anchored {
+			target = path
+		}
+		if r.re.MatchString(target) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// globToRegexp translates gitignore glob syntax (*, ?, **, [...]) to a regexp
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**"):
+			b.WriteString("(/.*)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// FileName is the per-directory file of paths perch hides from its list
+const FileName = ".perchignore"
+
+// LoadDir reads dir's .perchignore; a missing or unreadable file yields nil
+func LoadDir(dir string) *Matcher {
+	m, err := Load(filepath.Join(dir, FileName))
+	if err != nil {
+		return nil
+	}
+	return m
+}
+
+// AppendPattern adds a pattern to dir's .perchignore, creating the file
+// (ignoring itself) if needed
+func AppendPattern(dir, pattern string) error {
+	path := filepath.Join(dir, FileName)
+	_, statErr := os.Stat(path)
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if os.IsNotExist(statErr) {
+		if _, err := f.WriteString("# paths hidden from perch (gitignore syntax)\n/" + FileName + "\n"); err != nil {
+			return err
+		}
+	}
+	_, err = f.WriteString(pattern + "\n")
+	return err
+}
@@ -0,0 +1,42 @@
+package ignore
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	m := Parse([]string{
+		"# generated noise",
+		"*.lock",
+		"!keep.lock",
+		"coverage/",
+		"/docs/*.md",
+		"**/gen/**",
+	})
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"Cargo.lock", false, true},
+		{"sub/yarn.lock", false, true},
+		{"keep.lock", false, false},
+		{"coverage/index.html", false, true},
+		{"coverage", false, false}, // dir-only rule doesn't match a file
+		{"docs/readme.md", false, true},
+		{"src/docs/readme.md", false, false}, // anchored to the root
+		{"a/gen/b/c.go", false, true},
+		{"main.go", false, false},
+	}
+	for _, tt := range tests {
+		if got := m.Match(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("Match(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestNilMatcher(t *testing.T) {
+	var m *Matcher
+	if m.Match("anything", false) {
+		t.Error("nil matcher should match nothing")
+	}
+}
@@ -0,0 +1,28 @@
+// Package notify sends desktop notifications through the platform's tools
+package notify
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"strconv"
+)
+
+// ErrUnsupported is returned when no notification tool is available
+var ErrUnsupported = errors.New("no desktop notification tool found")
+
+// Send shows a desktop notification via osascript (macOS) or notify-send
+func Send(title, body string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := "display notification " + strconv.Quote(body) + " with title " + strconv.Quote(title)
+		cmd = exec.Command("osascript", "-e", script)
+	default:
+		if _, err := exec.LookPath("notify-send"); err != nil {
+			return ErrUnsupported
+		}
+		cmd = exec.Command("notify-send", "--app-name=perch", title, body)
+	}
+	return cmd.Run()
+}
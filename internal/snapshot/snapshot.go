@@ -0,0 +1,311 @@
+// Package snapshot keeps a local history of uncommitted file contents, so a
+// version an editor or agent overwrote can be recovered
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kateleext/perch/internal/state"
+)
+
+// MaxFileSize is the largest file that is snapshotted, in bytes
+var MaxFileSize int64 = 1 << 20
+
+// KeepFor is how long versions are kept before Open prunes them
+var KeepFor = 7 * 24 * time.Hour
+
+// MaxTotalSize bounds the bytes of content a store keeps; past it the oldest
+// versions are pruned
+var MaxTotalSize int64 = 256 << 20
+
+// Version is one recorded content of a file
+type Version struct {
+	Path string    `json:"path"` // relative to the store's root
+	Time time.Time `json:"time"`
+	Hash string    `json:"hash"` // sha256 of the content
+	Size int64     `json:"size"`
+}
+
+// Store holds the versions of files under one root. Contents live in
+// objects/ by hash; log.jsonl lists every version in the order recorded.
+type Store struct {
+	root string
+	dir  string
+
+	mu   sync.Mutex
+	last map[string]string    // path → hash of its newest version
+	read map[string]time.Time // path → mtime it had when last read
+	size int64                // bytes of the objects the log refers to
+}
+
+// Open loads the store for root, pruning versions older than KeepFor.
+// Nothing is written until the first version is recorded.
+func Open(root string) (*Store, error) {
+	dir, err := storeDir(root)
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{root: root, dir: dir, last: make(map[string]string), read: make(map[string]time.Time)}
+	if err := s.prune(time.Now().Add(-KeepFor)); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Root is the directory snapshot paths are relative to
+func (s *Store) Root() string {
+	return s.root
+}
+
+// storeDir puts the store in the repo's .git/perch, or in the state dir
+// when root has no .git directory (plain directories, linked worktrees)
+func storeDir(root string) (string, error) {
+	if info, err := os.Stat(filepath.Join(root, ".git")); err == nil && info.IsDir() {
+		return filepath.Join(root, ".git", "perch", "snapshots"), nil
+	}
+	stateDir, err := state.Dir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum([]byte(root))
+	return filepath.Join(stateDir, "snapshots", hex.EncodeToString(sum[:8])), nil
+}
+
+// Record snapshots path (relative to the root) unless its mtime or content
+// is unchanged since it was last read. It reports whether a version was
+// added; missing, oversized, and non-regular files are skipped.
+func (s *Store) Record(path string, modTime time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if at, ok := s.read[path]; ok && at.Equal(modTime) {
+		return false, nil
+	}
+
+	info, err := os.Stat(filepath.Join(s.root, path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil || !info.Mode().IsRegular() || info.Size() > MaxFileSize {
+		return false, err
+	}
+	content, err := os.ReadFile(filepath.Join(s.root, path))
+	if err != nil {
+		return false, err
+	}
+	added, err := s.add(path, content, time.Now())
+	if err != nil {
+		return false, err
+	}
+	// Only a read that was stored is skipped next time; a failed write is
+	// retried on the next load
+	s.read[path] = modTime
+	return added, nil
+}
+
+// add stores content as the newest version of path unless it already is
+func (s *Store) add(path string, content []byte, at time.Time) (bool, error) {
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+	if s.last[path] == hash {
+		return false, nil
+	}
+	written, err := s.writeObject(hash, content)
+	if err != nil {
+		return false, err
+	}
+	line, err := json.Marshal(Version{Path: path, Time: at, Hash: hash, Size: int64(len(content))})
+	if err != nil {
+		return false, err
+	}
+	f, err := os.OpenFile(s.logPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return false, err
+	}
+	s.last[path] = hash
+	if written {
+		s.size += int64(len(content))
+	}
+	if s.size > MaxTotalSize {
+		if err := s.prune(time.Now().Add(-KeepFor)); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// writeObject saves content under its hash, reporting false when the object
+// already exists. Snapshots can hold anything a dirty file did, so they are
+// readable by the owner only.
+func (s *Store) writeObject(hash string, content []byte) (bool, error) {
+	path := s.ObjectPath(hash)
+	if _, err := os.Stat(path); err == nil {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return false, err
+	}
+	// Write then rename so a crash never leaves a truncated object
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o600); err != nil {
+		return false, err
+	}
+	return true, os.Rename(tmp, path)
+}
+
+// ObjectPath is where the content with hash is stored
+func (s *Store) ObjectPath(hash string) string {
+	return filepath.Join(s.dir, "objects", hash[:2], hash[2:])
+}
+
+func (s *Store) logPath() string {
+	return filepath.Join(s.dir, "log.jsonl")
+}
+
+// History lists the versions of path, newest first
+func (s *Store) History(path string) ([]Version, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	versions, err := s.readLog()
+	if err != nil {
+		return nil, err
+	}
+	var history []Version
+	for _, v := range versions {
+		if v.Path == path {
+			history = append(history, v)
+		}
+	}
+	sort.SliceStable(history, func(i, j int) bool { return history[i].Time.After(history[j].Time) })
+	return history, nil
+}
+
+// Content reads a version's content back
+func (s *Store) Content(v Version) ([]byte, error) {
+	return os.ReadFile(s.ObjectPath(v.Hash))
+}
+
+// Restore writes v back over its file, first recording what the file holds
+// now so the restore can itself be undone
+func (s *Store) Restore(v Version) error {
+	content, err := s.Content(v)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	full := filepath.Join(s.root, v.Path)
+	mode := fs.FileMode(0o644)
+	if current, err := os.ReadFile(full); err == nil {
+		if _, err := s.add(v.Path, current, time.Now()); err != nil {
+			return err
+		}
+		if info, err := os.Stat(full); err == nil {
+			mode = info.Mode().Perm()
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(full, content, mode); err != nil {
+		return err
+	}
+	s.last[v.Path] = v.Hash
+	delete(s.read, v.Path)
+	return nil
+}
+
+// readLog reads every recorded version; a missing log is an empty store
+func (s *Store) readLog() ([]Version, error) {
+	data, err := os.ReadFile(s.logPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var versions []Version
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var v Version
+		// A line torn by a crash is skipped rather than failing the store
+		if json.Unmarshal(scanner.Bytes(), &v) == nil && len(v.Hash) > 2 {
+			versions = append(versions, v)
+		}
+	}
+	return versions, scanner.Err()
+}
+
+// prune drops versions recorded before cutoff, then the oldest versions
+// until the objects left fit in MaxTotalSize, removing the objects only the
+// dropped versions used
+func (s *Store) prune(cutoff time.Time) error {
+	versions, err := s.readLog()
+	if err != nil {
+		return err
+	}
+	var kept []Version
+	refs := make(map[string]int)
+	s.size = 0
+	for _, v := range versions {
+		if v.Time.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, v)
+		if refs[v.Hash] == 0 {
+			s.size += v.Size
+		}
+		refs[v.Hash]++
+	}
+	for len(kept) > 0 && s.size > MaxTotalSize {
+		v := kept[0]
+		kept = kept[1:]
+		if refs[v.Hash]--; refs[v.Hash] == 0 {
+			s.size -= v.Size
+		}
+	}
+	s.last = make(map[string]string, len(kept))
+	for _, v := range kept {
+		s.last[v.Path] = v.Hash
+	}
+	if len(kept) == len(versions) {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, v := range kept {
+		line, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(append(line, '\n'))
+	}
+	tmp := s.logPath() + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.logPath()); err != nil {
+		return err
+	}
+	for _, v := range versions {
+		if refs[v.Hash] == 0 {
+			os.Remove(s.ObjectPath(v.Hash))
+		}
+	}
+	return nil
+}
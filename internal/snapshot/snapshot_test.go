@@ -0,0 +1,88 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordAndRestore(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(root, "a.txt")
+	s, err := Open(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		content string
+		mtime   time.Time
+		added   bool
+	}{
+		{"one", time.Unix(1, 0), true},
+		{"one", time.Unix(1, 0), false}, // mtime unchanged: not read again
+		{"one", time.Unix(2, 0), false}, // touched but identical
+		{"two", time.Unix(3, 0), true},
+	}
+	for i, step := range steps {
+		os.WriteFile(file, []byte(step.content), 0o644)
+		added, err := s.Record("a.txt", step.mtime)
+		if err != nil || added != step.added {
+			t.Fatalf("step %d: Record = %v, %v; want %v", i, added, err, step.added)
+		}
+	}
+
+	history, err := s.History("a.txt")
+	if err != nil || len(history) != 2 {
+		t.Fatalf("History = %v, %v; want 2 versions", history, err)
+	}
+	if err := s.Restore(history[1]); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(file); string(got) != "one" {
+		t.Errorf("restored content = %q, want %q", got, "one")
+	}
+
+	reopened, err := Open(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history, _ := reopened.History("a.txt"); len(history) != 2 {
+		t.Errorf("reopened History has %d versions, want 2", len(history))
+	}
+}
+
+func TestPruneToTotalSize(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	defer func(max int64) { MaxTotalSize = max }(MaxTotalSize)
+	MaxTotalSize = 10
+	s, err := Open(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i, content := range []string{"aaaa", "bbbb", "cccc"} {
+		if _, err := s.add("a.txt", []byte(content), now.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	history, err := s.History("a.txt")
+	if err != nil || len(history) != 2 {
+		t.Fatalf("History = %v, %v; want the 2 newest versions", history, err)
+	}
+	if _, err := os.Stat(s.ObjectPath(history[1].Hash)); err != nil {
+		t.Errorf("kept object missing: %v", err)
+	}
+	info, err := os.Stat(s.logPath())
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("log mode = %v, %v; want 0600", info.Mode().Perm(), err)
+	}
+}
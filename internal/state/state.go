@@ -0,0 +1,95 @@
+// Package state persists per-repo perch sessions between launches
+package state
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Session is the UI state restored on the next launch in the same directory
+type Session struct {
+	Dir            string `json:"dir"`
+	SelectedPath   string `json:"selected_path,omitempty"`
+	ViewportOffset int    `json:"viewport_offset,omitempty"`
+	ListHeight     int    `json:"list_height,omitempty"`
+
+	// Filters and view toggles
+	Include        []string `json:"include,omitempty"`
+	Exclude        []string `json:"exclude,omitempty"`
+	DiffOnly       bool     `json:"diff_only,omitempty"`
+	Follow         bool     `json:"follow,omitempty"`
+	ShowWhitespace bool     `json:"show_whitespace,omitempty"`
+	Tail           bool     `json:"tail,omitempty"`
+}
+
+// Dir returns perch's state directory ($XDG_STATE_HOME/perch or ~/.local/state/perch)
+func Dir() (string, error) {
+	if xdg := os.Getenv("XDG_STATE_HOME"); xdg != "" {
+		return filepath.Join(xdg, "perch"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state", "perch"), nil
+}
+
+// Path returns the session file for a target directory
+func Path(dir string) (string, error) {
+	stateDir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum([]byte(dir))
+	return filepath.Join(stateDir, "sessions", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// Load reads the saved session for dir; ok is false when none exists
+func Load(dir string) (s Session, ok bool) {
+	path, err := Path(dir)
+	if err != nil {
+		return Session{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Session{}, false
+	}
+	if err := json.Unmarshal(data, &s); err != nil || s.Dir != dir {
+		return Session{}, false
+	}
+	return s, true
+}
+
+// Save writes the session for s.Dir, replacing any previous one
+func Save(s Session) error {
+	path, err := Path(s.Dir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write then rename so a crash never leaves a truncated file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// FirstRun reports whether perch has never saved a session on this machine
+func FirstRun() bool {
+	stateDir, err := Dir()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(stateDir, "sessions"))
+	return os.IsNotExist(err)
+}
@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kateleext/perch/internal/git"
+	"github.com/mattn/go-runewidth"
+)
+
+// Accessible trades decoration for output a screen reader can follow: no
+// animation, plain ASCII markers and dividers, no background colors, words
+// instead of symbols, and each selection change announced in the footer
+var Accessible bool
+
+// divider renders the rule between the list and the preview
+func (m Model) divider() string {
+	if Accessible {
+		return "\n"
+	}
+	return dividerStyle.Render(strings.Repeat(ruleGlyph, m.width)) + "\n"
+}
+
+// cursorMarker prefixes the selected row of a list
+func cursorMarker() string {
+	if Accessible {
+		return "> "
+	}
+	return "› "
+}
+
+// changeLabel names a file's change in words for accessible output
+func changeLabel(f git.FileStatus) string {
+	if f.Status == "committed" {
+		label := "committed " + f.TimeAgo
+		if f.Author != "" {
+			label += " by " + f.Author
+		}
+		if f.Refs != "" {
+			label += ", at " + f.Refs
+		}
+		return label
+	}
+	return f.ChangeType()
+}
+
+// accessibleFileLine renders one file list row as "> path, modified", with
+// ", unseen" for changes not yet previewed
+func accessibleFileLine(f git.FileStatus, selected, unseen bool, width int) string {
+	marker := "  "
+	if selected {
+		marker = "> "
+	}
+	label := changeLabel(f)
+	if unseen {
+		label += ", unseen"
+	}
+	return runewidth.Truncate(marker+f.Path+", "+label, width, "")
+}
+
+// announceSelection describes the selected file for the footer, so a
+// screen reader reads the new selection as it changes
+func (m *Model) announceSelection() {
+	if !Accessible || m.selected < 0 || m.selected >= len(m.files) {
+		return
+	}
+	f := m.files[m.selected]
+	m.announcement = fmt.Sprintf("file %d of %d: %s, %s", m.selected+1, len(m.files), f.Path, changeLabel(f))
+}
+
+// accessible spells out push/pull counts
+func (s syncState) accessible() string {
+	if !s.ok || (s.ahead == 0 && s.behind == 0) {
+		return ""
+	}
+	return fmt.Sprintf("%d ahead, %d behind", s.ahead, s.behind)
+}
+
+// renderAccessibleLoading is the loading screen without block art
+func (m Model) renderAccessibleLoading() string {
+	return "perch " + Version + ": loading " + m.dir + "\n"
+}
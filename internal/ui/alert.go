@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kateleext/perch/internal/git"
+)
+
+// BellOn and FlashOn hold the change kinds ("new", "modified", "deleted",
+// "renamed") that ring the terminal bell or flash the header
+var (
+	BellOn  = map[string]bool{}
+	FlashOn = map[string]bool{}
+)
+
+// ChangeKinds are the values accepted by BellOn and FlashOn
+var ChangeKinds = []string{"new", "modified", "deleted", "renamed"}
+
+// flashDuration is how long the header stays highlighted after a change
+const flashDuration = 600 * time.Millisecond
+
+// flashEndMsg clears the header flash
+type flashEndMsg struct{}
+
+// ParseKinds parses a comma-separated list of change kinds ("all" for every
+// kind) into a set, returning the first unknown entry if any
+func ParseKinds(list string) (map[string]bool, string) {
+	kinds := map[string]bool{}
+	for _, k := range strings.Split(list, ",") {
+		k = strings.TrimSpace(k)
+		switch {
+		case k == "":
+		case k == "all":
+			for _, kind := range ChangeKinds {
+				kinds[kind] = true
+			}
+		case isChangeKind(k):
+			kinds[k] = true
+		default:
+			return nil, k
+		}
+	}
+	return kinds, ""
+}
+
+func isChangeKind(k string) bool {
+	for _, kind := range ChangeKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// changeKind classifies an uncommitted file for alert settings
+func changeKind(f git.FileStatus) string {
+	switch {
+	case f.GitCode == "??" || f.GitCode == "A " || f.GitCode == "AM":
+		return "new"
+	case strings.Contains(f.GitCode, "D"):
+		return "deleted"
+	case strings.Contains(f.GitCode, "R"):
+		return "renamed"
+	default:
+		return "modified"
+	}
+}
+
+// alertCmd rings the bell and/or starts a header flash for changed files
+func (m *Model) alertCmd(changed []git.FileStatus) tea.Cmd {
+	bell, flash := false, false
+	for _, f := range changed {
+		kind := changeKind(f)
+		bell = bell || BellOn[kind]
+		flash = flash || FlashOn[kind]
+	}
+
+	var cmds []tea.Cmd
+	if bell {
+		cmds = append(cmds, func() tea.Msg {
+			os.Stdout.WriteString("\a")
+			return nil
+		})
+	}
+	if flash && animate() {
+		m.flashing = true
+		cmds = append(cmds, tea.Tick(flashDuration, func(time.Time) tea.Msg {
+			return flashEndMsg{}
+		}))
+	}
+	return tea.Batch(cmds...)
+}
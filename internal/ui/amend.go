@@ -0,0 +1,137 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kateleext/perch/internal/git"
+)
+
+// amendCheckedMsg carries what the amend prompt needs, or why it can't open
+type amendCheckedMsg struct {
+	info   git.AmendInfo
+	scopes []string // recently used scopes, with Conventional on
+	err    error
+}
+
+// amendDoneMsg reports a finished amend
+type amendDoneMsg struct {
+	hash string
+	err  error
+}
+
+// checkAmend makes sure HEAD can be amended before the prompt opens
+func (m Model) checkAmend() tea.Msg {
+	ctx := context.Background()
+	info, err := git.CheckAmend(ctx, m.gitRoot)
+	var scopes []string
+	if err == nil && Conventional {
+		scopes, _ = git.RecentScopes(ctx, m.gitRoot, scopeHistory)
+	}
+	return amendCheckedMsg{info: info, scopes: scopes, err: err}
+}
+
+// startAmend opens the amend prompt on HEAD's subject line
+func (m *Model) startAmend(info git.AmendInfo, scopes []string) {
+	m.amendActive = true
+	m.amendInfo = info
+	m.amendScopes = scopes
+	m.amendError = ""
+	m.amendInput, _, _ = strings.Cut(info.Message, "\n")
+}
+
+// updateAmend handles keys while the amend prompt is open
+func (m Model) updateAmend(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.Type == tea.KeyTab && Conventional {
+		m.amendInput = m.amendCompletion.complete(m.amendInput, m.amendScopes)
+		return m, nil
+	}
+	m.amendCompletion = completion{}
+	m.amendError = ""
+
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, m.quit()
+	case tea.KeyEsc:
+		m.amendActive = false
+	case tea.KeyEnter:
+		subject := strings.TrimSpace(m.amendInput)
+		if Conventional {
+			// The prompt stays open so the subject can be fixed
+			if m.amendError = checkConventional(subject); m.amendError != "" {
+				return m, nil
+			}
+		}
+		m.amendActive = false
+		return m, m.amendCmd(subject)
+	case tea.KeyBackspace:
+		if len(m.amendInput) > 0 {
+			r := []rune(m.amendInput)
+			m.amendInput = string(r[:len(r)-1])
+		}
+	case tea.KeyCtrlU:
+		m.amendInput = ""
+	case tea.KeyRunes, tea.KeySpace:
+		m.amendInput += string(msg.Runes)
+	}
+	return m, nil
+}
+
+// amendCmd amends HEAD with the staged changes and the edited subject; the
+// rest of the message is kept
+func (m *Model) amendCmd(subject string) tea.Cmd {
+	oldSubject, body, _ := strings.Cut(m.amendInfo.Message, "\n")
+	if subject == "" {
+		m.statusMessage = "the commit message can't be empty"
+		return nil
+	}
+	message := ""
+	if subject != oldSubject {
+		message = subject
+		if body != "" {
+			message += "\n" + body
+		}
+	} else if m.amendInfo.Staged == 0 {
+		m.statusMessage = "nothing staged and the message is unchanged"
+		return nil
+	}
+	gitRoot := m.gitRoot
+	return func() tea.Msg {
+		hash, err := git.Amend(context.Background(), gitRoot, message)
+		return amendDoneMsg{hash: hash, err: err}
+	}
+}
+
+// finishAmend reports the amend and refreshes the list
+func (m *Model) finishAmend(msg amendDoneMsg) tea.Cmd {
+	if msg.err != nil {
+		m.statusMessage = "couldn't amend: " + msg.err.Error()
+		return nil
+	}
+	m.statusMessage = "amended as " + msg.hash
+	m.statusUntil = time.Now().Add(toastFor)
+	return m.loadFiles
+}
+
+// renderAmendPrompt renders the prompt in place of the footer hint
+func (m Model) renderAmendPrompt() string {
+	staged := "nothing staged"
+	switch n := m.amendInfo.Staged; {
+	case n == 1:
+		staged = "1 staged file"
+	case n > 1:
+		staged = fmt.Sprintf("%d staged files", n)
+	}
+	prompt := dimStyle.Render("amend: ") + m.amendInput + keyStyle.Render("▏")
+	if !Conventional {
+		return prompt + dimStyle.Render("  "+staged+" · enter amend · esc cancel")
+	}
+	if m.amendError != "" {
+		return prompt + "  " + lineDelGutter.Render(m.amendError)
+	}
+	return prompt + "  " + renderHeaderLength(strings.TrimSpace(m.amendInput)) +
+		dimStyle.Render(" · "+staged+" · tab complete · enter amend · esc cancel")
+}
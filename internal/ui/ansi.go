@@ -0,0 +1,79 @@
+package ui
+
+import "strings"
+
+// hasEmbeddedANSI reports whether file content carries its own escape codes
+// (colored logs, captured terminal output), which chroma would mangle
+func hasEmbeddedANSI(content string) bool {
+	return strings.Contains(content, "\x1b[")
+}
+
+// sanitizeANSI keeps a line's SGR color sequences and drops everything else
+// that could move the cursor or change terminal state: other CSI sequences,
+// OSC strings, bare escapes, and control characters other than tab.
+// Colors are reset at the end so they don't bleed into the next line.
+func sanitizeANSI(line string) string {
+	var b strings.Builder
+	colored := false
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == 0x1b && i+1 < len(line) && line[i+1] == '[':
+			j := i + 2
+			for j < len(line) && (line[j] < 0x40 || line[j] > 0x7e) {
+				j++
+			}
+			if j < len(line) && line[j] == 'm' && isSGRParams(line[i+2:j]) {
+				b.WriteString(line[i : j+1])
+				colored = true
+			}
+			i = j + 1
+		case c == 0x1b && i+1 < len(line) && line[i+1] == ']':
+			// OSC runs to BEL or ST (ESC \)
+			j := i + 2
+			for j < len(line) && line[j] != 0x07 && !(line[j] == 0x1b && j+1 < len(line) && line[j+1] == '\\') {
+				j++
+			}
+			if j < len(line) && line[j] == 0x1b {
+				j++
+			}
+			i = j + 1
+		case c == 0x1b:
+			i += 2
+		case c < 0x20 && c != '\t', c == 0x7f:
+			i++
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	if colored {
+		b.WriteString(ansiReset)
+	}
+	return b.String()
+}
+
+// isSGRParams reports whether a CSI parameter string is plain SGR (digits
+// and separators only)
+func isSGRParams(params string) bool {
+	for i := 0; i < len(params); i++ {
+		if c := params[i]; (c < '0' || c > '9') && c != ';' && c != ':' {
+			return false
+		}
+	}
+	return true
+}
+
+// plainANSI is a line with every escape sequence removed, for raw text
+func plainANSI(line string) string {
+	return stripANSIColors(sanitizeANSI(line))
+}
+
+// passthroughANSI renders lines that carry their own colors as-is
+func passthroughANSI(lines []string) []string {
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = sanitizeANSI(line)
+	}
+	return out
+}
@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kateleext/perch/internal/git"
+)
+
+// ChurnDays is the default window of the churn dashboard, in days
+var ChurnDays = 30
+
+// churnLoadedMsg carries files ranked by change frequency
+type churnLoadedMsg struct {
+	days    int
+	files   []git.FileActivity
+	commits int
+	err     error
+}
+
+// loadChurn aggregates the log over the current window in the background
+func (m Model) loadChurn() tea.Cmd {
+	dir, days := m.dir, m.churnDays
+	return func() tea.Msg {
+		files, commits, err := git.GetChurn(context.Background(), dir, days)
+		return churnLoadedMsg{days: days, files: files, commits: commits, err: err}
+	}
+}
+
+// enterChurnMode swaps the file list for the churn dashboard
+func (m *Model) enterChurnMode() tea.Cmd {
+	m.mode = modeChurn
+	if m.churnDays <= 0 {
+		m.churnDays = ChurnDays
+	}
+	m.churn = nil
+	m.preview = PreviewContent{Valid: true, Message: "counting changes…"}
+	m.viewport.SetContent(m.renderPreviewContent())
+	return m.loadChurn()
+}
+
+// updateChurn handles keys while the churn dashboard is open
+func (m Model) updateChurn(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, m.quit()
+	case "esc", "H":
+		m.mode = modeFiles
+		m.lastSelectedFile = -1
+		m.updatePreview()
+	case "+", "=":
+		// Widen the window
+		m.churnDays *= 2
+		return m, m.loadChurn()
+	case "-", "_":
+		if m.churnDays > 1 {
+			m.churnDays /= 2
+			return m, m.loadChurn()
+		}
+	case "j", "down":
+		m.viewport.LineDown(1)
+	case "k", "up":
+		m.viewport.LineUp(1)
+	case "g":
+		m.viewport.GotoTop()
+	case "G":
+		m.viewport.GotoBottom()
+	case "ctrl+d":
+		m.viewport.HalfViewDown()
+	case "ctrl+u":
+		m.viewport.HalfViewUp()
+	}
+	return m, nil
+}
+
+// buildChurnPreview renders one row per file with a bar for its commit count
+func (m Model) buildChurnPreview(files []git.FileActivity) PreviewContent {
+	if len(files) == 0 {
+		return PreviewContent{Valid: true, Message: fmt.Sprintf("no commits in the last %d days", m.churnDays)}
+	}
+
+	maxCommits := files[0].Commits
+	barWidth := m.width / 4
+	var raw, highlighted []string
+	for _, f := range files {
+		count := fmt.Sprintf("%3d", f.Commits)
+		raw = append(raw, count+"  "+f.Path)
+		highlighted = append(highlighted, padLine(
+			keyStyle.Render(count)+"  "+f.Path+"  "+dimStyle.Render(fmt.Sprintf("+%d −%d", f.Added, f.Deleted)),
+			cyanStyle.Render(renderBar(f.Commits, maxCommits, barWidth))+"  ", m.width-gutterWidth))
+	}
+	return PreviewContent{Valid: true, RawLines: raw, HighlightedLines: highlighted}
+}
+
+// renderChurnList renders the window and totals in place of the file list
+func (m Model) renderChurnList() string {
+	var lines []string
+	header := dimStyle.Render(fmt.Sprintf("CHURN · LAST %d DAYS", m.churnDays))
+	hint := keyStyle.Render("+ -") + dimStyle.Render(" window  ") +
+		keyStyle.Render("esc") + dimStyle.Render(" back")
+	lines = append(lines, padLine(header, hint, m.width))
+	lines = append(lines, fmt.Sprintf("  %d files changed across %d commits", len(m.churn), m.churnCommits))
+
+	for len(lines) < m.listRows() {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
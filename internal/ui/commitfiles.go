@@ -0,0 +1,196 @@
+package ui
+
+import (
+	"context"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kateleext/perch/internal/git"
+)
+
+// commitDrill is a commit opened up into the files it changed
+type commitDrill struct {
+	commit   git.Commit
+	dir      string // where git runs; file paths are relative to it
+	focus    string // file to select once the list loads
+	files    []git.CommitFile
+	selected int
+	scroll   int
+	from     viewMode // mode esc returns to
+}
+
+// commitFilesLoadedMsg carries a commit's changed files
+type commitFilesLoadedMsg struct {
+	hash   string // as requested; the loaded commit may spell it longer
+	commit git.Commit
+	files  []git.CommitFile
+	err    error
+}
+
+// commitFilePreviewMsg carries one file's diff in the drilled commit
+type commitFilePreviewMsg struct {
+	hash    string
+	path    string
+	preview PreviewContent
+}
+
+// enterCommitFiles swaps the list for the files commit changed
+func (m *Model) enterCommitFiles(commit git.Commit, dir, focus string) tea.Cmd {
+	m.drill = commitDrill{commit: commit, dir: dir, focus: focus, from: m.mode}
+	m.mode = modeCommitFiles
+	m.preview = PreviewContent{Valid: true, Message: "loading " + commit.Hash + "…"}
+	m.viewport.SetContent(m.renderPreviewContent())
+	return func() tea.Msg {
+		ctx := context.Background()
+		hash := commit.Hash
+		// Entries from the file list only know the short hash and age
+		if commit.Subject == "" {
+			if full, err := git.GetCommit(ctx, dir, commit.Hash); err == nil {
+				commit = full
+			}
+		}
+		files, err := git.GetCommitFiles(ctx, dir, hash)
+		return commitFilesLoadedMsg{hash: hash, commit: commit, files: files, err: err}
+	}
+}
+
+// drillSelectedFile opens the commit of the selected committed file
+func (m *Model) drillSelectedFile() tea.Cmd {
+	if m.selected < 0 || m.selected >= len(m.files) {
+		return nil
+	}
+	f := m.files[m.selected]
+	if f.Status != "committed" || f.Commit == "" {
+		return nil
+	}
+	commit := git.Commit{Hash: f.Commit, TimeAgo: f.TimeAgo, Author: f.Author, Refs: f.Refs}
+	if f.GitRoot != "" && f.GitRoot != m.gitRoot {
+		// Nested repos' commits are read from their own root
+		return m.enterCommitFiles(commit, f.GitRoot, f.FullPath)
+	}
+	return m.enterCommitFiles(commit, m.dir, f.Path)
+}
+
+// loadCommitFilePreview loads the selected file's diff in the background
+func (m Model) loadCommitFilePreview() tea.Cmd {
+	d := m.drill
+	if d.selected < 0 || d.selected >= len(d.files) {
+		return nil
+	}
+	hash, path := d.commit.Hash, d.files[d.selected].Path
+	return func() tea.Msg {
+		diff, err := git.GetCommitFileDiff(context.Background(), d.dir, hash, path)
+		if err != nil {
+			return commitFilePreviewMsg{hash: hash, path: path, preview: PreviewContent{Valid: true, Message: "couldn't read " + path}}
+		}
+		return commitFilePreviewMsg{hash: hash, path: path, preview: buildDiffPreview(diff)}
+	}
+}
+
+// exitCommitFiles goes back to the commit log or file list
+func (m *Model) exitCommitFiles() tea.Cmd {
+	if m.drill.from == modeLog {
+		m.mode = modeLog
+		return m.loadCommitPreview()
+	}
+	m.mode = modeFiles
+	m.lastSelectedFile = -1
+	m.updatePreview()
+	return nil
+}
+
+// updateCommitFiles handles keys while a commit's files are listed
+func (m Model) updateCommitFiles(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	d := &m.drill
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, m.quit()
+	case "esc":
+		m.statusMessage = ""
+		return m, m.exitCommitFiles()
+	case "up":
+		if d.selected > 0 {
+			d.selected--
+			if d.selected < d.scroll {
+				d.scroll = d.selected
+			}
+			return m, m.loadCommitFilePreview()
+		}
+	case "down":
+		if d.selected < len(d.files)-1 {
+			d.selected++
+			if visible := m.listRows() - 1; d.selected >= d.scroll+visible {
+				d.scroll = d.selected - visible + 1
+			}
+			return m, m.loadCommitFilePreview()
+		}
+	case "j":
+		m.viewport.LineDown(1)
+	case "k":
+		m.viewport.LineUp(1)
+	case "g":
+		m.viewport.GotoTop()
+	case "G":
+		m.viewport.GotoBottom()
+	case "ctrl+d":
+		m.viewport.HalfViewDown()
+	case "ctrl+u":
+		m.viewport.HalfViewUp()
+	}
+	return m, nil
+}
+
+// commitFileIcon marks how a commit changed a file, like the file list does
+func commitFileIcon(status string) string {
+	switch status {
+	case "A":
+		return "✦ "
+	case "D":
+		return "✗ "
+	default:
+		return "- "
+	}
+}
+
+// renderCommitFileList renders the drilled commit's files in place of the list
+func (m Model) renderCommitFileList() string {
+	d := m.drill
+	var lines []string
+	header := dimStyle.Render(d.commit.Hash + "  " + d.commit.Subject)
+	hint := keyStyle.Render("esc") + dimStyle.Render(" back")
+	lines = append(lines, padLine(header, hint, m.width))
+
+	if len(d.files) == 0 {
+		lines = append(lines, "  "+dimStyle.Render("no files"))
+	}
+
+	end := d.scroll + m.listRows() - 1
+	if end > len(d.files) {
+		end = len(d.files)
+	}
+	for i := d.scroll; i < end; i++ {
+		f := d.files[i]
+		stats := renderDiffStats(git.DiffStats{Added: f.Added, Deleted: f.Deleted})
+		if i == d.selected {
+			lines = append(lines, padLine(selectedStyle.Render(cursorMarker()+commitFileIcon(f.Status)+f.Path), stats, m.width))
+		} else {
+			lines = append(lines, padLine("  "+dimStyle.Render(commitFileIcon(f.Status))+f.Path, stats, m.width))
+		}
+	}
+
+	for len(lines) < m.listRows() {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// renderCommitFileHeader renders the preview header for the selected file
+func (m Model) renderCommitFileHeader() string {
+	d := m.drill
+	if d.selected < 0 || d.selected >= len(d.files) {
+		return "\n"
+	}
+	header := "  " + cyanStyle.Render(d.files[d.selected].Path) + "  " + dimStyle.Render("in "+d.commit.Hash+" · "+d.commit.TimeAgo)
+	hint := keyStyle.Render("j k") + dimStyle.Render(" scroll  ")
+	return padLine(header, hint, m.width) + "\n"
+}
@@ -0,0 +1,164 @@
+package ui
+
+import (
+	"context"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kateleext/perch/internal/git"
+)
+
+// logLimit is how many commits the commit log lists
+const logLimit = 200
+
+// commitsLoadedMsg carries the branch history for the commit log
+type commitsLoadedMsg struct {
+	commits []git.Commit
+	err     error
+}
+
+// commitPreviewMsg carries the rendered diff of one commit
+type commitPreviewMsg struct {
+	hash    string
+	preview PreviewContent
+}
+
+func (m Model) loadCommits() tea.Msg {
+	commits, err := git.GetLog(context.Background(), m.dir, logLimit)
+	return commitsLoadedMsg{commits: commits, err: err}
+}
+
+// loadCommitPreview loads the selected commit's patch in the background
+func (m Model) loadCommitPreview() tea.Cmd {
+	if m.commitSelected < 0 || m.commitSelected >= len(m.commits) {
+		return nil
+	}
+	hash := m.commits[m.commitSelected].Hash
+	dir := m.dir
+	return func() tea.Msg {
+		diff, err := git.GetCommitDiff(context.Background(), dir, hash)
+		if err != nil {
+			return commitPreviewMsg{hash: hash, preview: PreviewContent{Valid: true, Message: "couldn't read " + hash}}
+		}
+		return commitPreviewMsg{hash: hash, preview: buildDiffPreview(diff)}
+	}
+}
+
+// enterLogMode swaps the file list for the commit log
+func (m *Model) enterLogMode() tea.Cmd {
+	m.mode = modeLog
+	m.commitSelected = 0
+	m.commitScroll = 0
+	m.preview = PreviewContent{Valid: true, Message: "loading commits…"}
+	m.viewport.SetContent(m.renderPreviewContent())
+	return m.loadCommits
+}
+
+// exitLogMode returns to the file list and restores its preview
+func (m *Model) exitLogMode() {
+	m.mode = modeFiles
+	m.lastSelectedFile = -1
+	m.updatePreview()
+}
+
+// updateLog handles keys while the commit log is open
+func (m Model) updateLog(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, m.quit()
+	case "esc", "C":
+		m.statusMessage = ""
+		m.exitLogMode()
+		return m, nil
+	case "enter":
+		if m.commitSelected < len(m.commits) {
+			return m, m.enterCommitFiles(m.commits[m.commitSelected], m.dir, "")
+		}
+	case "up":
+		if m.commitSelected > 0 {
+			m.commitSelected--
+			if m.commitSelected < m.commitScroll {
+				m.commitScroll = m.commitSelected
+			}
+			return m, m.loadCommitPreview()
+		}
+	case "down":
+		if m.commitSelected < len(m.commits)-1 {
+			m.commitSelected++
+			if visible := m.listRows() - 1; m.commitSelected >= m.commitScroll+visible {
+				m.commitScroll = m.commitSelected - visible + 1
+			}
+			return m, m.loadCommitPreview()
+		}
+	case "j":
+		m.viewport.LineDown(1)
+	case "k":
+		m.viewport.LineUp(1)
+	case "g":
+		m.viewport.GotoTop()
+	case "G":
+		m.viewport.GotoBottom()
+	case "ctrl+d":
+		m.viewport.HalfViewDown()
+	case "ctrl+u":
+		m.viewport.HalfViewUp()
+	}
+	return m, nil
+}
+
+// renderLogList renders the commit log in place of the file list
+func (m Model) renderLogList() string {
+	var lines []string
+	header := dimStyle.Render("COMMITS")
+	hint := keyStyle.Render("enter") + dimStyle.Render(" files  ") + keyStyle.Render("esc") + dimStyle.Render(" back")
+	lines = append(lines, padLine(header, hint, m.width))
+
+	if len(m.commits) == 0 {
+		lines = append(lines, "  "+dimStyle.Render("no commits"))
+	}
+
+	end := m.commitScroll + m.listRows() - 1
+	if end > len(m.commits) {
+		end = len(m.commits)
+	}
+	for i := m.commitScroll; i < end; i++ {
+		c := m.commits[i]
+		right := dimStyle.Render(authorInitials(c.Author) + " " + c.TimeAgo)
+		if c.Refs != "" {
+			right = cyanStyle.Render(c.Refs) + " " + right
+		}
+		maxLen := m.width - 8 - len(c.Hash) - lipgloss.Width(right)
+		if maxLen < 10 {
+			maxLen = 10
+		}
+		subject := c.Subject
+		if len([]rune(subject)) > maxLen {
+			subject = string([]rune(subject)[:maxLen-3]) + "..."
+		}
+		if i == m.commitSelected {
+			lines = append(lines, padLine(selectedStyle.Render(cursorMarker()+c.Hash+"  "+subject), right, m.width))
+		} else {
+			lines = append(lines, padLine("  "+dimStyle.Render(c.Hash)+"  "+subject, right, m.width))
+		}
+	}
+
+	for len(lines) < m.listRows() {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// renderLogHeader renders the preview header for the selected commit
+func (m Model) renderLogHeader() string {
+	if m.commitSelected < 0 || m.commitSelected >= len(m.commits) {
+		return "\n"
+	}
+	c := m.commits[m.commitSelected]
+	header := "  " + cyanStyle.Render(c.Hash) + "  " + dimStyle.Render(c.TimeAgo+" · "+c.Author)
+	if stats := renderDiffStats(m.preview.DiffStats); stats != "" {
+		header += "  " + stats
+	}
+	hint := keyStyle.Render("j k") + dimStyle.Render(" scroll  ")
+	return padLine(header, hint, m.width) + "\n"
+}
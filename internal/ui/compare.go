@@ -0,0 +1,207 @@
+package ui
+
+import (
+	"context"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kateleext/perch/internal/git"
+)
+
+// comparePicker chooses two points in a file's history to diff. The first
+// entry is the working tree (an empty Hash); the rest are the commits that
+// touched the file, newest first.
+type comparePicker struct {
+	dir      string // git root the file's history is read from
+	path     string // relative to dir
+	name     string // display path
+	commits  []git.Commit
+	base     int // entry marked with enter
+	selected int
+	scroll   int
+}
+
+// compareLoadedMsg carries the history of the file being compared
+type compareLoadedMsg struct {
+	path    string
+	commits []git.Commit
+	err     error
+}
+
+// comparePreviewMsg carries the diff between two picked entries
+type comparePreviewMsg struct {
+	from, to string
+	preview  PreviewContent
+}
+
+// enterCompareMode opens the picker on the selected file's history
+func (m *Model) enterCompareMode() tea.Cmd {
+	if m.selected < 0 || m.selected >= len(m.files) || m.plainDir {
+		return nil
+	}
+	f := m.files[m.selected]
+	dir := m.gitRoot
+	if f.GitRoot != "" {
+		dir = f.GitRoot
+	}
+	m.compare = comparePicker{dir: dir, path: f.FullPath, name: f.Path, selected: 1}
+	m.mode = modeCompare
+	m.preview = PreviewContent{Valid: true, Message: "loading history…"}
+	m.viewport.SetContent(m.renderPreviewContent())
+	return func() tea.Msg {
+		commits, err := git.GetFileLog(context.Background(), dir, f.FullPath, logLimit)
+		return compareLoadedMsg{path: f.FullPath, commits: commits, err: err}
+	}
+}
+
+// compareRange is the picked pair, older first, as git revisions ("" is
+// the working tree)
+func (c comparePicker) compareRange() (from, to string) {
+	older, newer := c.base, c.selected
+	if older < newer {
+		older, newer = newer, older
+	}
+	return c.commits[older].Hash, c.commits[newer].Hash
+}
+
+// loadComparePreview loads the diff between the base and the selection
+func (m *Model) loadComparePreview() tea.Cmd {
+	c := m.compare
+	if c.selected >= len(c.commits) {
+		return nil
+	}
+	if c.selected == c.base {
+		m.preview = PreviewContent{Valid: true, Message: "pick a second point to compare against"}
+		m.viewport.SetContent(m.renderPreviewContent())
+		return nil
+	}
+	from, to := c.compareRange()
+	return func() tea.Msg {
+		diff, err := git.GetFileDiffBetween(context.Background(), c.dir, from, to, c.path)
+		if err != nil {
+			return comparePreviewMsg{from: from, to: to, preview: PreviewContent{Valid: true, Message: "couldn't compare " + c.name}}
+		}
+		return comparePreviewMsg{from: from, to: to, preview: buildDiffPreview(diff)}
+	}
+}
+
+// updateCompare handles keys while the compare picker is open
+func (m Model) updateCompare(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c := &m.compare
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, m.quit()
+	case "esc", "b":
+		m.statusMessage = ""
+		m.exitLogMode()
+		return m, nil
+	case "enter":
+		if c.selected < len(c.commits) && c.selected != c.base {
+			c.base = c.selected
+			return m, m.loadComparePreview()
+		}
+	case "up":
+		if c.selected > 0 {
+			c.selected--
+			if c.selected < c.scroll {
+				c.scroll = c.selected
+			}
+			return m, m.loadComparePreview()
+		}
+	case "down":
+		if c.selected < len(c.commits)-1 {
+			c.selected++
+			if visible := m.listRows() - 1; c.selected >= c.scroll+visible {
+				c.scroll = c.selected - visible + 1
+			}
+			return m, m.loadComparePreview()
+		}
+	case "j":
+		m.viewport.LineDown(1)
+	case "k":
+		m.viewport.LineUp(1)
+	case "g":
+		m.viewport.GotoTop()
+	case "G":
+		m.viewport.GotoBottom()
+	case "ctrl+d":
+		m.viewport.HalfViewDown()
+	case "ctrl+u":
+		m.viewport.HalfViewUp()
+	}
+	return m, nil
+}
+
+// compareLabel names an entry in the compare header
+func compareLabel(hash string) string {
+	if hash == "" {
+		return "working tree"
+	}
+	return hash
+}
+
+// renderCompareList renders the file's history in place of the file list
+func (m Model) renderCompareList() string {
+	c := m.compare
+	var lines []string
+	header := dimStyle.Render("COMPARE " + c.name)
+	hint := keyStyle.Render("enter") + dimStyle.Render(" base  ") + keyStyle.Render("esc") + dimStyle.Render(" back")
+	lines = append(lines, padLine(header, hint, m.width))
+
+	end := c.scroll + m.listRows() - 1
+	if end > len(c.commits) {
+		end = len(c.commits)
+	}
+	for i := c.scroll; i < end; i++ {
+		commit := c.commits[i]
+		mark := "  "
+		if i == c.base {
+			mark = "◆ "
+			if Accessible {
+				mark = "base "
+			}
+		}
+		var label, right string
+		if commit.Hash == "" {
+			label = "working tree"
+		} else {
+			label = commit.Hash + "  " + commit.Subject
+			right = dimStyle.Render(authorInitials(commit.Author) + " " + commit.TimeAgo)
+			if commit.Refs != "" {
+				right = cyanStyle.Render(commit.Refs) + " " + right
+			}
+		}
+		if maxLen := m.width - 8 - lipgloss.Width(right); len([]rune(label)) > maxLen && maxLen > 10 {
+			label = string([]rune(label)[:maxLen-3]) + "..."
+		}
+		if i == c.selected {
+			lines = append(lines, padLine(selectedStyle.Render(cursorMarker()+mark+label), right, m.width))
+		} else {
+			lines = append(lines, padLine("  "+cyanStyle.Render(mark)+label, right, m.width))
+		}
+	}
+	if len(c.commits) == 1 {
+		lines = append(lines, "  "+dimStyle.Render("no commits touch this file"))
+	}
+
+	for len(lines) < m.listRows() {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// renderCompareHeader renders the preview header for the picked pair
+func (m Model) renderCompareHeader() string {
+	c := m.compare
+	if c.selected >= len(c.commits) || c.selected == c.base {
+		return "\n"
+	}
+	from, to := c.compareRange()
+	header := "  " + cyanStyle.Render(compareLabel(from)+" → "+compareLabel(to))
+	if stats := renderDiffStats(m.preview.DiffStats); stats != "" {
+		header += "  " + stats
+	}
+	hint := keyStyle.Render("j k") + dimStyle.Render(" scroll  ")
+	return padLine(header, hint, m.width) + "\n"
+}
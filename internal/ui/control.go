@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"errors"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kateleext/perch/internal/control"
+)
+
+// ControlMsg is a command from the control socket; the model answers on Reply
+type ControlMsg struct {
+	Command string
+	Arg     string
+	Reply   chan control.Response
+}
+
+// handleControl runs a control command against the model
+func (m Model) handleControl(msg ControlMsg) (Model, control.Response, tea.Cmd) {
+	switch msg.Command {
+	case "refresh":
+		return m, control.Response{"ok": true}, m.loadFiles
+	case "quit":
+		return m, control.Response{"ok": true}, m.quit()
+	case "status":
+		return m, m.statusResponse(), nil
+	case "select":
+		path := msg.Arg
+		if filepath.IsAbs(path) {
+			if rel, err := filepath.Rel(m.dir, path); err == nil {
+				path = rel
+			}
+		}
+		path = filepath.Clean(path)
+		for i, f := range m.files {
+			if f.Path != path {
+				continue
+			}
+			m.resetToFileList()
+			m.selected = i
+			m.ensureSelectedVisible()
+			m.previewPending = i
+			return m, control.Response{"ok": true, "path": f.Path}, debouncePreviewCmd(i)
+		}
+		return m, control.Error(errors.New("not in the file list: " + msg.Arg)), nil
+	default:
+		return m, control.Error(errors.New("unknown command " + msg.Command + " (want select, refresh, status, quit)")), nil
+	}
+}
+
+// resetToFileList leaves the current mode through its own exit and closes
+// any open prompt or overlay, so keys after a remote select go to the list
+// the user sees
+func (m *Model) resetToFileList() {
+	m.gotoActive, m.gotoInput = false, ""
+	m.amendActive, m.amendInput, m.amendError = false, "", ""
+	m.exportActive, m.exportInput = false, ""
+	m.visualActive = false
+	m.outlineActive = false
+	m.linksActive = false
+	m.showTips = false
+
+	switch m.mode {
+	case modeFiles:
+	case modeStash:
+		m.exitStashMode()
+	case modeTimeline:
+		m.exitTimelineMode()
+	default:
+		m.exitLogMode()
+	}
+}
+
+// statusResponse describes the current file list and selection
+func (m Model) statusResponse() control.Response {
+	files := make([]map[string]string, len(m.files))
+	for i, f := range m.files {
+		files[i] = map[string]string{"path": f.Path, "status": f.Status, "change": f.ChangeType()}
+	}
+	resp := control.Response{"ok": true, "dir": m.dir, "files": files}
+	if m.selected >= 0 && m.selected < len(m.files) {
+		resp["selected"] = m.files[m.selected].Path
+	}
+	return resp
+}
@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kateleext/perch/internal/git"
+)
+
+// Conventional guides commit messages into `type(scope): subject` form:
+// tab completes types and recently used scopes, and a message that isn't
+// conventional or is over HeaderLimit is refused before committing
+var Conventional = false
+
+// HeaderLimit is the longest commit header Conventional allows
+var HeaderLimit = 72
+
+// scopeHistory is how many commits are searched for recent scopes
+const scopeHistory = 200
+
+// completion cycles tab through the matches for what was typed before the
+// first tab
+type completion struct {
+	base    string // input before the completed word
+	matches []string
+	next    int
+	suffix  string // closes the completed word, e.g. ")" after a scope
+}
+
+// complete fills in the type or scope being typed, or the next match when
+// tab is pressed again
+func (c *completion) complete(input string, scopes []string) string {
+	if len(c.matches) == 0 {
+		var word string
+		var candidates []string
+		if open := strings.IndexByte(input, '('); open >= 0 {
+			if strings.ContainsAny(input[open:], "):") {
+				return input
+			}
+			c.base, word, c.suffix = input[:open+1], input[open+1:], ")"
+			candidates = scopes
+		} else {
+			if strings.ContainsAny(input, ": !") {
+				return input
+			}
+			c.base, word, c.suffix = "", input, ""
+			candidates = git.ConventionalTypes
+		}
+		for _, candidate := range candidates {
+			if strings.HasPrefix(candidate, word) {
+				c.matches = append(c.matches, candidate)
+			}
+		}
+		if len(c.matches) == 0 {
+			return input
+		}
+	}
+	match := c.matches[c.next%len(c.matches)]
+	c.next++
+	return c.base + match + c.suffix
+}
+
+// checkConventional reports why header can't be committed, or "" when it can
+func checkConventional(header string) string {
+	if n := len([]rune(header)); n > HeaderLimit {
+		return fmt.Sprintf("header is %d characters; keep it to %d", n, HeaderLimit)
+	}
+	c, ok := git.ParseConventional(header)
+	if !ok {
+		return "expected type(scope): subject"
+	}
+	for _, t := range git.ConventionalTypes {
+		if c.Type == t {
+			return ""
+		}
+	}
+	return fmt.Sprintf("unknown type %q; tab completes one", c.Type)
+}
+
+// renderHeaderLength renders the header's length against HeaderLimit
+func renderHeaderLength(header string) string {
+	n := len([]rune(header))
+	text := fmt.Sprintf("%d/%d", n, HeaderLimit)
+	if n > HeaderLimit {
+		return lineDelGutter.Render(text)
+	}
+	return dimStyle.Render(text)
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/kateleext/perch/internal/git"
+)
+
+// dateGroup buckets a modification time relative to now by calendar day
+func dateGroup(t, now time.Time) string {
+	y, mo, d := now.Date()
+	today := time.Date(y, mo, d, 0, 0, 0, 0, now.Location())
+	switch {
+	case !t.Before(today):
+		return "today"
+	case !t.Before(today.AddDate(0, 0, -1)):
+		return "yesterday"
+	case !t.Before(today.AddDate(0, 0, -6)):
+		return "this week"
+	case !t.Before(today.AddDate(0, 0, -13)):
+		return "last week"
+	default:
+		return "older"
+	}
+}
+
+// fileGroups returns each file's date group, or nil when every file falls
+// in the same one and separators would say nothing
+func fileGroups(files []git.FileStatus, now time.Time) []string {
+	groups := make([]string, len(files))
+	mixed := false
+	for i, f := range files {
+		groups[i] = dateGroup(f.ModTime, now)
+		if groups[i] != groups[0] {
+			mixed = true
+		}
+	}
+	if !mixed {
+		return nil
+	}
+	return groups
+}
+
+// listRow is one row of the file list: a file, or a date separator when
+// file is -1
+type listRow struct {
+	file  int
+	label string
+}
+
+// listRows fills up to slots rows with files from start on, putting a
+// separator above the first file of each date group
+func listRows(start, slots int, groups []string, count int) ([]listRow, int) {
+	if slots < 1 {
+		slots = 1
+	}
+	var rows []listRow
+	i := start
+	for ; i < count && len(rows) < slots; i++ {
+		if groups != nil && (i == 0 || groups[i] != groups[i-1]) {
+			// A separator needs its file beneath it, except on a one-row list
+			if len(rows)+2 > slots && len(rows) > 0 {
+				break
+			}
+			if len(rows)+2 <= slots {
+				rows = append(rows, listRow{file: -1, label: groups[i]})
+			}
+		}
+		rows = append(rows, listRow{file: i})
+	}
+	return rows, i
+}
+
+// renderDateSeparator draws a subtle label above a date group
+func renderDateSeparator(label string) string {
+	if Accessible {
+		return dimStyle.Render("  " + label)
+	}
+	return dimStyle.Render("  ── " + label)
+}
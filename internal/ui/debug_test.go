@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	
@@ -10,7 +11,7 @@ import (
 func TestFileStatus(t *testing.T) {
 	dir := "/Users/kate/Projects/takuma-os/labs/fun/perch"
 	
-	files, _ := git.GetStatus(dir)
+	files, _ := git.GetStatus(context.Background(), dir)
 	for _, f := range files {
 		if f.Path == "README.md" {
 			fmt.Printf("README.md found:\n")
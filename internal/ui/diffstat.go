@@ -0,0 +1,178 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kateleext/perch/internal/git"
+)
+
+// diffstatLoadedMsg carries per-file line counts for the diffstat view
+type diffstatLoadedMsg struct {
+	files []git.FileActivity
+	err   error
+}
+
+func (m Model) loadDiffstat() tea.Msg {
+	files, err := git.GetWorkingTreeFileStats(context.Background(), m.dir)
+	return diffstatLoadedMsg{files: files, err: err}
+}
+
+// enterDiffstatMode swaps the file list for the working tree's diffstat
+func (m *Model) enterDiffstatMode() tea.Cmd {
+	m.mode = modeDiffstat
+	m.diffstat = nil
+	m.diffstatSelected = 0
+	m.preview = PreviewContent{Valid: true, Message: "counting changes…"}
+	m.viewport.SetContent(m.renderPreviewContent())
+	return m.loadDiffstat
+}
+
+// updateDiffstat handles keys while the diffstat view is open; the cursor
+// moves through the rows in the preview
+func (m Model) updateDiffstat(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, m.quit()
+	case "esc", "D":
+		m.exitLogMode()
+		return m, nil
+	case "enter":
+		if m.diffstatSelected < len(m.diffstat) {
+			path := m.diffstat[m.diffstatSelected].Path
+			m.mode = modeFiles
+			m.lastSelectedFile = -1
+			return m, m.selectPath(path)
+		}
+	case "j", "down":
+		m.moveDiffstat(1)
+	case "k", "up":
+		m.moveDiffstat(-1)
+	case "g":
+		m.moveDiffstat(-len(m.diffstat))
+	case "G":
+		m.moveDiffstat(len(m.diffstat))
+	case "ctrl+d":
+		m.moveDiffstat(m.viewport.Height / 2)
+	case "ctrl+u":
+		m.moveDiffstat(-m.viewport.Height / 2)
+	}
+	return m, nil
+}
+
+// moveDiffstat moves the cursor by delta rows and keeps it in view
+func (m *Model) moveDiffstat(delta int) {
+	if len(m.diffstat) == 0 {
+		return
+	}
+	m.diffstatSelected = max(0, min(len(m.diffstat)-1, m.diffstatSelected+delta))
+	m.preview = m.buildDiffstatPreview()
+	m.viewport.SetContent(m.renderPreviewContent())
+	if m.diffstatSelected < m.viewport.YOffset {
+		m.viewport.SetYOffset(m.diffstatSelected)
+	} else if m.diffstatSelected >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(m.diffstatSelected - m.viewport.Height + 1)
+	}
+}
+
+// renderStatBar draws a git --stat bar: pluses then minuses, scaled so the
+// biggest change fills width
+func renderStatBar(added, deleted, max, width int) string {
+	total := added + deleted
+	if max <= 0 || total == 0 {
+		return ""
+	}
+	n := total
+	if max > width {
+		n = total * width / max
+		if n == 0 {
+			n = 1
+		}
+	}
+	plus := n * added / total
+	if plus == 0 && added > 0 {
+		plus = 1
+	}
+	minus := n - plus
+	if minus == 0 && deleted > 0 && plus > 1 {
+		plus, minus = plus-1, 1
+	}
+	return lineAddGutter.Render(strings.Repeat("+", plus)) + lineDelGutter.Render(strings.Repeat("-", minus))
+}
+
+// buildDiffstatPreview renders one row per file with its line count and bar
+func (m Model) buildDiffstatPreview() PreviewContent {
+	if len(m.diffstat) == 0 {
+		return PreviewContent{Valid: true, Message: "no changes against HEAD"}
+	}
+
+	maxChange, pathWidth := 0, 0
+	for _, f := range m.diffstat {
+		maxChange = max(maxChange, f.Added+f.Deleted)
+		pathWidth = max(pathWidth, len(f.Path))
+	}
+	countWidth := len(fmt.Sprint(maxChange))
+	barWidth := m.width / 3
+	pathWidth = min(pathWidth, m.width-gutterWidth-countWidth-barWidth-8)
+
+	var raw, highlighted []string
+	for i, f := range m.diffstat {
+		path := f.Path
+		if r := []rune(path); len(r) > pathWidth && pathWidth > 4 {
+			path = "..." + string(r[len(r)-pathWidth+3:])
+		}
+		count := fmt.Sprintf("%*d", countWidth, f.Added+f.Deleted)
+		raw = append(raw, fmt.Sprintf("%-*s | %s +%d -%d", pathWidth, path, count, f.Added, f.Deleted))
+		bar := renderStatBar(f.Added, f.Deleted, maxChange, barWidth)
+		if Accessible {
+			bar = fmt.Sprintf("%d added, %d removed", f.Added, f.Deleted)
+		}
+		row := padRight(path, pathWidth+1) + dimStyle.Render("| ") + count + " " + bar
+		if i == m.diffstatSelected {
+			row = selectedStyle.Render(cursorMarker()) + row
+		} else {
+			row = "  " + row
+		}
+		highlighted = append(highlighted, row)
+	}
+	return PreviewContent{Valid: true, RawLines: raw, HighlightedLines: highlighted}
+}
+
+// renderDiffstatList renders the totals in place of the file list
+func (m Model) renderDiffstatList() string {
+	var lines []string
+	header := dimStyle.Render("DIFFSTAT")
+	hint := keyStyle.Render("enter") + dimStyle.Render(" open  ") +
+		keyStyle.Render("esc") + dimStyle.Render(" back")
+	lines = append(lines, padLine(header, hint, m.width))
+
+	var added, deleted int
+	for _, f := range m.diffstat {
+		added += f.Added
+		deleted += f.Deleted
+	}
+	if len(m.diffstat) > 0 {
+		noun := "files"
+		if len(m.diffstat) == 1 {
+			noun = "file"
+		}
+		lines = append(lines, fmt.Sprintf("  %d %s changed  ", len(m.diffstat), noun)+
+			renderDiffStats(git.DiffStats{Added: added, Deleted: deleted}))
+	}
+	untracked := 0
+	for _, f := range m.files {
+		if f.GitCode == "??" {
+			untracked++
+		}
+	}
+	if untracked > 0 {
+		lines = append(lines, "  "+dimStyle.Render(fmt.Sprintf("%d untracked not counted", untracked)))
+	}
+
+	for len(lines) < m.listRows() {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
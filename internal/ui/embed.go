@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kateleext/perch/internal/git"
+)
+
+// StatusSource lists the files to show for a directory; git.GetStatus by default
+type StatusSource func(ctx context.Context, dir string) ([]git.FileStatus, error)
+
+// SelectionMsg is emitted when the selection settles on a file, so a parent
+// program embedding perch can react to it
+type SelectionMsg struct {
+	File git.FileStatus
+}
+
+// SetStatusSource replaces where the file list comes from
+func (m *Model) SetStatusSource(source StatusSource) {
+	m.statusSource = source
+}
+
+// SetPlainDir lists dir's files by modification time, for directories that
+// aren't git repos: previews work, but there are no diffs or commits
+func (m *Model) SetPlainDir() {
+	m.plainDir = true
+	m.statusSource = git.GetDirStatus
+}
+
+// QuitRequestMsg is sent instead of quitting when perch is embedded, so the
+// host program decides whether to exit
+type QuitRequestMsg struct{}
+
+// NewEmbedded creates a model for a pane inside another program. Quitting
+// is left to the host, and nothing is persisted: no session is loaded or
+// saved and no snapshots are recorded into the host's repo.
+func NewEmbedded(dir string) Model {
+	return newModel(dir, true)
+}
+
+// quit ends the program, or asks the host to when embedded
+func (m Model) quit() tea.Cmd {
+	if m.embedded {
+		return func() tea.Msg { return QuitRequestMsg{} }
+	}
+	return tea.Quit
+}
+
+// SetSize lays perch out in a width×height pane, as a WindowSizeMsg would
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.recalculateViewport()
+}
+
+// selectionCmd reports the settled selection to a host program
+func (m Model) selectionCmd() tea.Cmd {
+	if !m.embedded || m.selected < 0 || m.selected >= len(m.files) {
+		return nil
+	}
+	file := m.files[m.selected]
+	return func() tea.Msg {
+		return SelectionMsg{File: file}
+	}
+}
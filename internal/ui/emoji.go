@@ -0,0 +1,70 @@
+package ui
+
+// emojiShortcodes maps the GitHub :shortcode: names seen most in READMEs,
+// changelogs, and issue templates to their emoji
+var emojiShortcodes = map[string]string{
+	"+1":                   "👍",
+	"-1":                   "👎",
+	"100":                  "💯",
+	"arrow_down":           "⬇️",
+	"arrow_left":           "⬅️",
+	"arrow_right":          "➡️",
+	"arrow_up":             "⬆️",
+	"art":                  "🎨",
+	"bookmark":             "🔖",
+	"boom":                 "💥",
+	"bug":                  "🐛",
+	"bulb":                 "💡",
+	"calendar":             "📆",
+	"check":                "✔️",
+	"clap":                 "👏",
+	"clipboard":            "📋",
+	"construction":         "🚧",
+	"memo":                 "📝",
+	"eyes":                 "👀",
+	"fire":                 "🔥",
+	"gear":                 "⚙️",
+	"globe_with_meridians": "🌐",
+	"green_heart":          "💚",
+	"hammer":               "🔨",
+	"hammer_and_wrench":    "🛠️",
+	"heart":                "❤️",
+	"heavy_check_mark":     "✔️",
+	"heavy_minus_sign":     "➖",
+	"heavy_plus_sign":      "➕",
+	"hourglass":            "⌛",
+	"information_source":   "ℹ️",
+	"key":                  "🔑",
+	"label":                "🏷️",
+	"link":                 "🔗",
+	"lock":                 "🔒",
+	"mag":                  "🔍",
+	"package":              "📦",
+	"pencil":               "📝",
+	"pencil2":              "✏️",
+	"pushpin":              "📌",
+	"question":             "❓",
+	"recycle":              "♻️",
+	"red_circle":           "🔴",
+	"rocket":               "🚀",
+	"rotating_light":       "🚨",
+	"smile":                "😄",
+	"sparkles":             "✨",
+	"star":                 "⭐",
+	"tada":                 "🎉",
+	"thinking":             "🤔",
+	"thumbsdown":           "👎",
+	"thumbsup":             "👍",
+	"truck":                "🚚",
+	"warning":              "⚠️",
+	"wastebasket":          "🗑️",
+	"white_check_mark":     "✅",
+	"wrench":               "🔧",
+	"x":                    "❌",
+	"zap":                  "⚡",
+}
+
+// isShortcodeByte reports whether b can appear in an emoji shortcode name
+func isShortcodeByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= '0' && b <= '9' || b == '_' || b == '+' || b == '-'
+}
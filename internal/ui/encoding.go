@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// detectEncoding guesses the encoding of file bytes that aren't UTF-8.
+// It returns nil for UTF-8 (or anything it can't place better than UTF-8).
+func detectEncoding(data []byte) (encoding.Encoding, string) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+		return unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM), "UTF-16LE"
+	case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		return unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM), "UTF-16BE"
+	}
+	if utf8.Valid(data) {
+		if order, ok := utf16Order(data); ok {
+			return unicode.UTF16(order, unicode.IgnoreBOM), utf16Name(order)
+		}
+		return nil, ""
+	}
+	if isShiftJIS(data) {
+		return japanese.ShiftJIS, "Shift-JIS"
+	}
+	// Windows-1252 is Latin-1 plus printable characters in 0x80–0x9f,
+	// which is what "Latin-1" files almost always actually are
+	return charmap.Windows1252, "Latin-1"
+}
+
+// utf16Order spots BOM-less UTF-16 text: ASCII-range characters leave every
+// other byte zero
+func utf16Order(data []byte) (unicode.Endianness, bool) {
+	n := min(len(data), 4096) &^ 1
+	if n < 2 {
+		return unicode.LittleEndian, false
+	}
+	evenZeros, oddZeros := 0, 0
+	for i := 0; i < n; i += 2 {
+		if data[i] == 0 {
+			evenZeros++
+		}
+		if data[i+1] == 0 {
+			oddZeros++
+		}
+	}
+	pairs := n / 2
+	switch {
+	case oddZeros*10 >= pairs*7 && evenZeros*10 < pairs:
+		return unicode.LittleEndian, true
+	case evenZeros*10 >= pairs*7 && oddZeros*10 < pairs:
+		return unicode.BigEndian, true
+	}
+	return unicode.LittleEndian, false
+}
+
+func utf16Name(order unicode.Endianness) string {
+	if order == unicode.BigEndian {
+		return "UTF-16BE"
+	}
+	return "UTF-16LE"
+}
+
+// isShiftJIS reports whether data is well-formed Shift-JIS with at least one
+// double-byte character
+func isShiftJIS(data []byte) bool {
+	double := false
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		switch {
+		case b < 0x80, b >= 0xa1 && b <= 0xdf: // ASCII, half-width katakana
+		case b >= 0x81 && b <= 0x9f, b >= 0xe0 && b <= 0xfc:
+			if i+1 >= len(data) {
+				return false
+			}
+			t := data[i+1]
+			if t < 0x40 || t == 0x7f || t > 0xfc {
+				return false
+			}
+			double = true
+			i++
+		default:
+			return false
+		}
+	}
+	return double
+}
+
+// decodeString transcodes s with enc, leaving it as-is if enc is nil or fails
+func decodeString(enc encoding.Encoding, s string) string {
+	if enc == nil {
+		return s
+	}
+	out, err := enc.NewDecoder().String(s)
+	if err != nil {
+		return s
+	}
+	return out
+}
@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/kateleext/perch/internal/git"
+)
+
+const utf8BOM = "\xef\xbb\xbf"
+
+// textInfo describes how a file's text was encoded on disk
+type textInfo struct {
+	lineEnding string // "CRLF", "mixed", or "" for plain LF
+	bom        bool   // started with a UTF-8 byte order mark
+	crlf       []bool // per line: ended in CRLF
+}
+
+// normalizeText strips a UTF-8 BOM and CRLF line endings so highlighting and
+// width math see plain LF text, recording what was removed
+func normalizeText(content string) (string, textInfo) {
+	var info textInfo
+	if strings.HasPrefix(content, utf8BOM) {
+		content = content[len(utf8BOM):]
+		info.bom = true
+	}
+	if !strings.Contains(content, "\r\n") {
+		return content, info
+	}
+
+	lines := strings.Split(content, "\n")
+	info.crlf = make([]bool, len(lines))
+	crlf, lf := 0, 0
+	for i, line := range lines {
+		if i == len(lines)-1 && line == "" {
+			break // after the final newline
+		}
+		if strings.HasSuffix(line, "\r") {
+			lines[i] = line[:len(line)-1]
+			info.crlf[i] = true
+			crlf++
+		} else if i < len(lines)-1 {
+			lf++
+		}
+	}
+	info.lineEnding = "CRLF"
+	if lf > 0 {
+		info.lineEnding = "mixed"
+	}
+	return strings.Join(lines, "\n"), info
+}
+
+// markEOLChanges strips a BOM and CRs from removed diff rows and returns a
+// note for each added row that differs from a removed row of the same change
+// only in its line ending (or BOM), keyed by row index. The diff parser
+// already drops CRs, so an added line identical to a removed one next to it
+// changed nothing but its ending.
+func markEOLChanges(rows []git.DiffLine, info textInfo) map[int]string {
+	notes := make(map[int]string)
+	removed := make(map[string]bool) // text -> had a BOM, for the current change
+	for i, row := range rows {
+		switch row.Type {
+		case "remove":
+			text := strings.TrimSuffix(row.Content, "\r")
+			hadBOM := row.OldNumber == 1 && strings.HasPrefix(text, utf8BOM)
+			text = strings.TrimPrefix(text, utf8BOM)
+			rows[i].Content = text
+			removed[text] = removed[text] || hadBOM
+		case "add":
+			hadBOM, ok := removed[row.Content]
+			if !ok {
+				continue
+			}
+			switch {
+			case row.Number == 1 && hadBOM != info.bom:
+				notes[i] = "byte order mark only"
+			case row.Number-1 < len(info.crlf) && info.crlf[row.Number-1]:
+				notes[i] = "line ending only: LF → CRLF"
+			default:
+				notes[i] = "line ending only: CRLF → LF"
+			}
+		default:
+			// Context ends the change; removals only pair with nearby adds
+			clear(removed)
+		}
+	}
+	return notes
+}
+
+// encodingLabel names a file's non-default encoding, line endings, and BOM
+// for the header
+func (pc PreviewContent) encodingLabel() string {
+	var parts []string
+	if pc.Encoding != "" {
+		parts = append(parts, pc.Encoding)
+	}
+	switch pc.LineEnding {
+	case "CRLF":
+		parts = append(parts, "CRLF")
+	case "mixed":
+		parts = append(parts, "mixed CRLF/LF")
+	}
+	if pc.BOM {
+		parts = append(parts, "BOM")
+	}
+	return strings.Join(parts, " · ")
+}
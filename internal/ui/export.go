@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kateleext/perch/internal/git"
+)
+
+// exportedMsg reports the result of writing a patch file
+type exportedMsg struct {
+	path string
+	err  error
+}
+
+// startExport opens the patch path prompt; all exports the whole working
+// tree instead of the selected file. The suggested path is in the temp dir,
+// so the patch doesn't turn up as an untracked file in the list.
+func (m *Model) startExport(all bool) {
+	if !all && (m.selected < 0 || m.selected >= len(m.files)) {
+		return
+	}
+	m.exportActive = true
+	m.exportAll = all
+	name := filepath.Base(m.dir) + ".patch"
+	if !all {
+		name = filepath.Base(m.files[m.selected].Path) + ".patch"
+	}
+	m.exportInput = filepath.Join(os.TempDir(), name)
+}
+
+// updateExport handles keys while the patch path prompt is open
+func (m Model) updateExport(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, m.quit()
+	case tea.KeyEsc:
+		m.exportActive = false
+	case tea.KeyEnter:
+		m.exportActive = false
+		if strings.TrimSpace(m.exportInput) != "" {
+			return m, m.exportCmd(strings.TrimSpace(m.exportInput))
+		}
+	case tea.KeyBackspace:
+		if len(m.exportInput) > 0 {
+			r := []rune(m.exportInput)
+			m.exportInput = string(r[:len(r)-1])
+		}
+	case tea.KeyCtrlU:
+		m.exportInput = ""
+	case tea.KeyRunes, tea.KeySpace:
+		m.exportInput += string(msg.Runes)
+	}
+	return m, nil
+}
+
+// exportCmd writes the selected file's (or the whole tree's) diff to path,
+// resolved against the target directory
+func (m *Model) exportCmd(path string) tea.Cmd {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.dir, path)
+	}
+
+	dir := m.dir
+	all := m.exportAll
+	var file git.FileStatus
+	gitRoot := m.gitRoot
+	if !all {
+		file = m.files[m.selected]
+		if file.GitRoot != "" {
+			gitRoot = file.GitRoot
+		}
+	}
+
+	return func() tea.Msg {
+		var patch string
+		var err error
+		if all {
+			patch, err = git.GetWorkingTreePatch(context.Background(), dir)
+		} else {
+			patch, err = git.GetFilePatch(context.Background(), gitRoot, file.OldFullPath, file.FullPath)
+		}
+		if err != nil {
+			return exportedMsg{err: err}
+		}
+		if patch == "" {
+			return exportedMsg{err: errors.New("no uncommitted changes to export")}
+		}
+		return exportedMsg{path: path, err: writeNewFile(path, []byte(patch))}
+	}
+}
+
+// writeNewFile writes data to path, refusing to replace an existing file
+func writeNewFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		return errors.New(path + " already exists")
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kateleext/perch/internal/git"
+)
+
+// FetchInterval enables a periodic background `git fetch` (0 = off)
+var FetchInterval time.Duration
+
+// fetchTickMsg triggers the next background fetch
+type fetchTickMsg struct{}
+
+// fetchDoneMsg reports a finished fetch along with the new ahead/behind counts
+type fetchDoneMsg struct {
+	dir  string
+	err  error
+	sync syncState
+}
+
+// syncState is HEAD's position relative to its upstream
+type syncState struct {
+	ahead, behind int
+	ok            bool // false when there is no upstream
+}
+
+// loadSync reads ahead/behind counts; it's local and cheap, unlike the fetch
+func loadSync(dir string) syncState {
+	ahead, behind, err := git.GetAheadBehind(context.Background(), dir)
+	return syncState{ahead: ahead, behind: behind, ok: err == nil}
+}
+
+// fetchCmd fetches in the background and re-reads ahead/behind
+func (m Model) fetchCmd() tea.Msg {
+	err := git.Fetch(context.Background(), m.dir)
+	return fetchDoneMsg{dir: m.dir, err: err, sync: loadSync(m.dir)}
+}
+
+func fetchTickCmd() tea.Cmd {
+	return tea.Tick(FetchInterval, func(time.Time) tea.Msg {
+		return fetchTickMsg{}
+	})
+}
+
+// render renders "↑n ↓n" for the header, or "" when in sync or untracked
+func (s syncState) render() string {
+	if !s.ok || (s.ahead == 0 && s.behind == 0) {
+		return ""
+	}
+	out := ""
+	if s.ahead > 0 {
+		out += fmt.Sprintf("↑%d", s.ahead)
+	}
+	if s.behind > 0 {
+		if out != "" {
+			out += " "
+		}
+		out += fmt.Sprintf("↓%d", s.behind)
+	}
+	return cyanStyle.Render(out)
+}
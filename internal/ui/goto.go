@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// updateGoto handles keys while the ":" goto-line prompt is open
+func (m Model) updateGoto(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, m.quit()
+	case "esc":
+		m.gotoActive = false
+		m.gotoInput = ""
+	case "enter":
+		m.gotoActive = false
+		if n, err := strconv.Atoi(m.gotoInput); err == nil {
+			m.gotoLine(n)
+		}
+		m.gotoInput = ""
+	case "backspace":
+		if m.gotoInput == "" {
+			m.gotoActive = false
+		} else {
+			m.gotoInput = m.gotoInput[:len(m.gotoInput)-1]
+		}
+	default:
+		if s := msg.String(); len(s) == 1 && s[0] >= '0' && s[0] <= '9' {
+			m.gotoInput += s
+		}
+	}
+	return m, nil
+}
+
+// gotoLine scrolls the preview so file line n sits near the top; lines
+// before the first go to it
+func (m *Model) gotoLine(n int) {
+	n = max(n, 1)
+	pc := m.displayPreview()
+	idx := visualIndexForLine(pc.WrappedLinesForWidth(m.wrapWidth()), *pc, n)
+	if idx < 0 {
+		m.statusMessage = "no line " + strconv.Itoa(n)
+		return
+	}
+	m.statusMessage = ""
+	offset := idx - 3 // keep a little context above
+	if offset < 0 {
+		offset = 0
+	}
+	m.viewport.SetYOffset(offset)
+}
+
+// visualIndexForLine maps a 1-based file line number to the first wrapped
+// segment showing it. Rows with diff records use their new-file number, and
+// only file lines (context and added rows) match; otherwise rows are
+// numbered in order.
+func visualIndexForLine(wrapped []VisualLine, pc PreviewContent, n int) int {
+	for i, vl := range wrapped {
+		if vl.SegmentIndex != 0 {
+			continue
+		}
+		lineNum := vl.LogicalIndex + 1
+		if vl.LogicalIndex < len(pc.Diff) {
+			row := pc.Diff[vl.LogicalIndex]
+			if row.Type != "context" && row.Type != "add" {
+				continue
+			}
+			lineNum = row.Number
+		}
+		if lineNum == n {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kateleext/perch/internal/git"
+	"github.com/kateleext/perch/internal/hooks"
+)
+
+// hookCmd fires a lifecycle hook in the background
+func (m Model) hookCmd(event string, payload map[string]any) tea.Cmd {
+	if !hooks.Has(event) {
+		return nil
+	}
+	dir := m.dir
+	return func() tea.Msg {
+		hooks.Fire(event, dir, payload)
+		return nil
+	}
+}
+
+// fileSelectedHook fires file-selected for the current selection
+func (m Model) fileSelectedHook() tea.Cmd {
+	if m.mode != modeFiles || m.selected < 0 || m.selected >= len(m.files) {
+		return nil
+	}
+	f := m.files[m.selected]
+	return m.hookCmd(hooks.FileSelected, map[string]any{
+		"path":   f.Path,
+		"status": f.Status,
+		"change": f.ChangeType(),
+	})
+}
+
+// changeDetectedHook fires change-detected for files changed since the last load
+func (m Model) changeDetectedHook(changed []git.FileStatus) tea.Cmd {
+	if len(changed) == 0 {
+		return nil
+	}
+	paths := make([]string, len(changed))
+	for i, f := range changed {
+		paths[i] = f.Path
+	}
+	return m.hookCmd(hooks.ChangeDetected, map[string]any{"paths": paths})
+}
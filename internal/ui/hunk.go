@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kateleext/perch/internal/clipboard"
+	"github.com/kateleext/perch/internal/git"
+)
+
+// visibleLineRange returns the first and last new-file line numbers shown
+// in the viewport (0, 0 if none are)
+func (m *Model) visibleLineRange() (lo, hi int) {
+	wrapped := m.displayPreview().WrappedLinesForWidth(m.wrapWidth())
+	diff := m.displayPreview().Diff
+	end := m.viewport.YOffset + m.viewport.Height
+	for i := m.viewport.YOffset; i < end && i < len(wrapped); i++ {
+		idx := wrapped[i].LogicalIndex
+		if idx >= len(diff) || diff[idx].Number == 0 {
+			continue
+		}
+		n := diff[idx].Number
+		if lo == 0 || n < lo {
+			lo = n
+		}
+		if n > hi {
+			hi = n
+		}
+	}
+	return lo, hi
+}
+
+// pickHunk returns the hunk overlapping lines lo..hi, or the closest one
+func pickHunk(hunks []git.Hunk, lo, hi int) git.Hunk {
+	best, bestDist := hunks[0], -1
+	for _, h := range hunks {
+		end := h.NewStart + h.NewCount - 1
+		if end < h.NewStart {
+			end = h.NewStart
+		}
+		dist := 0
+		if end < lo {
+			dist = lo - end
+		} else if h.NewStart > hi {
+			dist = h.NewStart - hi
+		}
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = h, dist
+		}
+	}
+	return best
+}
+
+// copyHunkCmd copies the diff hunk under the viewport, with its file
+// header, to the clipboard as a unified diff. Hunks come from the file's
+// diff against HEAD, so staged and untracked changes can be copied too; the
+// one under the viewport is picked by new-file line, which is the same in
+// that diff and the preview's.
+func (m *Model) copyHunkCmd() tea.Cmd {
+	if m.selected < 0 || m.selected >= len(m.files) {
+		return nil
+	}
+	file := m.files[m.selected]
+	gitRoot := m.gitRoot
+	if file.GitRoot != "" {
+		gitRoot = file.GitRoot
+	}
+	lo, hi := m.visibleLineRange()
+
+	return func() tea.Msg {
+		patch, err := git.GetFilePatch(context.Background(), gitRoot, file.OldFullPath, file.FullPath)
+		if err != nil {
+			return copiedMsg{err: err}
+		}
+		header, hunks := git.SplitPatch(patch)
+		if len(hunks) == 0 {
+			return copiedMsg{err: errors.New("no uncommitted changes in " + file.Path)}
+		}
+		hunk := pickHunk(hunks, lo, hi)
+		return copiedMsg{
+			what: "hunk at " + file.Path + ":" + strconv.Itoa(hunk.NewStart),
+			err:  clipboard.Copy(header + hunk.Text),
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kateleext/perch/internal/git"
+)
+
+// slowScanAfter is how long the first scan may run before perch asks
+// whether to narrow it; pointing it at a home directory can take minutes
+const slowScanAfter = 3 * time.Second
+
+// largeRepoFiles is how many listed files count as an enormous repo
+const largeRepoFiles = 2000
+
+// scopeWindow is how recently a path must have changed to stay in a
+// scoped scan
+const scopeWindow = 24 * time.Hour
+
+// slowScanMsg fires once the first scan has had slowScanAfter to finish
+type slowScanMsg struct{}
+
+func slowScanCmd() tea.Cmd {
+	return tea.Tick(slowScanAfter, func(time.Time) tea.Msg {
+		return slowScanMsg{}
+	})
+}
+
+// updateScalePrompt handles keys while perch asks about an enormous repo
+func (m Model) updateScalePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "s":
+		paths := git.RecentPaths(m.dir, time.Now().Add(-scopeWindow))
+		if len(paths) == 0 {
+			m.statusMessage = "nothing here changed in the last day"
+			return m, nil
+		}
+		spec := git.ActivePathspec()
+		narrowed := spec.Narrow(paths)
+		if len(narrowed.Include) == 0 {
+			m.statusMessage = "nothing matching --include changed in the last day"
+			return m, nil
+		}
+		git.SetPathspec(narrowed)
+		m.scoped = true
+		m.unscopedInclude = spec.Include
+		m.scalePrompt = ""
+		m.statusMessage = ""
+		// Whatever the unscoped scan finds now is thrown away
+		m.scanGen++
+		m.loading = true
+		return m, m.loadFiles
+	case "enter", "c":
+		m.scalePrompt = ""
+		m.statusMessage = ""
+	case "q", "ctrl+c", "esc":
+		if m.embedded {
+			m.scalePrompt = ""
+			return m, nil
+		}
+		return m, m.quit()
+	}
+	return m, nil
+}
+
+// renderScalePrompt offers to narrow or abandon a scan of an enormous repo
+func (m Model) renderScalePrompt() string {
+	lines := []string{
+		cyanStyle.Render("this looks like a very large repo"),
+		dimStyle.Render(m.scalePrompt),
+		"",
+		"s  only scan paths changed in the last day",
+		"c  keep scanning everything",
+		"q  quit",
+	}
+	if m.statusMessage != "" {
+		lines = append(lines, "", dimStyle.Render(m.statusMessage))
+	}
+	if Accessible {
+		return strings.Join(lines, "\n") + "\n"
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// scaleReason says why a first scan of files looks like an enormous repo,
+// or "" when it doesn't
+func scaleReason(files []git.FileStatus, took time.Duration) string {
+	switch {
+	case len(files) > largeRepoFiles:
+		return fmt.Sprintf("%d changed files in %s", len(files), took.Round(100*time.Millisecond))
+	case took > slowScanAfter:
+		return fmt.Sprintf("git status took %s", took.Round(100*time.Millisecond))
+	}
+	return ""
+}
@@ -0,0 +1,139 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Width breakpoints: below each one, one more thing gives way
+const (
+	hintsWidth      = 70 // key hints are dropped
+	compactWidth    = 50 // preview gutters shrink from 4 columns to 2
+	singlePaneWidth = 40 // the list and the preview take turns filling the screen
+)
+
+// Below this size nothing lays out legibly, so View asks for more room
+const (
+	minWidth  = 24
+	minHeight = 12
+)
+
+// tooSmall reports whether the terminal is under the minimum size
+func (m Model) tooSmall() bool {
+	return m.width < minWidth || m.height < minHeight
+}
+
+// renderTooSmall fills the screen with a request for a bigger terminal
+func (m Model) renderTooSmall() string {
+	msg := fmt.Sprintf("terminal too small (need %dx%d)", minWidth, minHeight)
+	if lipgloss.Width(msg) > m.width {
+		msg = fmt.Sprintf("need %dx%d", minWidth, minHeight)
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, dimStyle.Render(msg))
+}
+
+// showHints reports whether there's room for key hints
+func (m Model) showHints() bool {
+	return m.width >= hintsWidth
+}
+
+// compactGutter reports whether the preview gutter drops its indent
+func (m Model) compactGutter() bool {
+	return m.width < compactWidth
+}
+
+// singlePane reports whether the screen shows the list or the preview, not both
+func (m Model) singlePane() bool {
+	return m.width < singlePaneWidth
+}
+
+// hint returns h, or nothing when there's no room for hints
+func (m Model) hint(h string) string {
+	if !m.showHints() {
+		return ""
+	}
+	return h
+}
+
+// wrapWidth is the width preview lines are wrapped to: the viewport's, which
+// lags the terminal while a resize settles. wrapAllLines reserves a 4-column
+// gutter; a compact gutter is 2, so the text gets the difference.
+func (m Model) wrapWidth() int {
+	if m.compactGutter() {
+		return m.viewport.Width + 2
+	}
+	return m.viewport.Width
+}
+
+// gutterIndent is the blank space left of the +/- gutter
+func (m Model) gutterIndent() string {
+	if m.compactGutter() {
+		return ""
+	}
+	return "  "
+}
+
+// previewOnly reports whether the preview has the whole screen: zen mode,
+// or the preview's turn in single-pane flow
+func (m Model) previewOnly() bool {
+	return (m.zen && m.mode == modeFiles) || (m.singlePane() && m.singlePreview)
+}
+
+// overPreview reports whether screen row y falls in the preview
+func (m Model) overPreview(y int) bool {
+	if m.previewOnly() {
+		return true
+	}
+	if m.singlePane() {
+		return false
+	}
+	if StatusLine == "top" {
+		y -= m.statusLineHeight()
+	}
+	return y > m.listRows()+3
+}
+
+// toggleSinglePane flips between the list and the preview in single-pane flow
+func (m *Model) toggleSinglePane() {
+	m.singlePreview = !m.singlePreview
+	m.recalculateViewport()
+}
+
+// renderPreviewOnly renders the preview without the list above it
+func (m Model) renderPreviewOnly() string {
+	var b strings.Builder
+	if StatusLine == "top" {
+		b.WriteString(m.renderStatusLine())
+	}
+	b.WriteString(m.renderModeHeader())
+	b.WriteString(m.divider())
+	b.WriteString(m.renderPreviewWithIndicators())
+	if m.paneHeight() > 0 {
+		b.WriteString(m.renderRunPane())
+	}
+	if StatusLine == "bottom" {
+		b.WriteString(m.renderStatusLine())
+	}
+	b.WriteString(m.renderFooter())
+	return b.String()
+}
+
+// renderListOnly renders the list's turn in single-pane flow, giving it
+// every row but the footer
+func (m Model) renderListOnly() string {
+	var b strings.Builder
+	if StatusLine == "top" {
+		b.WriteString(m.renderStatusLine())
+	}
+	b.WriteString(m.renderList())
+	if m.paneHeight() > 0 {
+		b.WriteString(m.renderRunPane())
+	}
+	if StatusLine == "bottom" {
+		b.WriteString(m.renderStatusLine())
+	}
+	b.WriteString(m.renderFooter())
+	return b.String()
+}
@@ -0,0 +1,73 @@
+package ui
+
+import "strings"
+
+// Hyperlinks wraps URLs in previews in OSC 8 escapes, which iTerm2, kitty,
+// WezTerm and others make clickable (and the rest ignore)
+var Hyperlinks = true
+
+const (
+	osc8Prefix = "\x1b]8;"
+	osc8Close  = "\x1b]8;;\x1b\\"
+)
+
+// hyperlink makes text a link to url
+func hyperlink(url, text string) string {
+	if !Hyperlinks || url == "" {
+		return text
+	}
+	return osc8Prefix + ";" + url + "\x1b\\" + text + osc8Close
+}
+
+// isAbsoluteURL reports whether a link target can be opened outside the repo
+func isAbsoluteURL(url string) bool {
+	return strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "mailto:")
+}
+
+// linkifyURLs turns bare http(s) URLs in a highlighted line into hyperlinks.
+// A URL ends at whitespace, an escape sequence, or a quote/bracket, and
+// trailing punctuation is left outside; URLs already inside a link are skipped.
+func linkifyURLs(s string) string {
+	if !Hyperlinks || !strings.Contains(s, "://") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if isANSIStart(s, i) {
+			j := skipANSI(s, i)
+			b.WriteString(s[i:j])
+			if strings.HasPrefix(s[i:j], osc8Prefix) && s[i:j] != osc8Close {
+				// Already a link: copy through to its close
+				end := strings.Index(s[j:], osc8Close)
+				if end < 0 {
+					b.WriteString(s[j:])
+					return b.String()
+				}
+				b.WriteString(s[j : j+end])
+				j += end
+			}
+			i = j
+			continue
+		}
+		if !strings.HasPrefix(s[i:], "https://") && !strings.HasPrefix(s[i:], "http://") {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(s) && !strings.ContainsRune(" \t\x1b\"'<>()[]{}`", rune(s[j])) {
+			j++
+		}
+		for j > i && strings.ContainsRune(".,;:!?", rune(s[j-1])) {
+			j--
+		}
+		url := s[i:j]
+		if strings.HasSuffix(url, "://") {
+			b.WriteString(url)
+		} else {
+			b.WriteString(hyperlink(url, url))
+		}
+		i = j
+	}
+	return b.String()
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"bytes"
+	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -26,9 +27,17 @@ var (
 	mdBullet       = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
 	mdTableBorder  = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
 	mdTableHeader  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("252"))
+	mdMetaKey      = lipgloss.NewStyle().Foreground(lipgloss.Color("109"))
+	mdStrike       = lipgloss.NewStyle().Strikethrough(true)
+	mdFootnote     = lipgloss.NewStyle().Foreground(lipgloss.Color("109"))
+	mdTaskOpen     = lipgloss.NewStyle().Foreground(lipgloss.Color("109"))
+	mdTaskDone     = lipgloss.NewStyle().Foreground(lipgloss.Color("#5a8a5a"))
 
 	fenceRegex     = regexp.MustCompile("^[ \t]*```([A-Za-z0-9_+-]*)")
 	tableSepRegex  = regexp.MustCompile(`^\|?[\s:-]+\|[\s|:-]*$`)
+	// A JSX block in MDX opens with a tag (components and HTML alike), a
+	// fragment, or an {expression}
+	jsxStartRegex  = regexp.MustCompile(`^(<[A-Za-z][\w.:-]*([\s/>]|$)|<>|</|\{)`)
 )
 
 type mdRenderer struct {
@@ -37,12 +46,34 @@ type mdRenderer struct {
 	// Table buffering for proper column alignment
 	tableBuffer [][]string // buffered rows (each row is slice of cells)
 	tableStart  int        // line index where table started
+	// Indent columns of the list items enclosing the current line, outermost first
+	listIndents []int
+	mermaid     *mermaidState // set inside a ```mermaid fence
+	inMath      bool          // inside a $$ math block
+	baseDir     string        // directory of the file, for relative image paths
+	rootDir     string        // watched directory, for site-absolute image paths
+	// MDX: ESM statements and JSX blocks are highlighted as JSX
+	mdx   bool
+	inESM bool // inside a multi-line import/export
+	inJSX bool // inside a JSX block, which runs to the next blank line
 }
 
-func highlightMarkdownLines(lines []string, filename string) []string {
-	r := &mdRenderer{}
+// listBullets are the bullets of unordered list items by nesting depth
+var listBullets = []string{"•", "◦", "▪"}
+
+// listGuide is drawn once per enclosing list level to the left of nested items
+const listGuide = "│ "
+
+func highlightMarkdownLines(lines []string, filename, rootDir string) []string {
+	r := &mdRenderer{baseDir: filepath.Dir(filename), rootDir: rootDir, mdx: strings.EqualFold(filepath.Ext(filename), ".mdx")}
 	result := make([]string, len(lines))
-	for i, line := range lines {
+	start := 0
+	if end := frontMatterEnd(lines); end > 0 {
+		copy(result, renderFrontMatter(lines[:end+1]))
+		start = end + 1
+	}
+	for i := start; i < len(lines); i++ {
+		line := lines[i]
 		rendered, flush := r.renderLine(line, i)
 		if flush && len(r.tableBuffer) > 0 {
 			// Flush the buffered table into result
@@ -78,14 +109,44 @@ func (r *mdRenderer) renderLine(line string, lineIdx int) (rendered string, flus
 	if r.inCodeBlock {
 		if strings.HasPrefix(trimmed, "```") {
 			r.inCodeBlock = false
+			if r.mermaid != nil {
+				r.mermaid = nil
+				return mdMermaidBox.Render("╰─"), false
+			}
 			return "", false
 		}
+		if r.mermaid != nil {
+			return r.mermaid.renderMermaidLine(line), false
+		}
 		return highlightCodeFenceLine(line, r.codeLang), false
 	}
 
+	if r.mdx && r.isJSXLine(line, trimmed) {
+		return highlightCodeFenceLine(line, "jsx"), false
+	}
+
+	if r.inMath {
+		if trimmed == "$$" {
+			r.inMath = false
+			return "", false
+		}
+		return leadingWhitespace(line) + "  " + renderMath(trimmed), false
+	}
+	if trimmed == "$$" {
+		r.inMath = true
+		return "", false
+	}
+	if len(trimmed) > 4 && strings.HasPrefix(trimmed, "$$") && strings.HasSuffix(trimmed, "$$") {
+		return leadingWhitespace(line) + "  " + renderMath(strings.TrimSpace(trimmed[2 : len(trimmed)-2])), false
+	}
+
 	if matches := fenceRegex.FindStringSubmatch(line); matches != nil {
 		r.inCodeBlock = true
 		r.codeLang = matches[1]
+		if r.codeLang == "mermaid" {
+			r.mermaid = &mermaidState{labels: map[string]string{}}
+			return mdMermaidBox.Render("╭─ ") + dimStyle.Render("mermaid"), false
+		}
 		return "", false
 	}
 
@@ -106,10 +167,100 @@ func (r *mdRenderer) renderLine(line string, lineIdx int) (rendered string, flus
 
 	// Not a table row - flush any buffered table
 	if len(r.tableBuffer) > 0 {
-		return renderMarkdownTextLine(line), true
+		return r.renderMarkdownTextLine(line), true
+	}
+
+	return r.renderMarkdownTextLine(line), false
+}
+
+// isJSXLine tracks MDX's JSX and ESM regions: an import/export at the left
+// margin runs until its statement ends, and a line opening with a tag or
+// expression starts a JSX block that runs to the next blank line
+func (r *mdRenderer) isJSXLine(line, trimmed string) bool {
+	switch {
+	case r.inESM:
+		r.inESM = !esmStatementEnds(trimmed)
+		return true
+	case r.inJSX:
+		if trimmed == "" {
+			r.inJSX = false
+			return false
+		}
+		return true
+	case strings.HasPrefix(line, "import ") || strings.HasPrefix(line, "export "):
+		r.inESM = !esmStatementEnds(trimmed)
+		return true
+	case jsxStartRegex.MatchString(trimmed):
+		r.inJSX = true
+		return true
+	}
+	return false
+}
+
+// esmStatementEnds reports whether an import/export line completes the
+// statement: a semicolon, a from clause, or balanced braces
+func esmStatementEnds(trimmed string) bool {
+	if strings.HasSuffix(trimmed, ";") || strings.Contains(trimmed, " from ") || strings.HasPrefix(trimmed, "from ") {
+		return true
+	}
+	if strings.HasPrefix(trimmed, "import ") && strings.Count(trimmed, "{") == strings.Count(trimmed, "}") &&
+		(strings.HasPrefix(trimmed, "import '") || strings.HasPrefix(trimmed, "import \"")) {
+		return true
+	}
+	return strings.HasPrefix(trimmed, "export ") && strings.Count(trimmed, "{") == strings.Count(trimmed, "}") &&
+		strings.Count(trimmed, "(") == strings.Count(trimmed, ")")
+}
+
+// frontMatterEnd returns the index of the line closing a YAML front matter
+// block at the top of the file, or -1 if there isn't one
+func frontMatterEnd(lines []string) int {
+	if len(lines) == 0 || strings.TrimRight(lines[0], " \t") != "---" {
+		return -1
+	}
+	for i := 1; i < len(lines); i++ {
+		switch strings.TrimRight(lines[i], " \t") {
+		case "---", "...":
+			return i
+		}
 	}
+	return -1
+}
 
-	return renderMarkdownTextLine(line), false
+// renderFrontMatter draws a front matter block, delimiters included, as a
+// key/value table: the delimiters become the table's top and bottom borders
+// so the block keeps its line count
+func renderFrontMatter(block []string) []string {
+	t := table.New().
+		Border(lipgloss.RoundedBorder()).
+		BorderStyle(mdTableBorder).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if col == 0 {
+				return mdMetaKey.Padding(0, 1)
+			}
+			return lipgloss.NewStyle().Padding(0, 1)
+		})
+	for _, line := range block[1 : len(block)-1] {
+		key, value := "", strings.TrimSpace(line)
+		// Nested values and list items stay in the value column under their key
+		if line == value {
+			if k, v, ok := strings.Cut(line, ":"); ok && !strings.ContainsAny(k, " \t\"'") {
+				key, value = k, strings.TrimSpace(v)
+			}
+		}
+		t.Row(key, renderInlineMarkdown(strings.Trim(value, `"'`)))
+	}
+	if len(block) == 2 {
+		t.Row("", "")
+	}
+
+	result := make([]string, len(block))
+	rendered := strings.Split(t.Render(), "\n")
+	if len(block) == 2 {
+		// An empty block has no rows to show, just the borders
+		rendered = []string{rendered[0], rendered[len(rendered)-1]}
+	}
+	copy(result, rendered)
+	return result
 }
 
 func isTableRow(s string) bool {
@@ -170,7 +321,7 @@ func (r *mdRenderer) flushTable() []string {
 		// Apply inline markdown to each cell
 		styledRow := make([]string, len(row))
 		for i, cell := range row {
-			styledRow[i] = renderInlineMarkdown(cell)
+			styledRow[i] = renderInline(cell, r.baseDir, r.rootDir)
 		}
 		t.Row(styledRow...)
 	}
@@ -233,29 +384,100 @@ func highlightCodeFenceLine(line, lang string) string {
 	return result
 }
 
-func renderMarkdownTextLine(line string) string {
+func (r *mdRenderer) renderMarkdownTextLine(line string) string {
 	leading := leadingWhitespace(line)
 	content := strings.TrimLeft(line, " \t")
 
 	if content == "" {
+		// Blank lines between items don't end a list
 		return ""
 	}
 
 	if lvl, body := parseHeading(content); lvl > 0 {
+		r.listIndents = nil
 		return leading + styleHeading(lvl, body)
 	}
 
 	if bullet, body, ok := parseListItem(content); ok {
-		return leading + mdBullet.Render(bullet) + " " + renderInlineMarkdown(body)
+		depth := r.enterListItem(indentColumns(leading))
+		leading = r.listPrefix(depth)
+		if !strings.HasSuffix(bullet, ".") {
+			bullet = listBullets[depth%len(listBullets)]
+		}
+		if done, task, ok := parseTask(body); ok {
+			// The checkbox replaces a bullet; ordered items keep their number
+			if strings.HasSuffix(bullet, ".") {
+				leading += mdBullet.Render(bullet) + " "
+			}
+			return leading + renderTask(done, task)
+		}
+		return leading + mdBullet.Render(bullet) + " " + renderInline(body, r.baseDir, r.rootDir)
+	}
+
+	// An indented line under a list item continues it, lined up with its text
+	if depth := r.continueListItem(indentColumns(leading)); depth >= 0 {
+		leading = r.listPrefix(depth) + "  "
+	} else {
+		r.listIndents = nil
+	}
+
+	if label, body, ok := parseFootnoteDef(content); ok {
+		return leading + mdFootnote.Render("["+label+"]") + " " + renderInline(body, r.baseDir, r.rootDir)
 	}
 
 	if strings.HasPrefix(content, ">") {
 		body := strings.TrimPrefix(content, ">")
 		body = strings.TrimPrefix(body, " ")
-		return leading + mdItalStyle.Render("▌ ") + renderInlineMarkdown(body)
+		return leading + mdItalStyle.Render("▌ ") + renderInline(body, r.baseDir, r.rootDir)
 	}
 
-	return leading + renderInlineMarkdown(content)
+	return leading + renderInline(content, r.baseDir, r.rootDir)
+}
+
+// enterListItem records a list item at the given indent and returns its
+// nesting depth: items indented past the current one open a sublist, and
+// outdented items close sublists back to their level
+func (r *mdRenderer) enterListItem(indent int) int {
+	for len(r.listIndents) > 0 && r.listIndents[len(r.listIndents)-1] > indent {
+		r.listIndents = r.listIndents[:len(r.listIndents)-1]
+	}
+	if len(r.listIndents) == 0 || r.listIndents[len(r.listIndents)-1] < indent {
+		r.listIndents = append(r.listIndents, indent)
+	}
+	return len(r.listIndents) - 1
+}
+
+// continueListItem returns the depth of the list item an indented non-item
+// line belongs to, or -1 if it isn't inside a list
+func (r *mdRenderer) continueListItem(indent int) int {
+	if indent == 0 {
+		return -1
+	}
+	for len(r.listIndents) > 0 && r.listIndents[len(r.listIndents)-1] >= indent {
+		r.listIndents = r.listIndents[:len(r.listIndents)-1]
+	}
+	return len(r.listIndents) - 1
+}
+
+// listPrefix draws the indent guides for a list item at depth
+func (r *mdRenderer) listPrefix(depth int) string {
+	if depth == 0 {
+		return ""
+	}
+	return mdBullet.Render(strings.Repeat(listGuide, depth))
+}
+
+// indentColumns measures leading whitespace, counting a tab as 4 columns
+func indentColumns(leading string) int {
+	cols := 0
+	for _, c := range leading {
+		if c == '\t' {
+			cols += 4
+		} else {
+			cols++
+		}
+	}
+	return cols
 }
 
 func leadingWhitespace(s string) string {
@@ -334,14 +556,48 @@ func parseListItem(s string) (bullet, body string, ok bool) {
 	return "", "", false
 }
 
+// parseTask splits a list item body like "[ ] todo" or "[x] done" into
+// its state and text
+func parseTask(body string) (done bool, task string, ok bool) {
+	if len(body) < 3 || body[0] != '[' || body[2] != ']' {
+		return false, "", false
+	}
+	if len(body) > 3 && body[3] != ' ' && body[3] != '\t' {
+		return false, "", false
+	}
+	switch body[1] {
+	case ' ':
+	case 'x', 'X':
+		done = true
+	default:
+		return false, "", false
+	}
+	return done, strings.TrimSpace(body[3:]), true
+}
+
+// renderTask draws a task list item with a checkbox glyph; completed items
+// are dimmed so what's left stands out
+func renderTask(done bool, task string) string {
+	if done {
+		return mdTaskDone.Render("☑") + " " + dimStyle.Render(stripANSIColors(renderInlineMarkdown(task)))
+	}
+	return mdTaskOpen.Render("☐") + " " + renderInlineMarkdown(task)
+}
+
 func renderInlineMarkdown(s string) string {
+	return renderInline(s, "", "")
+}
+
+// renderInline renders inline markdown, resolving relative image paths
+// against baseDir and site-absolute ones against rootDir
+func renderInline(s string, baseDir, rootDir string) string {
 	var out strings.Builder
 	i := 0
 
 	for i < len(s) {
 		if s[i] == '\\' && i+1 < len(s) {
 			next := s[i+1]
-			if next == '*' || next == '_' || next == '`' || next == '[' || next == ']' {
+			if next == '*' || next == '_' || next == '`' || next == '[' || next == ']' || next == '~' || next == ':' || next == '$' {
 				out.WriteByte(next)
 				i += 2
 				continue
@@ -358,16 +614,61 @@ func renderInlineMarkdown(s string) string {
 			}
 		}
 
+		if strings.HasPrefix(s[i:], "[^") {
+			if end := findClosingByte(s, i+2, ']'); end > i+2 {
+				out.WriteString(mdFootnote.Render("[" + s[i+2:end] + "]"))
+				i = end + 1
+				continue
+			}
+		}
+
+		if s[i] == '!' && i+1 < len(s) && s[i+1] == '[' {
+			if alt, url, consumed, ok := parseLink(s[i+1:]); ok {
+				out.WriteString(renderImage(alt, url, baseDir, rootDir))
+				i += 1 + consumed
+				continue
+			}
+		}
+
 		if s[i] == '[' {
 			text, url, consumed, ok := parseLink(s[i:])
 			if ok {
-				out.WriteString(mdLinkText.Render(text))
+				if isAbsoluteURL(url) {
+					out.WriteString(hyperlink(url, mdLinkText.Render(text)))
+				} else {
+					out.WriteString(mdLinkText.Render(text))
+				}
 				out.WriteString(mdLinkURL.Render(" (" + url + ")"))
 				i += consumed
 				continue
 			}
 		}
 
+		if s[i] == '$' {
+			if end := findInlineMath(s, i); end > 0 {
+				out.WriteString(renderMath(s[i+1 : end]))
+				i = end + 1
+				continue
+			}
+		}
+
+		if strings.HasPrefix(s[i:], "~~") {
+			end := findClosing(s, i+2, "~~")
+			if end > i+2 {
+				out.WriteString(mdStrike.Render(renderInline(s[i+2:end], baseDir, rootDir)))
+				i = end + 2
+				continue
+			}
+		}
+
+		if s[i] == ':' {
+			if emoji, consumed, ok := parseShortcode(s[i:]); ok {
+				out.WriteString(emoji)
+				i += consumed
+				continue
+			}
+		}
+
 		if s[i] == '*' || s[i] == '_' {
 			marker := s[i]
 
@@ -375,7 +676,7 @@ func renderInlineMarkdown(s string) string {
 				end := findClosing(s, i+2, string([]byte{marker, marker}))
 				if end > 0 {
 					inner := s[i+2 : end]
-					out.WriteString(mdBoldStyle.Render(renderInlineMarkdown(inner)))
+					out.WriteString(mdBoldStyle.Render(renderInline(inner, baseDir, rootDir)))
 					i = end + 2
 					continue
 				}
@@ -384,7 +685,7 @@ func renderInlineMarkdown(s string) string {
 			end := findClosingByte(s, i+1, marker)
 			if end > 0 && end > i+1 {
 				inner := s[i+1 : end]
-				out.WriteString(mdItalStyle.Render(renderInlineMarkdown(inner)))
+				out.WriteString(mdItalStyle.Render(renderInline(inner, baseDir, rootDir)))
 				i = end + 1
 				continue
 			}
@@ -397,6 +698,31 @@ func renderInlineMarkdown(s string) string {
 	return out.String()
 }
 
+// parseShortcode reads an :emoji: shortcode at the start of s
+func parseShortcode(s string) (emoji string, consumed int, ok bool) {
+	end := 1
+	for end < len(s) && isShortcodeByte(s[end]) {
+		end++
+	}
+	if end == 1 || end >= len(s) || s[end] != ':' {
+		return "", 0, false
+	}
+	emoji, ok = emojiShortcodes[s[1:end]]
+	return emoji, end + 1, ok
+}
+
+// parseFootnoteDef splits a footnote definition line like "[^1]: text"
+func parseFootnoteDef(s string) (label, body string, ok bool) {
+	if !strings.HasPrefix(s, "[^") {
+		return "", "", false
+	}
+	end := strings.Index(s, "]:")
+	if end < 3 {
+		return "", "", false
+	}
+	return s[2:end], strings.TrimSpace(s[end+2:]), true
+}
+
 func parseLink(s string) (text, url string, consumed int, ok bool) {
 	if len(s) == 0 || s[0] != '[' {
 		return "", "", 0, false
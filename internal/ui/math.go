@@ -0,0 +1,204 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// LaTeX in markdown ($x^2$ inline, $$ blocks) is approximated with unicode:
+// symbols and greek letters map directly, scripts use superscript and
+// subscript characters where unicode has them, and fractions become a/b.
+var mdMathStyle = lipgloss.NewStyle().Italic(true).Foreground(lipgloss.Color("252"))
+
+var latexSymbols = map[string]string{
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ε", "varepsilon": "ε",
+	"zeta": "ζ", "eta": "η", "theta": "θ", "vartheta": "ϑ", "iota": "ι", "kappa": "κ",
+	"lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ", "pi": "π", "rho": "ρ", "sigma": "σ",
+	"tau": "τ", "upsilon": "υ", "phi": "φ", "varphi": "φ", "chi": "χ", "psi": "ψ", "omega": "ω",
+	"Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ", "Pi": "Π",
+	"Sigma": "Σ", "Phi": "Φ", "Psi": "Ψ", "Omega": "Ω",
+	"times": "×", "cdot": "·", "div": "÷", "pm": "±", "mp": "∓", "ast": "∗",
+	"leq": "≤", "le": "≤", "geq": "≥", "ge": "≥", "neq": "≠", "ne": "≠", "approx": "≈",
+	"equiv": "≡", "sim": "∼", "propto": "∝", "ll": "≪", "gg": "≫",
+	"infty": "∞", "partial": "∂", "nabla": "∇", "sum": "∑", "prod": "∏", "int": "∫", "oint": "∮",
+	"in": "∈", "notin": "∉", "subset": "⊂", "subseteq": "⊆", "supset": "⊃", "cup": "∪", "cap": "∩",
+	"emptyset": "∅", "forall": "∀", "exists": "∃", "neg": "¬", "land": "∧", "lor": "∨",
+	"to": "→", "rightarrow": "→", "leftarrow": "←", "Rightarrow": "⇒", "Leftarrow": "⇐",
+	"iff": "⇔", "mapsto": "↦", "implies": "⇒",
+	"ldots": "…", "cdots": "⋯", "dots": "…", "circ": "∘", "degree": "°", "prime": "′",
+	"quad": "  ", "qquad": "    ", ",": " ", ";": " ", " ": " ", "!": "",
+	"{": "{", "}": "}", "%": "%", "$": "$", "_": "_", "&": "&", "#": "#",
+}
+
+// latexIgnored are commands that only affect sizing or spacing
+var latexIgnored = map[string]bool{
+	"left": true, "right": true, "big": true, "Big": true, "displaystyle": true,
+	"limits": true, "mathrm": true, "text": true, "textrm": true, "operatorname": true,
+	"mathit": true, "mathbf": true, "boldsymbol": true,
+}
+
+var blackboard = map[byte]string{'R': "ℝ", 'N': "ℕ", 'Z': "ℤ", 'Q': "ℚ", 'C': "ℂ", 'P': "ℙ"}
+
+var superscripts = map[rune]rune{
+	'0': '⁰', '1': '¹', '2': '²', '3': '³', '4': '⁴', '5': '⁵', '6': '⁶', '7': '⁷', '8': '⁸', '9': '⁹',
+	'+': '⁺', '-': '⁻', '=': '⁼', '(': '⁽', ')': '⁾', 'n': 'ⁿ', 'i': 'ⁱ', 'x': 'ˣ', 'y': 'ʸ',
+	'a': 'ᵃ', 'b': 'ᵇ', 'c': 'ᶜ', 'd': 'ᵈ', 'e': 'ᵉ', 'k': 'ᵏ', 'm': 'ᵐ', 'T': 'ᵀ', '′': '′',
+}
+
+var subscripts = map[rune]rune{
+	'0': '₀', '1': '₁', '2': '₂', '3': '₃', '4': '₄', '5': '₅', '6': '₆', '7': '₇', '8': '₈', '9': '₉',
+	'+': '₊', '-': '₋', '=': '₌', '(': '₍', ')': '₎', 'a': 'ₐ', 'e': 'ₑ', 'i': 'ᵢ', 'j': 'ⱼ',
+	'k': 'ₖ', 'n': 'ₙ', 'm': 'ₘ', 'o': 'ₒ', 'x': 'ₓ', 't': 'ₜ',
+}
+
+// renderMath styles a LaTeX expression converted to unicode
+func renderMath(expr string) string {
+	return mdMathStyle.Render(latexToUnicode(expr))
+}
+
+// latexToUnicode approximates a LaTeX expression in plain unicode
+func latexToUnicode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		switch c := s[i]; c {
+		case '\\':
+			name, n := latexCommand(s[i+1:])
+			i += 1 + n
+			switch {
+			case name == "frac" || name == "dfrac" || name == "tfrac":
+				num, n1 := latexGroup(s[i:])
+				den, n2 := latexGroup(s[i+n1:])
+				i += n1 + n2
+				b.WriteString(fraction(latexToUnicode(num), latexToUnicode(den)))
+			case name == "sqrt":
+				arg, n := latexGroup(s[i:])
+				i += n
+				inner := latexToUnicode(arg)
+				if len([]rune(inner)) > 1 {
+					inner = "(" + inner + ")"
+				}
+				b.WriteString("√" + inner)
+			case name == "mathbb":
+				arg, n := latexGroup(s[i:])
+				i += n
+				if len(arg) == 1 && blackboard[arg[0]] != "" {
+					b.WriteString(blackboard[arg[0]])
+				} else {
+					b.WriteString(latexToUnicode(arg))
+				}
+			case latexIgnored[name]:
+			case latexSymbols[name] != "" || name == "!":
+				b.WriteString(latexSymbols[name])
+			default:
+				b.WriteString("\\" + name)
+			}
+		case '^', '_':
+			arg, n := latexGroup(s[i+1:])
+			i += 1 + n
+			b.WriteString(script(latexToUnicode(arg), c == '^'))
+		case '{', '}':
+			i++
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// latexCommand reads a command name after a backslash: a run of letters, or
+// a single symbol as in \{ or \,
+func latexCommand(s string) (string, int) {
+	n := 0
+	for n < len(s) && (s[n] >= 'a' && s[n] <= 'z' || s[n] >= 'A' && s[n] <= 'Z') {
+		n++
+	}
+	if n == 0 && len(s) > 0 {
+		n = 1
+	}
+	return s[:n], n
+}
+
+// latexGroup reads one argument: a {braced group} or a single character
+func latexGroup(s string) (string, int) {
+	skip := len(s) - len(strings.TrimLeft(s, " "))
+	s = s[skip:]
+	if s == "" {
+		return "", skip
+	}
+	if s[0] == '\\' {
+		_, n := latexCommand(s[1:])
+		return s[:1+n], skip + 1 + n
+	}
+	if s[0] != '{' {
+		return s[:1], skip + 1
+	}
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return s[1:i], skip + i + 1
+			}
+		}
+	}
+	return s[1:], skip + len(s)
+}
+
+// script writes s as superscript or subscript characters, falling back to
+// ^(s) or _(s) when unicode has no small form for every character
+func script(s string, super bool) string {
+	table, mark := subscripts, "_"
+	if super {
+		table, mark = superscripts, "^"
+	}
+	var b strings.Builder
+	for _, r := range s {
+		small, ok := table[r]
+		if !ok {
+			if len([]rune(s)) == 1 {
+				return mark + s
+			}
+			return mark + "(" + s + ")"
+		}
+		b.WriteRune(small)
+	}
+	return b.String()
+}
+
+// fraction writes num/den, parenthesizing compound parts
+func fraction(num, den string) string {
+	wrap := func(s string) string {
+		if strings.ContainsAny(s, " +-×·") {
+			return "(" + s + ")"
+		}
+		return s
+	}
+	return wrap(num) + "/" + wrap(den)
+}
+
+// findInlineMath returns the end of an inline $...$ span starting at i, or
+// -1. Like GFM, the span can't open or close next to a space, and a closing
+// $ followed by a digit is a price, not math.
+func findInlineMath(s string, i int) int {
+	if i+1 >= len(s) || s[i+1] == ' ' || s[i+1] == '$' {
+		return -1
+	}
+	for j := i + 1; j < len(s); j++ {
+		if s[j] == '\\' {
+			j++
+			continue
+		}
+		if s[j] == '$' {
+			if s[j-1] == ' ' || (j+1 < len(s) && s[j+1] >= '0' && s[j+1] <= '9') {
+				return -1
+			}
+			return j
+		}
+	}
+	return -1
+}
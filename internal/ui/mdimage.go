@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var mdImageTag = lipgloss.NewStyle().Foreground(lipgloss.Color("139"))
+
+// renderImage shows a markdown image as its alt text plus what's known about
+// the file: format, pixel size, and file size for local images, the URL for
+// remote ones. Terminals can't show the picture itself yet, so the
+// placeholder says enough to know what's there. Site-absolute paths are
+// relative to rootDir, as links are.
+func renderImage(alt, url, baseDir, rootDir string) string {
+	url, _, _ = strings.Cut(strings.TrimSpace(url), " ")
+	if alt == "" {
+		alt = filepath.Base(url)
+	}
+	tag := mdImageTag.Render("[img] ")
+	if isAbsoluteURL(url) {
+		return tag + hyperlink(url, mdLinkText.Render(alt)) + mdLinkURL.Render(" ("+url+")")
+	}
+	if baseDir == "" {
+		return tag + mdLinkText.Render(alt) + mdLinkURL.Render(" ("+url+")")
+	}
+	path := filepath.Join(baseDir, filepath.FromSlash(url))
+	if strings.HasPrefix(url, "/") {
+		path = filepath.Join(rootDir, filepath.FromSlash(strings.TrimPrefix(url, "/")))
+	}
+	return tag + mdLinkText.Render(alt) + mdLinkURL.Render(" ("+url+" · "+imageInfo(path)+")")
+}
+
+// imageInfo describes an image file like "png 640×480, 12 KB"
+func imageInfo(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "missing"
+	}
+	desc := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	if f, err := os.Open(path); err == nil {
+		if cfg, format, err := image.DecodeConfig(f); err == nil {
+			desc = fmt.Sprintf("%s %d×%d", format, cfg.Width, cfg.Height)
+		}
+		f.Close()
+	}
+	if desc == "" {
+		return formatSize(info.Size())
+	}
+	return desc + ", " + formatSize(info.Size())
+}
+
+// formatSize renders a byte count as B, KB, or MB
+func formatSize(n int64) string {
+	switch {
+	case n < 1024:
+		return fmt.Sprintf("%d B", n)
+	case n < 1024*1024:
+		return fmt.Sprintf("%d KB", (n+512)/1024)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	}
+}
@@ -0,0 +1,209 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kateleext/perch/internal/git"
+)
+
+// mdLink is one link in a markdown preview's link index
+type mdLink struct {
+	text   string
+	target string
+	row    int // display row the link is on
+}
+
+// buildLinkIndex lists the [text](target) links of the preview as shown,
+// skipping code fences and removed lines
+func buildLinkIndex(pc *PreviewContent) []mdLink {
+	var links []mdLink
+	inFence := false
+	for i, raw := range pc.RawLines {
+		if i < len(pc.Diff) && pc.Diff[i].Type == "remove" {
+			continue
+		}
+		if fenceRegex.MatchString(raw) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		for j := 0; j < len(raw); j++ {
+			if raw[j] != '[' || (j > 0 && raw[j-1] == '\\') {
+				continue
+			}
+			text, target, consumed, ok := parseLink(raw[j:])
+			if !ok {
+				continue
+			}
+			// Titles ([text](url "title")) aren't part of the target
+			target, _, _ = strings.Cut(strings.TrimSpace(target), " ")
+			if target != "" {
+				links = append(links, mdLink{text: stripANSIColors(renderInlineMarkdown(text)), target: target, row: i})
+			}
+			j += consumed - 1
+		}
+	}
+	return links
+}
+
+// openLinks shows the links of a markdown preview to pick one to follow (l)
+func (m *Model) openLinks() {
+	if m.mode != modeFiles || len(m.files) == 0 || !isMarkdownFile(m.files[m.selected].Path) {
+		m.statusMessage = "links are for markdown files"
+		return
+	}
+	m.links = buildLinkIndex(m.displayPreview())
+	if len(m.links) == 0 {
+		m.statusMessage = "no links"
+		return
+	}
+	m.linkCursor = 0
+	m.linksActive = true
+}
+
+// updateLinks handles keys while the link index is open
+func (m Model) updateLinks(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, m.quit()
+	case "esc", "l", "q":
+		m.linksActive = false
+	case "j", "down":
+		if m.linkCursor < len(m.links)-1 {
+			m.linkCursor++
+		}
+	case "k", "up":
+		if m.linkCursor > 0 {
+			m.linkCursor--
+		}
+	case "g":
+		m.linkCursor = 0
+	case "G":
+		m.linkCursor = len(m.links) - 1
+	case "enter":
+		m.linksActive = false
+		return m, m.followLink(m.links[m.linkCursor])
+	}
+	return m, nil
+}
+
+// followLink opens a URL in the browser, jumps to a #heading in the same
+// file, or makes a linked file the preview target
+func (m *Model) followLink(link mdLink) tea.Cmd {
+	if isAbsoluteURL(link.target) || strings.HasPrefix(link.target, "mailto:") {
+		u := link.target
+		return func() tea.Msg {
+			return openedMsg{url: u, err: openURL(u)}
+		}
+	}
+
+	target, anchor, _ := strings.Cut(link.target, "#")
+	if target == "" {
+		m.jumpToAnchor(anchor)
+		return nil
+	}
+	current := m.files[m.selected].Path
+	path := filepath.Clean(filepath.Join(filepath.Dir(filepath.FromSlash(current)), filepath.FromSlash(target)))
+	if strings.HasPrefix(target, "/") {
+		// Site-absolute links are relative to the watched directory
+		path = filepath.Clean(filepath.FromSlash(strings.TrimPrefix(target, "/")))
+	}
+	if path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+		m.statusMessage = "outside " + filepath.Base(m.dir) + ": " + link.target
+		return nil
+	}
+	info, err := os.Stat(filepath.Join(m.dir, path))
+	if err != nil {
+		m.statusMessage = "no such file: " + link.target
+		return nil
+	}
+	if info.IsDir() {
+		m.statusMessage = "is a directory: " + link.target
+		return nil
+	}
+	return m.selectPath(filepath.ToSlash(path))
+}
+
+// jumpToAnchor scrolls to the heading whose GitHub-style slug is anchor
+func (m *Model) jumpToAnchor(anchor string) {
+	for _, e := range buildOutline(m.displayPreview()) {
+		if headingSlug(e.title) == strings.ToLower(anchor) {
+			m.jumpToRow(e.row)
+			return
+		}
+	}
+	m.statusMessage = "no heading #" + anchor
+}
+
+// headingSlug builds the anchor GitHub gives a heading: lower case, spaces
+// to hyphens, punctuation dropped
+func headingSlug(title string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case r == ' ':
+			b.WriteByte('-')
+		case r == '-' || r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r > 127:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// selectPath selects a file by its path relative to the watched dir. Files
+// the list doesn't have are added as a linked entry that stays until the
+// next link is followed.
+func (m *Model) selectPath(path string) tea.Cmd {
+	if m.viewFile != "" {
+		m.SetViewFile(path)
+		return m.loadFiles
+	}
+	for i, f := range m.files {
+		if f.Path == path {
+			m.selected = i
+			m.ensureSelectedVisible()
+			m.previewPending = i
+			return debouncePreviewCmd(i)
+		}
+	}
+	m.linkedFile = path
+	m.files = m.withLinkedFile(m.files)
+	m.selected = 0
+	m.ensureSelectedVisible()
+	m.previewPending = 0
+	return debouncePreviewCmd(0)
+}
+
+// withLinkedFile puts the file opened from a link at the top of a load
+// when git doesn't list it
+func (m Model) withLinkedFile(files []git.FileStatus) []git.FileStatus {
+	if m.linkedFile == "" {
+		return files
+	}
+	for _, f := range files {
+		if f.Path == m.linkedFile {
+			return files
+		}
+	}
+	return append([]git.FileStatus{m.cleanEntry(m.linkedFile)}, files...)
+}
+
+// renderLinks draws the link index in place of the preview
+func (m Model) renderLinks() string {
+	return m.renderPicker(len(m.links), m.linkCursor, func(i int, selected bool) string {
+		l := m.links[i]
+		text := l.text
+		if text == "" {
+			text = l.target
+		}
+		if selected {
+			return selectedStyle.Render(cursorMarker()+text) + dimStyle.Render("  "+l.target)
+		}
+		return "  " + text + dimStyle.Render("  "+l.target)
+	})
+}
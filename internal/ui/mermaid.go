@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Mermaid fences are summarized line by line rather than drawn: each edge
+// reads as "Start → Done", so the diagram makes sense without rendering it
+// and the preview keeps one row per source line.
+var (
+	mdMermaidBox  = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	mdMermaidNode = lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	mdMermaidEdge = lipgloss.NewStyle().Foreground(lipgloss.Color("109"))
+
+	mermaidNodeRegex  = regexp.MustCompile(`^([A-Za-z0-9_]+)(\[\[.*?\]\]|\[\(.*?\)\]|\(\(.*?\)\)|\(\[.*?\]\)|\[.*?\]|\(.*?\)|\{\{.*?\}\}|\{.*?\}|>.*?\])?`)
+	mermaidArrowRegex = regexp.MustCompile(`^\s*(<?(?:-->|---|-\.->|-\.-|==>|===|--[ox]|~~~))(?:\|([^|]*)\|)?\s*`)
+	mermaidTextArrow  = regexp.MustCompile(`^\s*(?:--|==|-\.)\s+([^>|]+?)\s+(-->|==>|\.->|---)\s*`)
+	mermaidMsgRegex   = regexp.MustCompile(`^([\w ]+?)\s*(?:--?>>|--?>|--?x|--?\))[+-]?\s*([\w ]+?)\s*:\s*(.*)$`)
+	mermaidDirections = map[string]string{"TD": "top-down", "TB": "top-down", "BT": "bottom-up", "LR": "left-right", "RL": "right-left"}
+)
+
+// mermaidState carries node labels across a fence, since a node's label is
+// usually given only where it first appears
+type mermaidState struct {
+	labels map[string]string
+}
+
+// renderMermaidLine summarizes one line of a mermaid fence
+func (ms *mermaidState) renderMermaidLine(line string) string {
+	prefix := mdMermaidBox.Render("│ ")
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return prefix
+	}
+	if summary, ok := mermaidHeader(trimmed); ok {
+		return prefix + mdMermaidEdge.Render(summary)
+	}
+	if m := mermaidMsgRegex.FindStringSubmatch(trimmed); m != nil {
+		return prefix + mdMermaidNode.Render(m[1]) + mdMermaidEdge.Render(" → ") + mdMermaidNode.Render(m[2]) + dimStyle.Render(": "+m[3])
+	}
+	if strings.HasPrefix(trimmed, "participant ") || strings.HasPrefix(trimmed, "actor ") {
+		name := strings.Fields(trimmed)[1]
+		if _, alias, ok := strings.Cut(trimmed, " as "); ok {
+			name = strings.TrimSpace(alias)
+		}
+		return prefix + dimStyle.Render("participant ") + mdMermaidNode.Render(name)
+	}
+	if summary, ok := ms.flowLine(trimmed); ok {
+		return prefix + summary
+	}
+	// Styling, subgraphs, comments: keep the source, out of the way
+	return prefix + dimStyle.Render(trimmed)
+}
+
+// mermaidHeader names the diagram a fence's first line declares
+func mermaidHeader(s string) (string, bool) {
+	fields := strings.Fields(s)
+	switch fields[0] {
+	case "graph", "flowchart":
+		if len(fields) > 1 {
+			if dir, ok := mermaidDirections[fields[1]]; ok {
+				return "flowchart, " + dir, true
+			}
+		}
+		return "flowchart", true
+	case "sequenceDiagram":
+		return "sequence diagram", true
+	case "classDiagram", "stateDiagram", "stateDiagram-v2", "erDiagram", "gantt", "pie", "journey", "gitGraph", "mindmap", "timeline":
+		return strings.TrimSuffix(fields[0], "-v2"), true
+	}
+	return "", false
+}
+
+// flowLine renders a flowchart line of nodes joined by arrows
+func (ms *mermaidState) flowLine(s string) (string, bool) {
+	var b strings.Builder
+	nodes := 0
+	for s != "" {
+		m := mermaidNodeRegex.FindStringSubmatch(s)
+		if m == nil {
+			return "", false
+		}
+		id, shape := m[1], m[2]
+		if label := mermaidLabel(shape); label != "" {
+			ms.labels[id] = label
+		}
+		label := ms.labels[id]
+		if label == "" {
+			label = id
+		}
+		b.WriteString(mdMermaidNode.Render(label))
+		nodes++
+		s = strings.TrimLeft(s[len(m[0]):], " \t;")
+		if s == "" {
+			break
+		}
+		a := mermaidArrowRegex.FindStringSubmatch(s)
+		if t := mermaidTextArrow.FindStringSubmatch(s); a == nil && t != nil {
+			// "A -- label --> B" is the same edge as "A -->|label| B"
+			a = []string{t[0], t[2], t[1]}
+		}
+		if a == nil {
+			return "", false
+		}
+		head := "→"
+		if !strings.HasSuffix(a[1], ">") {
+			head = "─"
+		}
+		arrow := " " + head + " "
+		if label := strings.TrimSpace(a[2]); label != "" {
+			arrow = " ─" + label + head + " "
+		}
+		b.WriteString(mdMermaidEdge.Render(arrow))
+		s = s[len(a[0]):]
+	}
+	if nodes == 0 {
+		return "", false
+	}
+	return b.String(), true
+}
+
+// mermaidLabel pulls the text out of a node shape like [Start] or {Ok?}
+func mermaidLabel(shape string) string {
+	label := strings.Trim(shape, "[](){}>")
+	return strings.Trim(strings.TrimSpace(label), `"`)
+}
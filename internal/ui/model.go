@@ -2,11 +2,13 @@ package ui
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/alecthomas/chroma/v2"
 	"github.com/alecthomas/chroma/v2/formatters"
@@ -15,7 +17,15 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/kateleext/perch/internal/clipboard"
+	"github.com/kateleext/perch/internal/control"
+	"github.com/kateleext/perch/internal/debuglog"
+	"github.com/kateleext/perch/internal/gh"
 	"github.com/kateleext/perch/internal/git"
+	"github.com/kateleext/perch/internal/hooks"
+	"github.com/kateleext/perch/internal/ignore"
+	"github.com/kateleext/perch/internal/snapshot"
+	"github.com/kateleext/perch/internal/state"
 )
 
 // DevBuild indicates if this is a development build
@@ -38,18 +48,9 @@ func stripANSIColors(s string) string {
 	var result strings.Builder
 	i := 0
 	for i < len(s) {
-		if i+1 < len(s) && s[i] == 0x1b && s[i+1] == '[' {
-			// Skip ANSI sequence
-			j := i + 2
-			for j < len(s) {
-				b := s[j]
-				if b >= 0x40 && b <= 0x7E {
-					j++
-					break
-				}
-				j++
-			}
-			i = j
+		if isANSIStart(s, i) {
+			// Skip ANSI sequence (CSI or OSC)
+			i = skipANSI(s, i)
 		} else {
 			result.WriteByte(s[i])
 			i++
@@ -69,6 +70,7 @@ var (
 	lineDelGutter  = lipgloss.NewStyle().Foreground(lipgloss.Color("#8a5a5a")) // muted red, blends with bg
 	lineDotStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("238"))     // very subtle dots
 	sparkleStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("255"))     // white sparkle
+	flashStyle     = lipgloss.NewStyle().Reverse(true).Foreground(lipgloss.Color("109")) // header flash on change
 )
 
 // TickMsg for sparkle animation
@@ -79,10 +81,27 @@ type previewRequestMsg struct {
 	selectedIndex int
 }
 
+// resizeSettledMsg fires once the terminal size has stopped changing
+type resizeSettledMsg struct {
+	seq int
+}
+
+// resizeSettleDelay is how long the size must hold before the preview is
+// laid out again; dragging a window corner sends a burst of sizes
+const resizeSettleDelay = 80 * time.Millisecond
+
 // previewLoadedMsg carries the async-loaded preview content
 type previewLoadedMsg struct {
 	selectedIndex int
 	preview       PreviewContent
+	took          time.Duration
+}
+
+// previewPreloadedMsg carries a preview built ahead of time for a file next
+// to the selection
+type previewPreloadedMsg struct {
+	file    git.FileStatus
+	preview PreviewContent
 }
 
 // PreviewContent holds the rendered preview data for a specific file
@@ -91,14 +110,29 @@ type PreviewContent struct {
 	Message          string
 	RawLines         []string
 	HighlightedLines []string
-	DiffLines        map[int]string
+	Diff             []git.DiffLine // one record per display row
 	DiffStats        git.DiffStats
 	WrappedByWidth   map[int][]VisualLine
+	indexByWidth     map[int]*wrapIndex // row counts per wrap width; rows wrap as shown
+	Encoding         string // detected encoding when the file isn't UTF-8
+	LineEnding       string // "CRLF" or "mixed" when the file isn't plain LF
+	BOM              bool   // file starts with a UTF-8 byte order mark
+	Masked           int    // rows whose secret values are hidden
+	Secrets          map[int]string // added rows that look like secrets, by kind
+	DeletedFrom      string          // "HEAD" or "index" when previewing a deleted file
+	Symlink          string          // "symlink → target" when the file is a symlink
+	Hash             uint64          // fingerprint of the content and diff the preview was built from
+	modTime          time.Time       // file mtime when built, for validating cached uncommitted previews
+	diffHash         uint64          // fingerprint of the diff alone, likewise
+	revealed         *PreviewContent // unmasked version of a masked preview
+	collapsed        *PreviewContent // diff-only version, built on demand
+	collapsedContext int             // context lines collapsed was built with
 }
 
 // ResetWrapCache clears the cached wrapped lines
 func (pc *PreviewContent) ResetWrapCache() {
 	pc.WrappedByWidth = make(map[int][]VisualLine)
+	pc.indexByWidth = make(map[int]*wrapIndex)
 }
 
 // WrappedLinesForWidth returns wrapped lines for a given width, using cache
@@ -109,11 +143,40 @@ func (pc *PreviewContent) WrappedLinesForWidth(width int) []VisualLine {
 	if lines, ok := pc.WrappedByWidth[width]; ok {
 		return lines
 	}
-	lines := wrapAllLines(pc.HighlightedLines, pc.RawLines, pc.DiffLines, width)
+	lines := pc.wrapIndexForWidth(width).all()
 	pc.WrappedByWidth[width] = lines
 	return lines
 }
 
+// wrapIndexForWidth returns the row index for a given width, using cache
+func (pc *PreviewContent) wrapIndexForWidth(width int) *wrapIndex {
+	if pc.indexByWidth == nil {
+		pc.indexByWidth = make(map[int]*wrapIndex)
+	}
+	if idx, ok := pc.indexByWidth[width]; ok {
+		return idx
+	}
+	idx := newWrapIndex(pc.HighlightedLines, pc.RawLines, pc.Diff, width)
+	pc.indexByWidth[width] = idx
+	return idx
+}
+
+// viewMode selects what the top pane lists
+type viewMode int
+
+const (
+	modeFiles viewMode = iota // changed files (default)
+	modeStash                 // stash browser
+	modeWorktrees             // sibling worktree picker
+	modeSummary               // today's progress summary
+	modeChurn                 // files ranked by change frequency
+	modeLog                   // commit log of the current branch
+	modeCommitFiles           // files changed by one commit
+	modeCompare               // one file diffed between two commits
+	modeDiffstat              // per-file line counts of the working tree
+	modeTimeline              // recorded versions of one file
+)
+
 // Model is the main bubbletea model
 type Model struct {
 	files            []git.FileStatus
@@ -133,28 +196,191 @@ type Model struct {
 	loadingFrame     int  // track animation frame for loading screen
 	loadingStartTime time.Time // track when loading started
 	previewPending   int  // index of pending preview request (-1 = none)
-	previewCache     map[string]PreviewContent // cache by file path
+	previewCancel    context.CancelFunc // aborts the async preview load in flight
+	perf             *perfStats         // timings for the --perf overlay
+	resizeSeq        int  // bumped by each resize; only the last settles the preview layout
+	previewCache     *previewCache // LRU by file path; uncommitted entries are checked before use
+	seen             seenFiles     // mtime of each file when last previewed, for unseen badges
+	snapshots        *snapshot.Store // local history of dirty files; nil when off
+	timeline         timelineView
+	snapshotWarned   bool // a snapshot failure has been shown
+	statusMessage    string // transient status shown in the footer (e.g. git timeouts)
+	statusUntil      time.Time // statusMessage survives refreshes until then
+	mode             viewMode
+	stashes          []git.StashEntry
+	stashSelected    int
+	stashScroll      int
+	confirmDrop      bool // true after the first "d" in the stash browser
+	commits          []git.Commit
+	commitSelected   int
+	commitScroll     int
+	drill            commitDrill // commit opened up into its files
+	compare          comparePicker
+	worktrees        []git.Worktree
+	worktreeSelected int
+	linkedWorktree   bool // true when dir is a linked (non-main) worktree
+	plainDir         bool // dir isn't a git repo; files are listed by mtime alone
+	scalePrompt      string // why the repo looks enormous, while asking whether to scope the scan
+	scaleChecked     bool   // the first scan has been checked for an enormous repo
+	scoped           bool   // the scan was narrowed to recently changed paths
+	unscopedInclude  []string // --include patterns from before the scan was scoped
+	savedFilters     bool     // --include/--exclude came from the last session
+	scanGen          int    // bumped when the scan is narrowed; older loads are dropped
+	showTips         bool   // first-run card of core keys, until a key is pressed
+	restorePath      string // file to reselect from the saved session
+	restoreOffset    int    // viewport offset to restore for restorePath
+	follow           bool      // follow mode: jump to whichever file changed last
+	followStamp      time.Time // mod time of the newest file already followed
+	gotoActive       bool      // true while the ":" goto-line prompt is open
+	gotoInput        string    // digits typed into the goto-line prompt
+	amendActive      bool      // true while the amend prompt is open
+	amendInput       string    // subject line being edited in the amend prompt
+	amendInfo        git.AmendInfo
+	amendScopes      []string   // recent scopes tab completes, with Conventional on
+	amendCompletion  completion // tab's place among the current matches
+	amendError       string     // why enter was refused, until the next key
+	showWhitespace   bool      // mark tabs, trailing whitespace, and mixed indents
+	revealSecrets    bool      // show the masked values of a sensitive file
+	diffOnly         bool      // show only changed hunks (with context) in the preview
+	diffContext      int       // context lines around each hunk in diff-only view
+	visualActive     bool      // true while selecting preview rows with v
+	visualAnchor     int       // wrapped row where the selection started
+	visualCursor     int       // wrapped row the selection extends to
+	exportActive     bool      // true while the patch path prompt is open
+	outlineActive    bool           // true while the markdown heading outline is open
+	outline          []outlineEntry // headings of the previewed markdown file
+	outlineCursor    int            // highlighted heading in the outline
+	linksActive      bool           // true while the markdown link index is open
+	links            []mdLink       // links of the previewed markdown file
+	linkCursor       int            // highlighted link in the index
+	linkedFile       string         // unlisted file opened from a link, kept in the list
+	exportAll        bool      // export the whole working tree, not just the selection
+	exportInput      string    // path typed into the patch prompt
+	pr               *gh.PRStatus // current branch's pull request, nil if none
+	sync             syncState    // commits to push/pull (with --fetch or the status line)
+	repoStatus       repoStatus   // branch and stash count for the status line
+	blurred          bool         // terminal reported focus loss
+	lastNotify       time.Time    // when the last desktop notification fired
+	flashing         bool         // header is briefly highlighted after a change
+	summary          git.DaySummary // today's activity for the summary view
+	churn            []git.FileActivity // files ranked by commits in the churn window
+	churnCommits     int                // commits in the churn window
+	churnDays        int                // churn window length
+	diffstat         []git.FileActivity // working tree changes against HEAD, path order
+	diffstatSelected int
+	sizeSamples      []int              // working-tree diff size (added+deleted) over the session
+	treeStats        git.DiffStats      // latest working-tree totals
+	run              runState           // --on-change command status and output
+	lastHead         string             // HEAD at the last load, for commit-created hooks and the preview cache
+	statusSource     StatusSource       // file list provider (git.GetStatus unless embedded)
+	embedded         bool               // running as a pane inside another program
+	announcement     string             // last selection change, read out in Accessible mode
+	singlePreview    bool               // single-pane flow is showing the preview, not the list
+	zen              bool               // file list hidden, preview fills the screen
+	viewFile         string             // `perch view` target; the list holds only this file
+	tail             bool               // preview stays pinned to the bottom as the file grows
 }
 
+// RestoreFilters applies the last session's --include/--exclude patterns;
+// it is off when either flag is given, so `--include=` clears them
+var RestoreFilters = true
+
 // New creates a new UI model
 func New(dir string) Model {
-	gitRoot, _ := git.GetGitRoot(dir)
-	return Model{
+	return newModel(dir, false)
+}
+
+func newModel(dir string, embedded bool) Model {
+	gitRoot, _ := git.GetGitRoot(context.Background(), dir)
+	m := Model{
 		dir:              dir,
 		gitRoot:          gitRoot,
+		linkedWorktree:   git.IsLinkedWorktree(context.Background(), dir),
 		listHeight:       8,
 		preview:          PreviewContent{},
 		viewport:         viewport.New(80, 10),
 		loading:          true, // Start in loading state
 		loadingStartTime: time.Now(),
 		previewPending:   -1,
-		previewCache:     make(map[string]PreviewContent),
+		previewCache:     newPreviewCache(previewCacheLines),
+		perf:             &perfStats{},
+		diffContext:      DiffContext,
+		showTips:         !embedded && state.FirstRun(), // the host program explains itself
+		embedded:         embedded,
+	}
+	if embedded {
+		return m
+	}
+	if Snapshots {
+		root := gitRoot
+		if root == "" {
+			root = dir
+		}
+		var err error
+		if m.snapshots, err = snapshot.Open(root); err != nil {
+			debuglog.Printf("snapshots disabled: %v", err)
+			m.statusMessage = "snapshots are off: " + err.Error()
+			m.statusUntil = time.Now().Add(toastFor)
+			m.snapshotWarned = true
+		}
+	}
+
+	if session, ok := state.Load(dir); ok {
+		m.restorePath = session.SelectedPath
+		m.restoreOffset = session.ViewportOffset
+		if session.ListHeight >= 3 {
+			m.listHeight = session.ListHeight
+		}
+		// --include/--exclude on the command line, even empty, win over
+		// the saved ones
+		saved := git.Pathspec{Include: session.Include, Exclude: session.Exclude}
+		if RestoreFilters && !saved.IsEmpty() {
+			git.SetPathspec(saved)
+			m.savedFilters = true
+		}
+		m.diffOnly = session.DiffOnly
+		m.follow = session.Follow
+		m.showWhitespace = session.ShowWhitespace
+		m.tail = session.Tail
+	}
+	return m
+}
+
+// SaveSession persists the selection, scroll, layout, filters, and view
+// toggles for the next launch
+func (m Model) SaveSession() error {
+	if m.embedded {
+		return nil
+	}
+	spec := git.ActivePathspec()
+	session := state.Session{
+		Dir:            m.dir,
+		ViewportOffset: m.viewport.YOffset,
+		ListHeight:     m.listHeight,
+		Include:        spec.Include,
+		Exclude:        spec.Exclude,
+		DiffOnly:       m.diffOnly,
+		Follow:         m.follow,
+		ShowWhitespace: m.showWhitespace,
+		Tail:           m.tail,
+	}
+	// A scan scoped to recent changes is for this run only
+	if m.scoped {
+		session.Include = m.unscopedInclude
 	}
+	if m.selected >= 0 && m.selected < len(m.files) {
+		session.SelectedPath = m.files[m.selected].Path
+	}
+	return state.Save(session)
 }
 
 // RefreshMsg tells the model to refresh files
 type RefreshMsg struct{}
 
+// FileChangedMsg tells the model the watcher saw files change: it refreshes
+// and starts the on-change command
+type FileChangedMsg struct{}
+
 func tickCmd() tea.Cmd {
 	return tea.Tick(2*time.Second, func(t time.Time) tea.Msg {
 		return TickMsg(t)
@@ -170,27 +396,263 @@ func debouncePreviewCmd(selectedIndex int) tea.Cmd {
 
 // Init implements tea.Model
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(m.loadFiles, tickCmd())
+	cmds := []tea.Cmd{m.loadFiles, tickCmd()}
+	if m.plainDir {
+		return tea.Batch(cmds...)
+	}
+	cmds = append(cmds, slowScanCmd())
+	if gh.Available() {
+		cmds = append(cmds, m.loadPRStatus)
+	}
+	if FetchInterval > 0 {
+		cmds = append(cmds, m.fetchCmd)
+	}
+	if WIPInterval > 0 {
+		cmds = append(cmds, wipTickCmd())
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m Model) loadFiles() tea.Msg {
-	files, _ := git.GetStatus(m.dir)
-	return filesLoadedMsg{files: files}
+	source := m.statusSource
+	if source == nil {
+		source = git.GetStatus
+	}
+	start := time.Now()
+	files, err := source(context.Background(), m.dir)
+	if m.viewFile != "" && err == nil {
+		files = m.pinFile(files)
+	} else if err == nil {
+		files = m.withLinkedFile(files)
+	}
+	msg := filesLoadedMsg{files: files, err: err, gen: m.scanGen}
+	if m.plainDir {
+		msg.took = time.Since(start)
+		return msg
+	}
+	msg.treeStats, msg.treeStatsErr = git.GetWorkingTreeStats(context.Background(), m.dir)
+	if FetchInterval > 0 || StatusLine != "" {
+		msg.sync = loadSync(m.dir)
+	}
+	if StatusLine != "" {
+		msg.repo = loadRepoStatus(m.dir)
+	}
+	msg.head, msg.headSubject, _ = git.GetHeadSubject(context.Background(), m.dir)
+	msg.took = time.Since(start)
+	return msg
+}
+
+// copiedMsg reports the result of a clipboard copy
+type copiedMsg struct {
+	what string // description for the footer, e.g. the copied path
+	err  error
+}
+
+// copyCmd copies text to the clipboard in the background
+func copyCmd(text string) tea.Cmd {
+	return copyTextCmd(text, text)
+}
+
+// copyTextCmd copies text, reporting it in the footer as what
+func copyTextCmd(text, what string) tea.Cmd {
+	return func() tea.Msg {
+		return copiedMsg{what: what, err: clipboard.Copy(text)}
+	}
 }
 
 type filesLoadedMsg struct {
 	files []git.FileStatus
+	err   error
+	sync  syncState  // ahead/behind upstream, read when fetching or showing the status line
+	repo  repoStatus // branch and stashes, read only for the status line
+
+	treeStats    git.DiffStats // total working-tree changes, for the sparkline
+	treeStatsErr error
+
+	head        string // HEAD hash, for commit-created hooks and the preview cache
+	headSubject string
+
+	took time.Duration // how long the git calls took, for the --perf overlay
+	gen  int           // scanGen when the load started
 }
 
 // Update implements tea.Model
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
+	logMsg(msg)
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// Embedded in another program, quitting is the host's call
+		if m.embedded && msg.String() == "ctrl+c" {
+			return m, nil
+		}
+		if m.scalePrompt != "" {
+			return m.updateScalePrompt(msg)
+		}
+		if m.showTips && !m.loading {
+			return m.updateTips(msg)
+		}
+		if m.gotoActive {
+			return m.updateGoto(msg)
+		}
+		if m.amendActive {
+			return m.updateAmend(msg)
+		}
+		if m.visualActive {
+			return m.updateVisual(msg)
+		}
+		if m.exportActive {
+			return m.updateExport(msg)
+		}
+		if m.outlineActive {
+			return m.updateOutline(msg)
+		}
+		if m.linksActive {
+			return m.updateLinks(msg)
+		}
+		if m.embedded && msg.String() == "q" {
+			return m, nil
+		}
+		if m.singlePane() && msg.String() == "tab" {
+			m.toggleSinglePane()
+			return m, nil
+		}
+		switch m.mode {
+		case modeStash:
+			return m.updateStash(msg)
+		case modeWorktrees:
+			return m.updateWorktrees(msg)
+		case modeSummary:
+			return m.updateSummary(msg)
+		case modeChurn:
+			return m.updateChurn(msg)
+		case modeLog:
+			return m.updateLog(msg)
+		case modeCommitFiles:
+			return m.updateCommitFiles(msg)
+		case modeCompare:
+			return m.updateCompare(msg)
+		case modeDiffstat:
+			return m.updateDiffstat(msg)
+		case modeTimeline:
+			return m.updateTimeline(msg)
+		}
 		switch msg.String() {
 		case "q", "ctrl+c":
-			return m, tea.Quit
+			return m, m.quit()
+		case "t":
+			m.toggleTail()
+		case "m":
+			m.toggleSecrets()
+		case "z":
+			m.zen = !m.zen
+			m.recalculateViewport()
+		case "[":
+			return m, m.cycleFile(-1)
+		case "]":
+			return m, m.cycleFile(1)
+		case "enter":
+			if m.singlePane() && !m.singlePreview {
+				m.toggleSinglePane()
+			} else {
+				return m, m.drillSelectedFile()
+			}
+		case "esc":
+			if m.singlePane() && m.singlePreview {
+				m.toggleSinglePane()
+			}
+		case "s":
+			return m, m.enterStashMode()
+		case "Z":
+			if !m.plainDir {
+				return m, m.quickStash()
+			}
+		case "M":
+			if !m.plainDir {
+				return m, m.checkAmend
+			}
+		case "P":
+			if !m.plainDir {
+				return m, m.quickPop()
+			}
+		case "b":
+			return m, m.enterCompareMode()
+		case "A":
+			if n := m.markAllSeen(); n == 1 {
+				m.statusMessage = "marked 1 file reviewed"
+			} else {
+				m.statusMessage = fmt.Sprintf("marked %d files reviewed", n)
+			}
+		case "T":
+			return m, m.enterTimelineMode()
+		case "D":
+			if !m.plainDir {
+				return m, m.enterDiffstatMode()
+			}
+		case "C":
+			return m, m.enterLogMode()
+		case "w":
+			return m, m.enterWorktreeMode()
+		case "S":
+			return m, m.enterSummaryMode()
+		case "R":
+			// Expand/collapse the --on-change output pane
+			if OnChange != "" {
+				m.run.paneOpen = !m.run.paneOpen
+				m.recalculateViewport()
+			}
+		case "H":
+			return m, m.enterChurnMode()
+		case "f":
+			// Toggle follow mode; turning it on jumps to the newest change
+			m.follow = !m.follow
+			if m.follow {
+				m.statusMessage = "following changes"
+				if len(m.files) > 0 {
+					m.followStamp = m.files[0].ModTime
+					if m.selected != 0 {
+						m.selected = 0
+						m.listScroll = 0
+						m.previewPending = m.selected
+						cmds = append(cmds, debouncePreviewCmd(m.selected))
+					}
+				}
+			} else {
+				m.statusMessage = "stopped following"
+			}
+		case "y", "Y":
+			// Copy the selected path: y relative, Y absolute
+			if m.selected >= 0 && m.selected < len(m.files) {
+				path := m.files[m.selected].Path
+				if msg.String() == "Y" {
+					path = filepath.Join(m.dir, path)
+				}
+				return m, copyCmd(path)
+			}
+		case "c":
+			return m, m.copyHunkCmd()
+		case "e", "E":
+			// Export the selected file's diff (E: the whole tree) to a patch
+			m.startExport(msg.String() == "E")
+		case "O":
+			return m, m.openRemoteCmd()
+		case "L":
+			return m, m.copyPermalinkCmd()
+		case "v":
+			m.enterVisual()
+			m.viewport.SetContent(m.renderPreviewContent())
+		case "x":
+			// Hide the selected file via .perchignore
+			if m.selected >= 0 && m.selected < len(m.files) {
+				path := filepath.ToSlash(m.files[m.selected].Path)
+				if err := ignore.AppendPattern(m.dir, "/"+path); err != nil {
+					m.statusMessage = "couldn't hide " + path + ": " + err.Error()
+				} else {
+					m.statusMessage = "hid " + path + " (edit " + ignore.FileName + " to undo)"
+				}
+				return m, m.loadFiles
+			}
 		case "up":
 			if m.selected > 0 {
 				m.selected--
@@ -201,13 +663,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.listScroll = 0
 					}
 				}
-				m.previewPending = m.selected
-				cmds = append(cmds, debouncePreviewCmd(m.selected))
+				cmds = append(cmds, m.previewCmd())
 			}
 		case "down":
 			if m.selected < len(m.files)-1 {
 				m.selected++
-				visibleCapacity := m.listHeight - 3
+				visibleCapacity := m.listRows() - 3
 				if visibleCapacity < 1 {
 					visibleCapacity = 1
 				}
@@ -218,8 +679,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if m.selected >= m.listScroll+visibleCapacity-bottomBuffer {
 					m.listScroll = m.selected - visibleCapacity + bottomBuffer + 1
 				}
-				m.previewPending = m.selected
-				cmds = append(cmds, debouncePreviewCmd(m.selected))
+				cmds = append(cmds, m.previewCmd())
 			}
 		case "j":
 			m.viewport.LineDown(1)
@@ -229,18 +689,50 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.viewport.GotoTop()
 		case "G":
 			m.viewport.GotoBottom()
+		case ":":
+			m.gotoActive = true
+			m.gotoInput = ""
+		case "o":
+			m.openOutline()
+		case "l":
+			m.openLinks()
+		case "W":
+			m.showWhitespace = !m.showWhitespace
+			m.viewport.SetContent(m.renderPreviewContent())
+		case "d":
+			// Toggle between the full file and changed hunks only
+			m.diffOnly = !m.diffOnly
+			m.viewport.SetContent(m.renderPreviewContent())
+			m.scrollToFirstDiff()
 		case "ctrl+d":
 			m.viewport.HalfViewDown()
 		case "ctrl+u":
 			m.viewport.HalfViewUp()
 		case "+", "=":
+			// In diff-only view, +/- adjust context lines instead of the list
+			if m.diffOnly {
+				m.diffContext++
+				m.statusMessage = contextMessage(m.diffContext)
+				m.viewport.SetContent(m.renderPreviewContent())
+				break
+			}
 			if m.listHeight < m.height-10 {
 				m.listHeight++
 				m.recalculateViewport()
 			}
 		case "-", "_":
-			if m.listHeight > 3 {
-				m.listHeight--
+			if m.diffOnly {
+				if m.diffContext > 0 {
+					m.diffContext--
+				}
+				m.statusMessage = contextMessage(m.diffContext)
+				m.viewport.SetContent(m.renderPreviewContent())
+				break
+			}
+			// Shrink from what's shown, not a taller choice a short
+			// terminal is hiding
+			if h := min(m.listHeight, m.listRows()); h > 3 {
+				m.listHeight = h - 1
 				m.recalculateViewport()
 			}
 		case "shift+up":
@@ -256,34 +748,130 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.MouseMsg:
 		switch msg.Type {
 		case tea.MouseWheelUp:
-			if msg.Y > m.listHeight+3 {
+			if m.overPreview(msg.Y) {
 				m.viewport.LineUp(3)
 			}
 		case tea.MouseWheelDown:
-			if msg.Y > m.listHeight+3 {
+			if m.overPreview(msg.Y) {
 				m.viewport.LineDown(3)
 			}
 		}
 
 	case tea.WindowSizeMsg:
+		rewrap := m.width != 0 && msg.Width != m.width
 		m.width = msg.Width
 		m.height = msg.Height
-		m.recalculateViewport()
+		if !rewrap {
+			m.recalculateViewport()
+			break
+		}
+		// Everything but the preview's wrapping follows the new size at once;
+		// the preview keeps its last width (cropped) until the size settles
+		m.layoutViewport(m.viewport.Width)
+		m.resizeSeq++
+		seq := m.resizeSeq
+		cmds = append(cmds, tea.Tick(resizeSettleDelay, func(time.Time) tea.Msg {
+			return resizeSettledMsg{seq: seq}
+		}))
+
+	case slowScanMsg:
+		if m.loading && !m.scaleChecked {
+			m.scaleChecked = true
+			m.scalePrompt = fmt.Sprintf("the first git status is still running after %s", slowScanAfter)
+		}
+
+	case resizeSettledMsg:
+		if msg.seq == m.resizeSeq {
+			m.recalculateViewport()
+		}
 
 	case filesLoadedMsg:
+		if msg.gen != m.scanGen {
+			return m, nil
+		}
 		m.loading = false
-		
+		m.perf.refresh = msg.took
+		if !m.scaleChecked {
+			m.scaleChecked = true
+			m.scalePrompt = scaleReason(msg.files, msg.took)
+		}
+
+		// A timed-out refresh keeps the previous list and says why
+		if errors.Is(msg.err, git.ErrTimeout) {
+			m.statusMessage = msg.err.Error()
+			return m, nil
+		}
+		if time.Now().After(m.statusUntil) {
+			m.statusMessage = ""
+		}
+		m.sync = msg.sync
+		m.repoStatus = msg.repo
+		if m.seen == nil && msg.err == nil {
+			m.seen = seedSeen(msg.files)
+		}
+		if msg.treeStatsErr == nil {
+			m.recordSizeSample(msg.treeStats)
+		}
+
 		// Remember if we were at the top file
 		wasAtTop := m.selected == 0
-		
+
+		// In follow mode, a newer change pulls the selection to the top
+		// (files are sorted newest first)
+		if m.follow && len(msg.files) > 0 && msg.files[0].ModTime.After(m.followStamp) {
+			m.followStamp = msg.files[0].ModTime
+			wasAtTop = true
+		}
+
 		// Remember currently selected file path to preserve selection
 		var selectedPath string
 		if m.selected >= 0 && m.selected < len(m.files) {
 			selectedPath = m.files[m.selected].Path
 		}
-		
+
+		// On the first load, reselect the file from the saved session
+		if m.restorePath != "" && m.files == nil {
+			selectedPath = m.restorePath
+			wasAtTop = false
+		}
+
+		// Notify and alert about files that changed since the last load (not the first)
+		if m.files != nil {
+			changed := changedFiles(m.files, msg.files)
+			for _, f := range changed {
+				m.previewCache.remove(f.Path)
+			}
+			cmds = append(cmds, m.notifyCmd(changed), m.alertCmd(changed), m.changeDetectedHook(changed))
+		}
+
+		// A new HEAD since the last load means a commit was created (or a
+		// checkout), so cached previews of committed files may be stale
+		headChanged := false
+		if msg.head != "" {
+			if m.lastHead != "" && msg.head != m.lastHead {
+				headChanged = true
+				m.previewCache.clear()
+				cmds = append(cmds, m.hookCmd(hooks.CommitCreated, map[string]any{
+					"commit":   msg.head,
+					"subject":  msg.headSubject,
+					"previous": m.lastHead,
+				}))
+			}
+			m.lastHead = msg.head
+		}
+
+		// With reduced motion, a refresh that found nothing new changes
+		// nothing, except the preview when a new HEAD made it stale
+		if ReduceMotion && m.files != nil && sameFiles(m.files, msg.files) {
+			if headChanged {
+				m.lastSelectedFile = -1
+				m.updatePreviewKeepScroll(true)
+			}
+			return m, tea.Batch(cmds...)
+		}
+
 		m.files = msg.files
-		
+
 		// If we were at top, stay at top (auto-select newest)
 		// Otherwise, try to keep selection on the same file
 		newSelected := 0
@@ -303,71 +891,395 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 		m.selected = newSelected
-		
+
 		if m.selected >= len(m.files) {
 			m.selected = len(m.files) - 1
 		}
 		if m.selected < 0 {
 			m.selected = 0
 		}
-		
+		m.ensureSelectedVisible()
+
 		// Refresh preview content (for updated diffs) but preserve scroll if same file
 		m.lastSelectedFile = -1
 		m.updatePreviewKeepScroll(sameFile)
+		cmds = append(cmds, m.preloadAdjacent(), m.snapshotCmd(m.files))
 
 	case RefreshMsg:
 		return m, m.loadFiles
 
+	case FileChangedMsg:
+		return m, tea.Batch(m.loadFiles, m.startRun())
+
+	case runDoneMsg:
+		cmds = append(cmds, m.finishRun(msg))
+
+	case ControlMsg:
+		var resp control.Response
+		var cmd tea.Cmd
+		m, resp, cmd = m.handleControl(msg)
+		msg.Reply <- resp
+		return m, cmd
+
+	case flashEndMsg:
+		m.flashing = false
+
+	case tea.FocusMsg:
+		m.blurred = false
+
+	case tea.BlurMsg:
+		m.blurred = true
+
+	case prStatusMsg:
+		if msg.dir == m.dir {
+			m.pr = msg.pr
+		}
+		return m, prTickCmd()
+
+	case prTickMsg:
+		return m, m.loadPRStatus
+
+	case fetchTickMsg:
+		return m, m.fetchCmd
+
+	case wipTickMsg:
+		return m, m.saveWIP
+
+	case wipSavedMsg:
+		if msg.err != nil {
+			m.statusMessage = "wip: " + msg.err.Error()
+		}
+		return m, wipTickCmd()
+
+	case fetchDoneMsg:
+		if msg.dir == m.dir {
+			m.sync = msg.sync
+			if msg.err != nil {
+				m.statusMessage = msg.err.Error()
+			}
+		}
+		return m, fetchTickCmd()
+
+	case openedMsg:
+		if msg.err != nil {
+			m.statusMessage = "couldn't open: " + msg.err.Error()
+		} else {
+			m.statusMessage = "opened " + msg.url
+		}
+
+	case exportedMsg:
+		if msg.err != nil {
+			m.statusMessage = "couldn't export: " + msg.err.Error()
+		} else {
+			m.statusMessage = "wrote patch to " + msg.path
+		}
+
+	case copiedMsg:
+		if msg.err != nil {
+			m.statusMessage = "couldn't copy: " + msg.err.Error()
+		} else {
+			m.statusMessage = "copied " + msg.what
+		}
+
 	case TickMsg:
-		m.sparkleOn = !m.sparkleOn
+		m.sparkleOn = !m.sparkleOn && animate()
 		// Increment animation frame during loading
-		if m.loading {
+		if m.loading && animate() {
 			m.loadingFrame++
 		}
-		// Refresh files and diffs every tick
+		// Refresh files and diffs every tick, once the first load is done
+		if m.loading {
+			return m, tickCmd()
+		}
 		return m, tea.Batch(tickCmd(), m.loadFiles)
 
+	case stashesLoadedMsg:
+		if m.mode != modeStash {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.statusMessage = msg.err.Error()
+		}
+		m.stashes = msg.stashes
+		if m.stashSelected >= len(m.stashes) {
+			m.stashSelected = len(m.stashes) - 1
+		}
+		if m.stashSelected < 0 {
+			m.stashSelected = 0
+		}
+		if len(m.stashes) == 0 {
+			m.preview = PreviewContent{Valid: true, Message: "no stashes"}
+			m.viewport.SetContent(m.renderPreviewContent())
+			return m, nil
+		}
+		return m, m.loadStashPreview()
+
+	case summaryLoadedMsg:
+		if m.mode != modeSummary {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.statusMessage = msg.err.Error()
+		}
+		m.summary = msg.summary
+		m.preview = m.buildSummaryPreview(msg.summary)
+		m.viewport.SetContent(m.renderPreviewContent())
+		m.viewport.GotoTop()
+
+	case churnLoadedMsg:
+		if m.mode != modeChurn || msg.days != m.churnDays {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.statusMessage = msg.err.Error()
+		}
+		m.churn = msg.files
+		m.churnCommits = msg.commits
+		m.preview = m.buildChurnPreview(msg.files)
+		m.viewport.SetContent(m.renderPreviewContent())
+		m.viewport.GotoTop()
+
+	case worktreesLoadedMsg:
+		if m.mode != modeWorktrees {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.statusMessage = msg.err.Error()
+		}
+		m.worktrees = msg.worktrees
+		m.worktreeSelected = 0
+		for i, wt := range m.worktrees {
+			if wt.Current {
+				m.worktreeSelected = i
+			}
+		}
+		m.showWorktreePreview()
+
+	case commitsLoadedMsg:
+		if m.mode != modeLog {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.statusMessage = msg.err.Error()
+		}
+		m.commits = msg.commits
+		if len(m.commits) == 0 {
+			m.preview = PreviewContent{Valid: true, Message: "no commits"}
+			m.viewport.SetContent(m.renderPreviewContent())
+			return m, nil
+		}
+		return m, m.loadCommitPreview()
+
+	case commitPreviewMsg:
+		if m.mode != modeLog || m.commitSelected >= len(m.commits) || m.commits[m.commitSelected].Hash != msg.hash {
+			return m, nil
+		}
+		m.preview = msg.preview
+		m.viewport.SetContent(m.renderPreviewContent())
+		m.viewport.GotoTop()
+
+	case commitFilesLoadedMsg:
+		if m.mode != modeCommitFiles || msg.hash != m.drill.commit.Hash {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.statusMessage = msg.err.Error()
+		}
+		m.drill.commit = msg.commit
+		m.drill.files = msg.files
+		for i, f := range msg.files {
+			if f.Path == m.drill.focus {
+				m.drill.selected = i
+				if visible := m.listRows() - 1; i >= visible {
+					m.drill.scroll = i - visible + 1
+				}
+			}
+		}
+		if len(msg.files) == 0 {
+			m.preview = PreviewContent{Valid: true, Message: "no files"}
+			m.viewport.SetContent(m.renderPreviewContent())
+			return m, nil
+		}
+		return m, m.loadCommitFilePreview()
+
+	case commitFilePreviewMsg:
+		d := m.drill
+		if m.mode != modeCommitFiles || d.commit.Hash != msg.hash || d.selected >= len(d.files) || d.files[d.selected].Path != msg.path {
+			return m, nil
+		}
+		m.preview = msg.preview
+		m.viewport.SetContent(m.renderPreviewContent())
+		m.viewport.GotoTop()
+
+	case timelineLoadedMsg:
+		if m.mode != modeTimeline || msg.path != m.timeline.path {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.statusMessage = msg.err.Error()
+		}
+		m.timeline.versions = msg.versions
+		if len(msg.versions) == 0 {
+			m.preview = PreviewContent{Valid: true, Message: "no versions recorded yet"}
+			m.viewport.SetContent(m.renderPreviewContent())
+			return m, nil
+		}
+		return m, m.loadTimelinePreview()
+
+	case timelinePreviewMsg:
+		t := m.timeline
+		if m.mode != modeTimeline || t.selected >= len(t.versions) || t.versions[t.selected].Hash != msg.hash {
+			return m, nil
+		}
+		m.preview = msg.preview
+		m.viewport.SetContent(m.renderPreviewContent())
+		m.viewport.GotoTop()
+
+	case snapshotFailedMsg:
+		// Shown once; later failures only go to the debug log
+		if !m.snapshotWarned {
+			m.snapshotWarned = true
+			m.statusMessage = "couldn't record snapshots: " + msg.err.Error()
+			m.statusUntil = time.Now().Add(toastFor)
+		}
+
+	case timelineRestoredMsg:
+		if msg.err != nil {
+			m.statusMessage = "couldn't restore: " + msg.err.Error()
+			return m, nil
+		}
+		m.statusMessage = "restored " + m.timeline.name + " to " + versionTime(msg.version.Time.Local(), time.Now())
+		if m.mode == modeTimeline {
+			m.exitTimelineMode()
+		}
+		return m, m.loadFiles
+
+	case diffstatLoadedMsg:
+		if m.mode != modeDiffstat {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.statusMessage = msg.err.Error()
+		}
+		m.diffstat = msg.files
+		m.preview = m.buildDiffstatPreview()
+		m.viewport.SetContent(m.renderPreviewContent())
+		m.viewport.GotoTop()
+
+	case compareLoadedMsg:
+		if m.mode != modeCompare || msg.path != m.compare.path {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.statusMessage = msg.err.Error()
+		}
+		m.compare.commits = append([]git.Commit{{}}, msg.commits...)
+		if len(msg.commits) == 0 {
+			m.compare.selected = 0
+			m.preview = PreviewContent{Valid: true, Message: "no history to compare"}
+			m.viewport.SetContent(m.renderPreviewContent())
+			return m, nil
+		}
+		return m, m.loadComparePreview()
+
+	case comparePreviewMsg:
+		if m.mode != modeCompare || m.compare.selected >= len(m.compare.commits) || m.compare.selected == m.compare.base {
+			return m, nil
+		}
+		if from, to := m.compare.compareRange(); from != msg.from || to != msg.to {
+			return m, nil
+		}
+		m.preview = msg.preview
+		m.viewport.SetContent(m.renderPreviewContent())
+		m.viewport.GotoTop()
+
+	case stashPreviewMsg:
+		if m.mode != modeStash || m.stashSelected >= len(m.stashes) || m.stashes[m.stashSelected].Ref != msg.ref {
+			return m, nil
+		}
+		m.preview = msg.preview
+		m.viewport.SetContent(m.renderPreviewContent())
+		m.viewport.GotoTop()
+
+	case amendCheckedMsg:
+		if msg.err != nil {
+			m.statusMessage = "can't amend: " + msg.err.Error()
+			return m, nil
+		}
+		m.statusMessage = ""
+		m.startAmend(msg.info, msg.scopes)
+
+	case amendDoneMsg:
+		return m, m.finishAmend(msg)
+
+	case quickStashMsg:
+		m.statusMessage = msg.text
+		if msg.err != nil {
+			m.statusMessage = msg.err.Error()
+		}
+		m.statusUntil = time.Now().Add(toastFor)
+		return m, m.loadFiles
+
+	case stashActionMsg:
+		if msg.err != nil {
+			m.statusMessage = msg.err.Error()
+		} else {
+			m.statusMessage = msg.action + " " + msg.ref
+		}
+		return m, tea.Batch(m.loadStashes, m.loadFiles)
+
 	case previewRequestMsg:
 		// Only load if this is still the pending request (debounce)
-		if msg.selectedIndex != m.previewPending {
+		if msg.selectedIndex != m.previewPending || m.mode != modeFiles {
 			return m, nil
 		}
 		if msg.selectedIndex < 0 || msg.selectedIndex >= len(m.files) {
 			return m, nil
 		}
+		// Secrets are masked again whenever the selection moves
+		m.revealSecrets = false
 		// Check cache first
 		file := m.files[msg.selectedIndex]
-		if cached, ok := m.previewCache[file.Path]; ok && file.Status == "committed" {
-			// Use cached preview for committed files (they don't change)
+		if cached, ok := m.previewCache.get(file.Path); ok && cachedPreviewValid(file, cached, m.dir, m.gitRoot) {
+			// Committed files don't change; uncommitted ones are reused while
+			// their mtime and diff match
 			m.preview = cached
 			m.viewport.SetContent(m.renderPreviewContent())
-			m.viewport.GotoTop()
+			if m.tail {
+				m.viewport.GotoBottom()
+			} else if file.Status == "uncommitted" && m.preview.HasChanges() {
+				m.scrollToFirstDiff()
+			} else {
+				m.viewport.GotoTop()
+			}
 			m.lastSelectedFile = msg.selectedIndex
 			m.previewPending = -1
-			return m, nil
+			m.markSeen()
+			m.announceSelection()
+			return m, tea.Batch(m.fileSelectedHook(), m.selectionCmd(), m.preloadAdjacent())
 		}
 		// Load async
-		return m, m.loadPreviewAsync(msg.selectedIndex)
+		m.announceSelection()
+		return m, tea.Batch(m.loadPreviewAsync(msg.selectedIndex), m.fileSelectedHook(), m.selectionCmd())
 
 	case previewLoadedMsg:
 		// Only apply if still relevant
-		if msg.selectedIndex != m.selected {
+		if msg.selectedIndex != m.selected || m.mode != modeFiles {
 			return m, nil
 		}
 		m.preview = msg.preview
-		// Cache committed file previews
+		m.perf.preview = msg.took
 		if msg.selectedIndex < len(m.files) {
-			file := m.files[msg.selectedIndex]
-			if file.Status == "committed" {
-				m.previewCache[file.Path] = msg.preview
-			}
+			m.cachePreview(m.files[msg.selectedIndex], msg.preview)
 		}
 		m.viewport.SetContent(m.renderPreviewContent())
-		
-		// Auto-scroll to first diff for uncommitted files
-		if msg.selectedIndex < len(m.files) {
+
+		// Auto-scroll to first diff for uncommitted files (or the end when tailing)
+		if m.tail {
+			m.viewport.GotoBottom()
+		} else if msg.selectedIndex < len(m.files) {
 			file := m.files[msg.selectedIndex]
-			if file.Status == "uncommitted" && len(m.preview.DiffLines) > 0 {
+			if file.Status == "uncommitted" && m.preview.HasChanges() {
 				m.scrollToFirstDiff()
 			} else {
 				m.viewport.GotoTop()
@@ -375,105 +1287,158 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else {
 			m.viewport.GotoTop()
 		}
-		
+
 		m.lastSelectedFile = msg.selectedIndex
 		m.previewPending = -1
+		m.markSeen()
+		cmds = append(cmds, m.preloadAdjacent())
+
+	case previewPreloadedMsg:
+		// Keep it only while the file is still listed as it was
+		for _, f := range m.files {
+			if f.Path == msg.file.Path && f.Status == msg.file.Status {
+				m.cachePreview(f, msg.preview)
+				break
+			}
+		}
 	}
 
 
 	return m, tea.Batch(cmds...)
 }
 
+// logMsg records message flow in the debug log, skipping mouse motion noise
+func logMsg(msg tea.Msg) {
+	if !debuglog.Enabled() {
+		return
+	}
+	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		if msg.Action == tea.MouseActionMotion {
+			return
+		}
+		debuglog.Printf("msg %T %s", msg, msg.String())
+	case tea.KeyMsg:
+		debuglog.Printf("msg key %q", msg.String())
+	case filesLoadedMsg:
+		debuglog.Printf("msg filesLoaded files=%d err=%v", len(msg.files), msg.err)
+	case previewLoadedMsg:
+		debuglog.Printf("msg previewLoaded index=%d lines=%d", msg.selectedIndex, len(msg.preview.RawLines))
+	default:
+		debuglog.Printf("msg %T", msg)
+	}
+}
+
+// cachePreview keeps a preview for reselecting its file: always for
+// committed files, and for uncommitted ones that can be validated later
+func (m *Model) cachePreview(file git.FileStatus, pc PreviewContent) {
+	if file.Status == "committed" || !pc.modTime.IsZero() {
+		m.previewCache.put(file.Path, pc)
+	}
+}
+
 // loadPreviewAsync returns a command that loads preview content in the background
 func (m *Model) loadPreviewAsync(selectedIndex int) tea.Cmd {
 	file := m.files[selectedIndex]
 	dir := m.dir
 	gitRoot := m.gitRoot
-	if file.GitRoot != "" {
-		gitRoot = file.GitRoot
-	}
+	m.cancelPreviewLoad()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.previewCancel = cancel
 
 	return func() tea.Msg {
-		fullPath := filepath.Join(dir, file.Path)
-
-		// Check if file was deleted
-		if strings.Contains(file.GitCode, "D") {
-			return previewLoadedMsg{
-				selectedIndex: selectedIndex,
-				preview:       PreviewContent{Valid: true, Message: fmt.Sprintf("%s was deleted", file.Path)},
-			}
-		}
-
-		// Check if file type is unsupported
-		if isUnsupportedFile(file.Path) {
-			reason := "not supported in perch"
-			if filepath.Ext(file.Path) == "" {
-				reason = "no file extension — open in your editor"
-			}
-			return previewLoadedMsg{
-				selectedIndex: selectedIndex,
-				preview:       PreviewContent{Valid: true, Message: fmt.Sprintf("%s\n%s", filepath.Base(file.Path), reason)},
-			}
+		defer cancel()
+		start := time.Now()
+		preview := buildPreview(ctx, file, dir, gitRoot)
+		if ctx.Err() != nil {
+			return nil
 		}
+		return previewLoadedMsg{selectedIndex: selectedIndex, preview: preview, took: time.Since(start)}
+	}
+}
 
-		// Get diff info
-		var diffLines map[int]string
-		var diffStats git.DiffStats
-		if file.Status == "uncommitted" {
-			diffLines = git.GetDiffLines(gitRoot, file.FullPath)
-			diffStats = git.GetDiffStats(gitRoot, file.FullPath)
-		} else {
-			diffLines = make(map[int]string)
-			diffStats = git.DiffStats{}
-		}
+// cancelPreviewLoad aborts the async preview load in flight, whose result
+// would be thrown away now that the selection has moved on
+func (m *Model) cancelPreviewLoad() {
+	if m.previewCancel != nil {
+		m.previewCancel()
+		m.previewCancel = nil
+	}
+}
 
-		// Read file content
-		content, err := os.ReadFile(fullPath)
-		if err != nil {
-			return previewLoadedMsg{
-				selectedIndex: selectedIndex,
-				preview:       PreviewContent{Valid: true, Message: fmt.Sprintf("couldn't read %s", file.Path)},
-			}
+// preloadAdjacent builds the previews of the files above and below the
+// selection in the background, so stepping to them needn't wait
+func (m *Model) preloadAdjacent() tea.Cmd {
+	if m.mode != modeFiles {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for _, i := range []int{m.selected + 1, m.selected - 1} {
+		if i < 0 || i >= len(m.files) {
+			continue
 		}
-
-		rawLines := strings.Split(string(content), "\n")
-		var highlightedLines []string
-		if isMarkdownERBFile(file.Path) {
-			highlightedLines = highlightMarkdownLines(rawLines, file.Path)
-			highlightedLines = applyERBStyling(highlightedLines)
-		} else if isMarkdownFile(file.Path) {
-			highlightedLines = highlightMarkdownLines(rawLines, file.Path)
-		} else if isERBFile(file.Path) {
-			highlightedLines = highlightCode(string(content), file.Path)
-			highlightedLines = applyERBStyling(highlightedLines)
-		} else {
-			highlightedLines = highlightCode(string(content), file.Path)
+		file := m.files[i]
+		if m.previewCache.has(file.Path) {
+			continue
 		}
+		dir, gitRoot := m.dir, m.gitRoot
+		cmds = append(cmds, func() tea.Msg {
+			return previewPreloadedMsg{file: file, preview: buildPreview(context.Background(), file, dir, gitRoot)}
+		})
+	}
+	return tea.Batch(cmds...)
+}
 
-		return previewLoadedMsg{
-			selectedIndex: selectedIndex,
-			preview: PreviewContent{
-				Valid:            true,
-				RawLines:         rawLines,
-				HighlightedLines: highlightedLines,
-				DiffLines:        diffLines,
-				DiffStats:        diffStats,
-			},
-		}
+// previewCmd asks for the selected file's preview: at once when it is
+// already cached, otherwise after the debounce
+func (m *Model) previewCmd() tea.Cmd {
+	m.previewPending = m.selected
+	m.cancelPreviewLoad()
+	if m.previewCache.has(m.files[m.selected].Path) {
+		selected := m.selected
+		return func() tea.Msg { return previewRequestMsg{selectedIndex: selected} }
 	}
+	return debouncePreviewCmd(m.selected)
 }
 
 func (m *Model) recalculateViewport() {
-	// Layout: fileList (listHeight) + divider (1) + previewHeader (1) + underline (1) + viewport + indicators (up to 2) + footer (1)
+	m.layoutViewport(m.width)
+	m.previewReady = true
+	if m.mode == modeFiles && m.selected == m.lastSelectedFile && m.preview.Valid {
+		// Same file at a new size: rewrap it where it is
+		m.viewport.SetContent(m.renderPreviewContent())
+		return
+	}
+	m.updatePreview()
+}
+
+// listRows is the height the list is drawn at: every row on the list's turn
+// in single-pane flow, otherwise the chosen listHeight, shrunk on a short
+// terminal so the preview keeps a couple of rows
+func (m Model) listRows() int {
+	if m.singlePane() {
+		return max(m.height-1-m.paneHeight()-m.statusLineHeight(), 1)
+	}
+	if m.height > 0 && m.listHeight > m.height-8 {
+		return max(m.height-8, 3)
+	}
+	return m.listHeight
+}
+
+// layoutViewport sizes the preview for the current height and the given width
+func (m *Model) layoutViewport(width int) {
+	// Layout: fileList (listRows) + divider (1) + previewHeader (1) + underline (1) + viewport + indicators (up to 2) + footer (1)
 	// Reserve space for up to 2 indicator lines (top + bottom dots) to keep layout stable
-	previewHeight := m.height - m.listHeight - 6
+	previewHeight := m.height - m.listRows() - 6 - m.paneHeight() - m.statusLineHeight()
+	if m.singlePane() || m.previewOnly() {
+		// Header (1) + divider (1) + indicators (2) + footer (1), no list
+		previewHeight = m.height - 5 - m.paneHeight() - m.statusLineHeight()
+	}
 	if previewHeight < 1 {
 		previewHeight = 1
 	}
-	m.viewport.Width = m.width
+	m.viewport.Width = width
 	m.viewport.Height = previewHeight
-	m.previewReady = true
-	m.updatePreview()
 }
 
 func (m *Model) updatePreview() {
@@ -481,6 +1446,12 @@ func (m *Model) updatePreview() {
 }
 
 func (m *Model) updatePreviewKeepScroll(keepScroll bool) {
+	// Other modes own the preview; just re-render it for the current size
+	if m.mode != modeFiles {
+		m.viewport.SetContent(m.renderPreviewContent())
+		return
+	}
+
 	if !m.previewReady || len(m.files) == 0 {
 		m.preview = PreviewContent{}
 		m.viewport.SetContent("")
@@ -493,89 +1464,44 @@ func (m *Model) updatePreviewKeepScroll(keepScroll bool) {
 		return
 	}
 
-	file := m.files[m.selected]
-	fullPath := filepath.Join(m.dir, file.Path)
-
-	// Check if file was deleted
-	if strings.Contains(file.GitCode, "D") {
-		m.preview = PreviewContent{Valid: true, Message: fmt.Sprintf("%s was deleted", file.Path)}
-		m.viewport.SetContent(m.renderPreviewContent())
-		if !keepScroll {
-			m.viewport.GotoTop()
-		}
+	prevLines := m.viewport.TotalLineCount()
+	start := time.Now()
+	preview := buildPreview(context.Background(), m.files[m.selected], m.dir, m.gitRoot)
+	m.perf.preview = time.Since(start)
+	if keepScroll && preview.Hash != 0 && preview.Hash == m.preview.Hash {
+		// Same content and diff as before: keep the preview and its wrap caches
 		m.lastSelectedFile = m.selected
+		m.markSeen()
 		return
 	}
-
-	// Check if file type is unsupported
-	if isUnsupportedFile(file.Path) {
-		reason := "not supported in perch"
-		if filepath.Ext(file.Path) == "" {
-			reason = "no file extension — open in your editor"
-		}
-		m.preview = PreviewContent{Valid: true, Message: fmt.Sprintf("%s\n%s", filepath.Base(file.Path), reason)}
-		m.viewport.SetContent(m.renderPreviewContent())
-		if !keepScroll {
-			m.viewport.GotoTop()
-		}
-		m.lastSelectedFile = m.selected
-		return
+	m.preview = preview
+	m.cachePreview(m.files[m.selected], preview)
+	m.viewport.SetContent(m.renderPreviewContent())
+	if !keepScroll {
+		m.viewport.GotoTop()
 	}
-
-	// Get diff info
-	var diffLines map[int]string
-	var diffStats git.DiffStats
-	if file.Status == "uncommitted" {
-		gitRoot := file.GitRoot
-		if gitRoot == "" {
-			gitRoot = m.gitRoot
-		}
-		diffLines = git.GetDiffLines(gitRoot, file.FullPath)
-		diffStats = git.GetDiffStats(gitRoot, file.FullPath)
-	} else {
-		diffLines = make(map[int]string)
-		diffStats = git.DiffStats{}
-	}
-
-	// Read file content
-	content, err := os.ReadFile(fullPath)
-	if err != nil {
-		m.preview = PreviewContent{Valid: true, Message: fmt.Sprintf("couldn't read %s", file.Path)}
-		m.viewport.SetContent(m.renderPreviewContent())
-		if !keepScroll {
-			m.viewport.GotoTop()
-		}
-		m.lastSelectedFile = m.selected
-		return
+	m.tailOnGrowth(prevLines)
+	// Restore the saved scroll position once the saved file is shown
+	if m.restorePath != "" && m.files[m.selected].Path == m.restorePath {
+		m.viewport.SetYOffset(m.restoreOffset)
+		m.restorePath = ""
 	}
+	m.lastSelectedFile = m.selected
+	m.markSeen()
+}
 
-	rawLines := strings.Split(string(content), "\n")
-	var highlightedLines []string
-	if isMarkdownERBFile(file.Path) {
-		highlightedLines = highlightMarkdownLines(rawLines, file.Path)
-		highlightedLines = applyERBStyling(highlightedLines)
-	} else if isMarkdownFile(file.Path) {
-		highlightedLines = highlightMarkdownLines(rawLines, file.Path)
-	} else if isERBFile(file.Path) {
-		highlightedLines = highlightCode(string(content), file.Path)
-		highlightedLines = applyERBStyling(highlightedLines)
-	} else {
-		highlightedLines = highlightCode(string(content), file.Path)
+// ensureSelectedVisible scrolls the file list so the selection is on screen
+func (m *Model) ensureSelectedVisible() {
+	visibleCapacity := m.listRows() - 3
+	if visibleCapacity < 1 {
+		visibleCapacity = 1
 	}
-
-	m.preview = PreviewContent{
-		Valid:            true,
-		RawLines:         rawLines,
-		HighlightedLines: highlightedLines,
-		DiffLines:        diffLines,
-		DiffStats:        diffStats,
+	if m.selected < m.listScroll {
+		m.listScroll = m.selected
 	}
-
-	m.viewport.SetContent(m.renderPreviewContent())
-	if !keepScroll {
-		m.viewport.GotoTop()
+	if m.selected >= m.listScroll+visibleCapacity {
+		m.listScroll = m.selected - visibleCapacity + 1
 	}
-	m.lastSelectedFile = m.selected
 }
 
 // renderPreviewContent builds the content string for the viewport using wrapped lines
@@ -606,31 +1532,74 @@ func (m *Model) renderPreviewContent() string {
 		return ""
 	}
 
-	wrappedLines := m.preview.WrappedLinesForWidth(m.width)
+	// Rows are drawn a screen at a time by previewRows; the viewport only
+	// needs as many lines as there are to scroll through
+	idx := m.displayPreview().wrapIndexForWidth(m.wrapWidth())
+	return strings.Repeat("\n", max(idx.total()-1, 0))
+}
+
+// virtualPreview reports whether the viewport holds placeholder rows for a
+// file preview (see previewRows) rather than rendered content
+func (m Model) virtualPreview() bool {
+	return m.preview.Valid && m.preview.Message == "" && len(m.preview.HighlightedLines) > 0
+}
+
+// previewView renders the viewport, drawing a file preview's visible rows
+// from the wrap index
+func (m Model) previewView() string {
+	vp := m.viewport
+	vp.Width = min(vp.Width, m.width)
+	if !m.virtualPreview() {
+		return vp.View()
+	}
+	vp.SetContent(m.previewRows(vp.YOffset, vp.YOffset+vp.Height))
+	vp.SetYOffset(0)
+	return vp.View()
+}
+
+// previewRows renders visual rows [start, end) of the displayed preview
+func (m Model) previewRows(start, end int) string {
+	display := m.displayPreview()
+	idx := display.wrapIndexForWidth(m.wrapWidth())
+	end = min(end, idx.total())
 
 	var b strings.Builder
-	for i, vl := range wrappedLines {
+	for i := start; i < end; i++ {
+		vl := idx.row(i)
 		var gutter string
 		var bgCode string
 		var fgCode string
 
 		switch vl.DiffStatus {
 		case "added":
-			gutter = "  " + lineAddGutter.Render(vl.Gutter)
+			gutter = m.gutterIndent() + lineAddGutter.Render(vl.Gutter)
+			if _, ok := display.Secrets[vl.LogicalIndex]; ok && vl.SegmentIndex == 0 {
+				gutter = m.gutterIndent() + secretStyle.Render("! ")
+			}
 			bgCode = bgAddANSI
 			fgCode = fgAddANSI
 		case "deleted":
-			gutter = "  " + lineDelGutter.Render(vl.Gutter)
+			gutter = m.gutterIndent() + lineDelGutter.Render(vl.Gutter)
 			bgCode = bgDelANSI
 			fgCode = fgDelANSI
 		default:
-			gutter = "  " + lineDotStyle.Render(vl.Gutter)
+			gutter = m.gutterIndent() + lineDotStyle.Render(vl.Gutter)
+			if Accessible {
+				gutter = m.gutterIndent() + "  "
+			}
+		}
+		if m.visualSelected(i) {
+			bgCode = bgSelANSI
+		}
+		if Accessible && !m.visualSelected(i) {
+			// The +/- gutter already says it; backgrounds only add noise
+			bgCode = ""
 		}
 
 		// Calculate visible width BEFORE any background injection
 		// gutter: "  " (2) + vl.Gutter (2, e.g. "+ ") = 4 visible chars
 		// We use a fixed gutter width since it's always the same structure
-		const gutterVisibleWidth = 4
+		gutterVisibleWidth := len(m.gutterIndent()) + 2
 		textWidth := VisibleWidth(vl.Text)
 		totalWidth := gutterVisibleWidth + textWidth
 		padding := m.width - totalWidth
@@ -641,9 +1610,15 @@ func (m *Model) renderPreviewContent() string {
 		// Inject background into both gutter and content so it survives ANSI resets
 		text := vl.Text
 		if bgCode != "" {
-			gutter = InjectBackground(gutter, bgCode)
 			// Apply foreground color to text (overrides syntax highlighting)
 			text = fgCode + stripANSIColors(vl.Text) + ansiReset
+		}
+		if m.showWhitespace {
+			lastSegment := i == idx.total()-1 || idx.row(i+1).LogicalIndex != vl.LogicalIndex
+			text = visualizeWhitespace(text, vl.SegmentIndex == 0, lastSegment)
+		}
+		if bgCode != "" {
+			gutter = InjectBackground(gutter, bgCode)
 			text = InjectBackground(text, bgCode)
 		}
 
@@ -662,7 +1637,7 @@ func (m *Model) renderPreviewContent() string {
 			b.WriteString(strings.Repeat(" ", padding))
 		}
 
-		if i < len(wrappedLines)-1 {
+		if i < end-1 {
 			b.WriteString("\n")
 		}
 	}
@@ -670,38 +1645,59 @@ func (m *Model) renderPreviewContent() string {
 	return b.String()
 }
 
+// contextMessage describes the diff-only context setting for the footer
+func contextMessage(n int) string {
+	if n == 1 {
+		return "1 line of context"
+	}
+	return fmt.Sprintf("%d lines of context", n)
+}
+
+// displayPreview returns the preview as shown: whole, or collapsed to
+// changed hunks when diff-only view is on
+func (m *Model) displayPreview() *PreviewContent {
+	pc := &m.preview
+	if m.revealSecrets && pc.revealed != nil && m.mode == modeFiles {
+		pc = pc.revealed
+	}
+	if m.diffOnly && m.mode == modeFiles {
+		return pc.Collapsed(m.diffContext)
+	}
+	return pc
+}
+
 // scrollToFirstDiff scrolls the viewport to the first diff line with context
 func (m *Model) scrollToFirstDiff() {
-	wrappedLines := m.preview.WrappedLinesForWidth(m.width)
-	
+	display := m.displayPreview()
+
 	// Find the first line with a diff status
 	firstDiffIndex := -1
-	for i, vl := range wrappedLines {
-		if vl.DiffStatus == "added" || vl.DiffStatus == "deleted" {
-			firstDiffIndex = i
+	for i, row := range display.Diff {
+		if row.Type == "add" || row.Type == "remove" {
+			firstDiffIndex = display.wrapIndexForWidth(m.wrapWidth()).rowOf(i)
 			break
 		}
 	}
-	
+
 	if firstDiffIndex == -1 {
 		// No diff found, go to top
 		m.viewport.GotoTop()
 		return
 	}
-	
+
 	// Add context lines above (3 lines of context)
 	contextLines := 3
 	targetOffset := firstDiffIndex - contextLines
 	if targetOffset < 0 {
 		targetOffset = 0
 	}
-	
+
 	// Don't scroll if the diff is already near the top
 	if targetOffset <= 2 {
 		m.viewport.GotoTop()
 		return
 	}
-	
+
 	// Set the viewport offset
 	m.viewport.SetYOffset(targetOffset)
 }
@@ -804,40 +1800,138 @@ func isUnsupportedFile(path string) bool {
 
 // View implements tea.Model
 func (m Model) View() string {
+	start := time.Now()
+	frame := m.view()
+	if m.showTips && !m.loading && m.scalePrompt == "" && m.width > 0 && !m.tooSmall() {
+		frame = m.withTipsCard(frame)
+	}
+	if !PerfOverlay || m.width == 0 {
+		return frame
+	}
+	m.perf.render = time.Since(start)
+	return m.withPerfOverlay(frame)
+}
+
+// view renders the frame
+func (m Model) view() string {
+	if m.scalePrompt != "" && m.width > 0 && m.height > 0 {
+		return m.renderScalePrompt()
+	}
 	// Show loading screen instantly, even before dimensions arrive
 	if m.loading {
 		if m.width == 0 || m.height == 0 {
 			// Minimal instant display before window size is known
 			return "\n\n  " + cyanStyle.Render("PERCH") + "\n"
 		}
+		if m.tooSmall() {
+			return m.renderTooSmall()
+		}
+		if Accessible {
+			return m.renderAccessibleLoading()
+		}
 		return m.renderLoadingScreen()
 	}
 
 	if m.width == 0 || m.height == 0 {
 		return ""
 	}
+	if m.tooSmall() {
+		return m.renderTooSmall()
+	}
+
+	if m.previewOnly() {
+		return m.renderPreviewOnly()
+	}
+	if m.singlePane() {
+		return m.renderListOnly()
+	}
 
 	var b strings.Builder
 
+	if StatusLine == "top" {
+		b.WriteString(m.renderStatusLine())
+	}
+
 	// === FILE LIST ===
-	b.WriteString(m.renderFileList())
+	b.WriteString(m.renderList())
 
 	// === DIVIDER ===
-	b.WriteString(dividerStyle.Render(strings.Repeat("─", m.width)) + "\n")
+	b.WriteString(m.divider())
 
 	// === PREVIEW HEADER ===
-	b.WriteString(m.renderPreviewHeader())
-	b.WriteString(dividerStyle.Render(strings.Repeat("─", m.width)) + "\n")
+	b.WriteString(m.renderModeHeader())
+	b.WriteString(m.divider())
 
 	// === VIEWPORT (preview content with scroll indicators) ===
 	b.WriteString(m.renderPreviewWithIndicators())
 
+	// === ON-CHANGE OUTPUT ===
+	if m.paneHeight() > 0 {
+		b.WriteString(m.renderRunPane())
+	}
+
+	if StatusLine == "bottom" {
+		b.WriteString(m.renderStatusLine())
+	}
+
 	// === FOOTER ===
 	b.WriteString(m.renderFooter())
 
 	return b.String()
 }
 
+// renderList renders the top pane for the current mode
+func (m Model) renderList() string {
+	switch m.mode {
+	case modeStash:
+		return m.renderStashList()
+	case modeWorktrees:
+		return m.renderWorktreeList()
+	case modeSummary:
+		return m.renderSummaryList()
+	case modeChurn:
+		return m.renderChurnList()
+	case modeLog:
+		return m.renderLogList()
+	case modeCommitFiles:
+		return m.renderCommitFileList()
+	case modeCompare:
+		return m.renderCompareList()
+	case modeDiffstat:
+		return m.renderDiffstatList()
+	case modeTimeline:
+		return m.renderTimelineList()
+	default:
+		return m.renderFileList()
+	}
+}
+
+// renderModeHeader renders the line above the preview for the current mode
+func (m Model) renderModeHeader() string {
+	switch m.mode {
+	case modeStash:
+		return m.renderStashHeader()
+	case modeWorktrees:
+		return "\n"
+	case modeSummary:
+		return "  " + cyanStyle.Render("busiest files") + "\n"
+	case modeChurn:
+		return "  " + cyanStyle.Render("hotspots") + m.hint(dimStyle.Render("  commits per file")) + "\n"
+	case modeLog:
+		return m.renderLogHeader()
+	case modeCommitFiles:
+		return m.renderCommitFileHeader()
+	case modeCompare:
+		return m.renderCompareHeader()
+	case modeDiffstat:
+		return "  " + cyanStyle.Render("working tree vs HEAD") + "\n"
+	case modeTimeline:
+		return m.renderTimelineHeader()
+	default:
+		return m.renderPreviewHeader()
+	}
+}
+
 // renderPreviewWithIndicators renders the viewport with scroll indicators at display level
 func (m Model) renderPreviewWithIndicators() string {
 	var lines []string
@@ -849,6 +1943,9 @@ func (m Model) renderPreviewWithIndicators() string {
 	totalContentLines := m.viewport.TotalLineCount()
 	visibleEnd := m.viewport.YOffset + m.viewport.Height
 	showBottomDots := visibleEnd < totalContentLines
+	if m.outlineActive || m.linksActive {
+		showTopDots, showBottomDots = false, false
+	}
 
 	// Top indicator (or empty line to maintain layout)
 	if showTopDots {
@@ -858,7 +1955,13 @@ func (m Model) renderPreviewWithIndicators() string {
 	}
 
 	// Main viewport content
-	lines = append(lines, m.viewport.View())
+	if m.outlineActive {
+		lines = append(lines, m.renderOutline())
+	} else if m.linksActive {
+		lines = append(lines, m.renderLinks())
+	} else {
+		lines = append(lines, m.previewView())
+	}
 
 	// Bottom indicator (or empty line to maintain layout)
 	if showBottomDots {
@@ -885,36 +1988,86 @@ func (m Model) renderFileList() string {
 	if DevBuild {
 		devMarker = dimStyle.Render("[dev] ")
 	}
-	header := devMarker + dimStyle.Render("PERCHED ON PROGRESS") + " " + sparkle
+	if m.linkedWorktree {
+		devMarker += dimStyle.Render("[worktree] ")
+	}
+	if m.plainDir {
+		devMarker += dimStyle.Render("[no git] ")
+	}
+	if m.scoped {
+		devMarker += dimStyle.Render("[scoped] ")
+	}
+	if m.savedFilters {
+		devMarker += dimStyle.Render("[saved filters] ")
+	}
+	if m.follow {
+		devMarker += cyanStyle.Render("[follow] ")
+	}
+	titleText := "PERCHED ON PROGRESS"
+	if !m.showHints() {
+		titleText = "PERCH"
+	}
+	title := dimStyle.Render(titleText)
+	if m.flashing {
+		title = flashStyle.Render(titleText)
+	}
+	header := devMarker + title + " " + sparkle
 	pathHint := dimStyle.Render("..." + shortPath)
+	if m.pr != nil {
+		pathHint = m.renderPRStatus() + "  " + pathHint
+	}
+	sync := m.sync.render()
+	if Accessible {
+		sync = m.sync.accessible()
+	}
+	if sync != "" && m.statusLineHeight() == 0 {
+		pathHint = sync + "  " + pathHint
+	}
+	if progress := m.renderReviewProgress(); progress != "" {
+		pathHint = progress + "  " + pathHint
+	}
+	// The summary shortens, then goes, when the header is tight; the
+	// sparkline is dropped before it
+	for _, compact := range []bool{false, true} {
+		if summary := m.changeSummary(compact); summary != "" && lipgloss.Width(header+summary+pathHint)+3 <= m.width {
+			header += " " + summary
+			break
+		}
+	}
+	if spark := m.renderSparkline(); spark != "" && !Accessible && lipgloss.Width(header)+lipgloss.Width(spark+pathHint)+3 <= m.width {
+		pathHint = spark + "  " + pathHint
+	}
 	lines = append(lines, padLine(header, pathHint, m.width))
 
 	if len(m.files) == 0 {
-		for len(lines) < m.listHeight {
+		for len(lines) < m.listRows() {
 			lines = append(lines, "")
 		}
 		return strings.Join(lines, "\n") + "\n"
 	}
 
-	// Calculate visible range (now we have 1 header line)
-	showUpDots := m.listScroll > 0
-	fileSlots := m.listHeight - 1 // -1 for header line
-	if showUpDots {
-		fileSlots--
-	}
-	potentialEnd := m.listScroll + fileSlots
-	showDownDots := potentialEnd < len(m.files)
-	if showDownDots {
-		fileSlots--
-	}
-	if fileSlots < 1 {
-		fileSlots = 1
-	}
-
+	// Calculate visible range (now we have 1 header line). Date separators
+	// take rows too, so the window slides down until the selection fits.
+	groups := fileGroups(m.files, time.Now())
 	visibleStart := m.listScroll
-	visibleEnd := m.listScroll + fileSlots
-	if visibleEnd > len(m.files) {
-		visibleEnd = len(m.files)
+	var rows []listRow
+	var showUpDots, showDownDots bool
+	for {
+		showUpDots = visibleStart > 0
+		fileSlots := m.listRows() - 1 // -1 for header line
+		if showUpDots {
+			fileSlots--
+		}
+		var end int
+		rows, end = listRows(visibleStart, fileSlots, groups, len(m.files))
+		showDownDots = end < len(m.files)
+		if showDownDots {
+			rows, end = listRows(visibleStart, fileSlots-1, groups, len(m.files))
+		}
+		if m.selected < end || visibleStart >= m.selected {
+			break
+		}
+		visibleStart++
 	}
 
 	// Up dots
@@ -927,10 +2080,21 @@ func (m Model) renderFileList() string {
 	if maxPathLen < 10 {
 		maxPathLen = 10
 	}
-	for i := visibleStart; i < visibleEnd; i++ {
+	for _, row := range rows {
+		if row.file < 0 {
+			lines = append(lines, renderDateSeparator(row.label))
+			continue
+		}
+		i := row.file
 		f := m.files[i]
+		if Accessible {
+			lines = append(lines, accessibleFileLine(f, i == m.selected, m.seen.unseen(f), m.width))
+			continue
+		}
 		icon := "✓ "
-		if f.Status == "uncommitted" {
+		if f.Status == "plain" {
+			icon = "· "
+		} else if f.Status == "uncommitted" {
 			if f.GitCode == "??" || f.GitCode == "A " || f.GitCode == "AM" {
 				icon = "✦ "
 			} else {
@@ -938,13 +2102,28 @@ func (m Model) renderFileList() string {
 			}
 		}
 		displayPath := f.Path
+		if f.OldPath != "" {
+			displayPath = f.OldPath + " → " + f.Path
+		}
 		if len(displayPath) > maxPathLen {
 			displayPath = "..." + displayPath[len(displayPath)-maxPathLen+3:]
 		}
+		// Committed files carry their commit's HEAD/tags and author's
+		// initials on the right
+		author := ""
+		if f.Status == "committed" {
+			author = dimStyle.Render(authorInitials(f.Author))
+			if f.Refs != "" {
+				author = cyanStyle.Render(f.Refs) + " " + author
+			}
+		}
+		if m.seen.unseen(f) {
+			author = strings.TrimSpace(cyanStyle.Render("●") + " " + author)
+		}
 		if i == m.selected {
-			lines = append(lines, selectedStyle.Render("› "+icon+displayPath))
+			lines = append(lines, padLine(selectedStyle.Render("› "+icon+displayPath), author, m.width))
 		} else {
-			lines = append(lines, "  "+dimStyle.Render(icon)+displayPath)
+			lines = append(lines, padLine("  "+dimStyle.Render(icon)+displayPath, author, m.width))
 		}
 	}
 
@@ -953,14 +2132,35 @@ func (m Model) renderFileList() string {
 		lines = append(lines, dimStyle.Render("  ..."))
 	}
 
-	// Pad to listHeight
-	for len(lines) < m.listHeight {
+	// Pad to listRows
+	for len(lines) < m.listRows() {
 		lines = append(lines, "")
 	}
 
 	return strings.Join(lines, "\n") + "\n"
 }
 
+// authorInitials shortens a commit author to initials ("Kate Lee" → "KL");
+// single-word names keep their first two letters
+func authorInitials(name string) string {
+	words := strings.Fields(name)
+	if len(words) == 0 {
+		return ""
+	}
+	if len(words) == 1 {
+		r := []rune(words[0])
+		if len(r) > 2 {
+			r = r[:2]
+		}
+		return string(r)
+	}
+	var initials []rune
+	for _, w := range []string{words[0], words[len(words)-1]} {
+		initials = append(initials, unicode.ToUpper([]rune(w)[0]))
+	}
+	return string(initials)
+}
+
 func (m Model) renderPreviewHeader() string {
 	if len(m.files) == 0 || m.selected < 0 || m.selected >= len(m.files) {
 		return "\n"
@@ -969,16 +2169,116 @@ func (m Model) renderPreviewHeader() string {
 	f := m.files[m.selected]
 	basename := filepath.Base(f.Path)
 	header := "  " + cyanStyle.Render(basename) + "  " + dimStyle.Render(f.ChangeType())
-	hint := keyStyle.Render("j k") + dimStyle.Render(" scroll  ")
+	if f.Status == "uncommitted" {
+		if stats := renderDiffStats(m.preview.DiffStats); stats != "" {
+			header += "  " + stats
+		}
+	}
+	if m.preview.Symlink != "" {
+		header += "  " + cyanStyle.Render(m.preview.Symlink)
+	}
+	if m.preview.DeletedFrom != "" {
+		header += "  " + lineDelGutter.Render("[as of "+m.preview.DeletedFrom+"]")
+	}
+	if label := m.preview.encodingLabel(); label != "" {
+		header += "  " + dimStyle.Render(label)
+	}
+	if m.tail {
+		header += "  " + cyanStyle.Render("[tail]")
+	}
+	if notice := m.preview.secretNotice(); notice != "" {
+		header += "  " + secretStyle.Render(notice)
+	}
+	if m.preview.Masked > 0 {
+		if m.revealSecrets {
+			header += "  " + lineDelGutter.Render("[revealed]")
+		} else {
+			header += "  " + cyanStyle.Render("[masked]") + m.hint(dimStyle.Render(" m reveal"))
+		}
+	}
+	hint := m.hint(keyStyle.Render("j k") + dimStyle.Render(" scroll  "))
+	if m.zen && m.viewFile == "" {
+		hint = dimStyle.Render(m.zenPosition()+"  ") + m.hint(keyStyle.Render("[ ]")+dimStyle.Render(" files  "))
+	}
 	return padLine(header, hint, m.width) + "\n"
 }
 
+// renderDiffStats shows line counts as "+N −M", leaving out zero sides
+func renderDiffStats(s git.DiffStats) string {
+	var parts []string
+	if Accessible {
+		if s.Added > 0 {
+			parts = append(parts, fmt.Sprintf("%d added", s.Added))
+		}
+		if s.Deleted > 0 {
+			parts = append(parts, fmt.Sprintf("%d removed", s.Deleted))
+		}
+		return strings.Join(parts, ", ")
+	}
+	if s.Added > 0 {
+		parts = append(parts, lineAddGutter.Render(fmt.Sprintf("+%d", s.Added)))
+	}
+	if s.Deleted > 0 {
+		parts = append(parts, lineDelGutter.Render(fmt.Sprintf("−%d", s.Deleted)))
+	}
+	return strings.Join(parts, " ")
+}
+
 func (m Model) renderFooter() string {
-	leftHint := dimStyle.Render("hold ") + keyStyle.Render("shift") + dimStyle.Render(" to select text")
-	rightHint := keyStyle.Render("q") + dimStyle.Render(" quit  ")
+	leftHint := m.hint(dimStyle.Render("hold ") + keyStyle.Render("shift") + dimStyle.Render(" to select text"))
+	if m.singlePane() {
+		pane := "preview"
+		if m.singlePreview {
+			pane = "list"
+		}
+		leftHint = keyStyle.Render("tab") + dimStyle.Render(" "+pane)
+	}
+	if Accessible && m.announcement != "" {
+		leftHint = m.announcement
+	}
+	if m.statusMessage != "" {
+		leftHint = lineDelGutter.Render(m.statusMessage)
+	}
+	if m.gotoActive {
+		leftHint = keyStyle.Render(":") + m.gotoInput + dimStyle.Render("  enter go · esc cancel")
+	}
+	if m.exportActive {
+		leftHint = dimStyle.Render("write patch to ") + m.exportInput + keyStyle.Render("▏")
+	}
+	if m.amendActive {
+		leftHint = m.renderAmendPrompt()
+	}
+	if m.visualActive {
+		leftHint = keyStyle.Render("VISUAL") + dimStyle.Render("  j k extend · y yank · esc cancel")
+	}
+	if m.outlineActive {
+		leftHint = keyStyle.Render("OUTLINE") + dimStyle.Render("  j k move · enter jump · esc close")
+	}
+	if m.linksActive {
+		leftHint = keyStyle.Render("LINKS") + dimStyle.Render("  j k move · enter open · esc close")
+	}
+	rightHint := m.hint(keyStyle.Render("q") + dimStyle.Render(" quit  "))
+	if m.embedded {
+		rightHint = ""
+	}
+	if OnChange != "" && m.paneHeight() == 0 {
+		rightHint = m.runStatus() + "  " + rightHint
+	}
 	return padLine(leftHint, rightHint, m.width)
 }
 
+// renderPRStatus colors the PR summary by its CI state
+func (m Model) renderPRStatus() string {
+	style := dimStyle
+	switch m.pr.CI {
+	case "passing":
+		style = lineAddGutter
+	case "failing":
+		style = lineDelGutter
+	}
+	return style.Render(m.pr.Summary())
+}
+
 // Helper functions
 func truncatePath(path string, n int) string {
 	parts := strings.Split(path, "/")
@@ -991,6 +2291,15 @@ func truncatePath(path string, n int) string {
 func padLine(left, right string, width int) string {
 	leftLen := lipgloss.Width(left)
 	rightLen := lipgloss.Width(right)
+	// Too tight for both: the right-hand side gives way, then the left is cut
+	if leftLen+rightLen+1 > width {
+		right, rightLen = "", 0
+		if leftLen > width {
+			left, _, _ = sliceANSIAware(left, width)
+			left += ansiReset
+			leftLen = width
+		}
+	}
 	padding := width - leftLen - rightLen
 	if padding < 1 {
 		padding = 1
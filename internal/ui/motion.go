@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/kateleext/perch/internal/git"
+)
+
+// ReduceMotion turns off the sparkle, loading frames, and header flash, and
+// skips rebuilding the preview on refreshes that found nothing new, so an
+// idle perch leaves the terminal (and the CPU) alone
+var ReduceMotion bool
+
+// animate reports whether decorative animation should run
+func animate() bool {
+	return !ReduceMotion && !Accessible
+}
+
+// sameFiles reports whether two loads found exactly the same files
+func sameFiles(a, b []git.FileStatus) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		x, y := a[i], b[i]
+		if !x.ModTime.Equal(y.ModTime) {
+			return false
+		}
+		x.ModTime, y.ModTime = time.Time{}, time.Time{}
+		if x != y {
+			return false
+		}
+	}
+	return true
+}
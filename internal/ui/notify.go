@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kateleext/perch/internal/debuglog"
+	"github.com/kateleext/perch/internal/git"
+	"github.com/kateleext/perch/internal/notify"
+)
+
+// Notify selects when file changes fire a desktop notification:
+// "off", "unfocused" (only while the terminal is unfocused), or "always"
+var Notify = "off"
+
+// notifyCooldown keeps a burst of writes from producing a burst of notifications
+const notifyCooldown = 10 * time.Second
+
+// changedFiles returns uncommitted (or plain) files in next that are new or
+// modified since prev
+func changedFiles(prev, next []git.FileStatus) []git.FileStatus {
+	seen := make(map[string]time.Time, len(prev))
+	for _, f := range prev {
+		if f.Status != "committed" {
+			seen[f.Path] = f.ModTime
+		}
+	}
+	var changed []git.FileStatus
+	for _, f := range next {
+		if f.Status == "committed" {
+			continue
+		}
+		if mod, ok := seen[f.Path]; !ok || f.ModTime.After(mod) {
+			changed = append(changed, f)
+		}
+	}
+	return changed
+}
+
+// notifyCmd sends a notification for changed files if the Notify setting,
+// focus state, and cooldown allow it
+func (m *Model) notifyCmd(changed []git.FileStatus) tea.Cmd {
+	if len(changed) == 0 || Notify == "off" || (Notify == "unfocused" && !m.blurred) {
+		return nil
+	}
+	if time.Since(m.lastNotify) < notifyCooldown {
+		return nil
+	}
+	m.lastNotify = time.Now()
+
+	title := "perch · " + filepath.Base(m.dir)
+	body := changed[0].Path + " changed"
+	if len(changed) > 1 {
+		body = fmt.Sprintf("%s and %d more changed", changed[0].Path, len(changed)-1)
+	}
+	return func() tea.Msg {
+		if err := notify.Send(title, body); err != nil {
+			debuglog.Printf("notify: %v", err)
+		}
+		return nil
+	}
+}
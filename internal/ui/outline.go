@@ -0,0 +1,132 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// outlineEntry is one heading in a markdown preview's outline
+type outlineEntry struct {
+	level int
+	title string
+	row   int // display row of the heading
+}
+
+// buildOutline lists the headings of the preview as shown, skipping code
+// fences and removed lines
+func buildOutline(pc *PreviewContent) []outlineEntry {
+	var entries []outlineEntry
+	inFence := false
+	for i, raw := range pc.RawLines {
+		if i < len(pc.Diff) && pc.Diff[i].Type == "remove" {
+			continue
+		}
+		trimmed := strings.TrimSpace(raw)
+		if fenceRegex.MatchString(raw) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if level, title := parseHeading(trimmed); level > 0 && title != "" {
+			entries = append(entries, outlineEntry{level: level, title: stripANSIColors(renderInlineMarkdown(title)), row: i})
+		}
+	}
+	return entries
+}
+
+// openOutline shows the heading outline of a markdown preview (o)
+func (m *Model) openOutline() {
+	if m.mode != modeFiles || len(m.files) == 0 || !isMarkdownFile(m.files[m.selected].Path) {
+		m.statusMessage = "outline is for markdown files"
+		return
+	}
+	m.outline = buildOutline(m.displayPreview())
+	if len(m.outline) == 0 {
+		m.statusMessage = "no headings"
+		return
+	}
+	// Start on the section currently in view
+	m.outlineCursor = 0
+	wrapped := m.displayPreview().WrappedLinesForWidth(m.wrapWidth())
+	if m.viewport.YOffset < len(wrapped) {
+		top := wrapped[m.viewport.YOffset].LogicalIndex
+		for i, e := range m.outline {
+			if e.row <= top {
+				m.outlineCursor = i
+			}
+		}
+	}
+	m.outlineActive = true
+}
+
+// updateOutline handles keys while the outline is open
+func (m Model) updateOutline(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, m.quit()
+	case "esc", "o", "q":
+		m.outlineActive = false
+	case "j", "down":
+		if m.outlineCursor < len(m.outline)-1 {
+			m.outlineCursor++
+		}
+	case "k", "up":
+		if m.outlineCursor > 0 {
+			m.outlineCursor--
+		}
+	case "g":
+		m.outlineCursor = 0
+	case "G":
+		m.outlineCursor = len(m.outline) - 1
+	case "enter":
+		m.outlineActive = false
+		m.jumpToRow(m.outline[m.outlineCursor].row)
+	}
+	return m, nil
+}
+
+// jumpToRow scrolls the preview so display row sits at the top
+func (m *Model) jumpToRow(row int) {
+	for i, vl := range m.displayPreview().WrappedLinesForWidth(m.wrapWidth()) {
+		if vl.LogicalIndex == row && vl.SegmentIndex == 0 {
+			m.viewport.SetYOffset(i)
+			return
+		}
+	}
+}
+
+// renderOutline draws the outline in place of the preview
+func (m Model) renderOutline() string {
+	return m.renderPicker(len(m.outline), m.outlineCursor, func(i int, selected bool) string {
+		e := m.outline[i]
+		indent := strings.Repeat("  ", e.level-1)
+		if selected {
+			return selectedStyle.Render(cursorMarker() + indent + e.title)
+		}
+		if e.level > 2 {
+			return dimStyle.Render("  " + indent + e.title)
+		}
+		return "  " + indent + e.title
+	})
+}
+
+// renderPicker fills the preview area with a list of n items, scrolled to
+// keep the cursor in view
+func (m Model) renderPicker(n, cursor int, item func(i int, selected bool) string) string {
+	height := m.viewport.Height
+	start := 0
+	if cursor >= height {
+		start = cursor - height + 1
+	}
+	lines := make([]string, 0, height)
+	for i := start; i < n && len(lines) < height; i++ {
+		lines = append(lines, padLine(item(i, i == cursor), "", m.width))
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// PerfOverlay shows render, refresh, and preview load times and goroutine
+// and cache counts over the top line, for reporting slowness on big repos
+var PerfOverlay bool
+
+// perfStats holds the latest timings. The model shares it by pointer so
+// View, which can't change the model, can still record its own.
+type perfStats struct {
+	render  time.Duration // building the last frame
+	refresh time.Duration // the last git status load
+	preview time.Duration // the last preview build
+}
+
+// perfLine sums up the timings and counts in one line
+func (m Model) perfLine() string {
+	round := func(d time.Duration) string {
+		return d.Round(100 * time.Microsecond).String()
+	}
+	return fmt.Sprintf(" render %s · git %s · preview %s · %d goroutines · cache %d (%d lines) ",
+		round(m.perf.render), round(m.perf.refresh), round(m.perf.preview),
+		runtime.NumGoroutine(), m.previewCache.order.Len(), m.previewCache.lines)
+}
+
+// withPerfOverlay draws the perf line over the right end of the frame's
+// first line
+func (m Model) withPerfOverlay(frame string) string {
+	stats := m.perfLine()
+	if lipgloss.Width(stats) > m.width {
+		stats, _, _ = sliceANSIAware(stats, m.width)
+	}
+	first, rest, multiline := strings.Cut(frame, "\n")
+	keep := m.width - lipgloss.Width(stats)
+	if w := lipgloss.Width(first); w > keep {
+		first, _, _ = sliceANSIAware(first, keep)
+		first += ansiReset
+	} else {
+		first += strings.Repeat(" ", keep-w)
+	}
+	first += flashStyle.Render(stats)
+	if !multiline {
+		return first
+	}
+	return first + "\n" + rest
+}
@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kateleext/perch/internal/gh"
+)
+
+// prRefreshInterval is how often the PR status is re-fetched; it hits the
+// network, so it runs far less often than the file refresh
+const prRefreshInterval = time.Minute
+
+// prStatusMsg carries a fetched PR status; pr is nil when there is none
+type prStatusMsg struct {
+	dir string
+	pr  *gh.PRStatus
+}
+
+// prTickMsg triggers the next PR status refresh
+type prTickMsg struct{}
+
+// loadPRStatus fetches the current branch's PR in the background. Errors
+// (no PR, not a GitHub repo, offline) just hide the indicator.
+func (m Model) loadPRStatus() tea.Msg {
+	pr, err := gh.GetPRStatus(context.Background(), m.dir)
+	if err != nil {
+		return prStatusMsg{dir: m.dir}
+	}
+	return prStatusMsg{dir: m.dir, pr: &pr}
+}
+
+func prTickCmd() tea.Cmd {
+	return tea.Tick(prRefreshInterval, func(time.Time) tea.Msg {
+		return prTickMsg{}
+	})
+}
@@ -0,0 +1,381 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kateleext/perch/internal/git"
+)
+
+// buildPreview reads, highlights, and diffs a file into preview content.
+// It is shared by the synchronous refresh path and async preview loads, and
+// returns an empty preview once ctx is cancelled.
+func buildPreview(ctx context.Context, file git.FileStatus, dir, gitRoot string) PreviewContent {
+	if file.GitRoot != "" {
+		gitRoot = file.GitRoot
+	}
+	fullPath := filepath.Join(dir, file.Path)
+
+	deleted := strings.Contains(file.GitCode, "D")
+
+	// Symlinks preview their target when it's a file in the repo. Their own
+	// diff is just the target path, so it only goes in the header.
+	var link *symlinkInfo
+	var linkLabel string
+	if !deleted {
+		link = readSymlink(fullPath, gitRoot)
+	}
+	name := file.Path
+	if link != nil {
+		var linkDiff []git.DiffLine
+		if file.Status == "uncommitted" {
+			linkDiff, _ = git.GetFileDiff(ctx, gitRoot, file.FullPath)
+		}
+		linkLabel = symlinkLabel(link, linkDiff, strings.Contains(file.GitCode, "T"))
+		if link.resolved == "" {
+			return PreviewContent{Valid: true, Symlink: linkLabel, Message: fmt.Sprintf("%s\n%s", filepath.Base(file.Path), link.problem)}
+		}
+		fullPath = link.resolved
+		name = link.resolved
+	}
+	diffable := !deleted && link == nil
+
+	// Check if file type is unsupported
+	if isUnsupportedFile(name) {
+		reason := "not supported in perch"
+		if filepath.Ext(name) == "" {
+			reason = "no file extension — open in your editor"
+		}
+		return PreviewContent{Valid: true, Symlink: linkLabel, Message: fmt.Sprintf("%s\n%s", filepath.Base(file.Path), reason)}
+	}
+
+	// Get diff info
+	var diff []git.DiffLine
+	var diffStats git.DiffStats
+	if file.Status == "uncommitted" && diffable {
+		diff = fileDiff(ctx, file, gitRoot)
+		if file.OldFullPath != "" {
+			diffStats = git.GetRenameDiffStats(ctx, gitRoot, file.OldFullPath, file.FullPath)
+		} else {
+			diffStats = git.GetDiffStats(ctx, gitRoot, file.FullPath)
+		}
+	}
+
+	// A load superseded while git ran is dropped before the expensive part
+	if ctx.Err() != nil {
+		return PreviewContent{}
+	}
+
+	// Read file content; deleted files come from git so you can see what was lost
+	var content []byte
+	var deletedFrom string
+	var err error
+	if deleted {
+		content, deletedFrom, err = git.GetDeletedContent(ctx, gitRoot, file.FullPath)
+		if err != nil {
+			return PreviewContent{Valid: true, Message: fmt.Sprintf("%s was deleted", file.Path)}
+		}
+	} else if content, err = os.ReadFile(fullPath); err != nil {
+		return PreviewContent{Valid: true, Message: fmt.Sprintf("couldn't read %s", file.Path)}
+	}
+	var modTime time.Time
+	if info, err := os.Stat(fullPath); err == nil && diffable {
+		modTime = info.ModTime()
+	}
+
+	enc, encName := detectEncoding(content)
+	text, info := normalizeText(decodeString(enc, string(content)))
+	fileLines := strings.Split(text, "\n")
+	rows := git.OverlayDiff(fileLines, diff)
+	if enc != nil {
+		// Removed lines come from git in the file's own encoding too
+		for i, row := range rows {
+			if row.Type == "remove" {
+				rows[i].Content = decodeString(enc, row.Content)
+			}
+		}
+	}
+	if deleted {
+		diffStats.Deleted = len(fileLines)
+		if text == "" || strings.HasSuffix(text, "\n") {
+			diffStats.Deleted--
+		}
+	}
+	if ctx.Err() != nil {
+		return PreviewContent{}
+	}
+	rawLines, highlightedLines := previewLines(text, fileLines, rows, fullPath, dir, info)
+
+	pc := PreviewContent{
+		Valid:            true,
+		RawLines:         rawLines,
+		HighlightedLines: highlightedLines,
+		Diff:             rows,
+		DiffStats:        diffStats,
+		Encoding:         encName,
+		LineEnding:       info.lineEnding,
+		BOM:              info.bom,
+		Secrets:          scanSecrets(file.Path, rows),
+		DeletedFrom:      deletedFrom,
+		Symlink:          linkLabel,
+		Hash:             previewHash(file, content, diff, linkLabel),
+		modTime:          modTime,
+		diffHash:         diffHash(diff),
+	}
+	if isSensitiveFile(file.Path) || looksLikeKey(fileLines) {
+		// Show masked values by default; the unmasked preview is kept for m
+		revealed := pc
+		maskedLines, maskedRows, count := maskSecrets(file.Path, fileLines, rows)
+		if count > 0 {
+			pc.RawLines, pc.HighlightedLines = previewLines(strings.Join(maskedLines, "\n"), maskedLines, maskedRows, fullPath, dir, info)
+			pc.Diff = maskedRows
+			pc.Masked = count
+			pc.revealed = &revealed
+		}
+	}
+	return pc
+}
+
+// fileDiff returns an uncommitted file's worktree diff, against its
+// pre-rename path for renames
+func fileDiff(ctx context.Context, file git.FileStatus, gitRoot string) []git.DiffLine {
+	var diff []git.DiffLine
+	if file.OldFullPath != "" {
+		diff, _ = git.GetRenameDiff(ctx, gitRoot, file.OldFullPath, file.FullPath)
+	} else {
+		diff, _ = git.GetFileDiff(ctx, gitRoot, file.FullPath)
+	}
+	return diff
+}
+
+// diffHash fingerprints a diff, to tell whether a cached preview's is current
+func diffHash(diff []git.DiffLine) uint64 {
+	h := fnv.New64a()
+	for _, d := range diff {
+		fmt.Fprintf(h, "%s:%d:%d:%s\x00", d.Type, d.Number, d.OldNumber, d.Content)
+	}
+	return h.Sum64()
+}
+
+// cachedPreviewValid reports whether a cached preview still shows the file.
+// Committed files don't change; uncommitted ones must have the same mtime
+// and diff (git's diff cache makes checking it cheap) as when it was built,
+// and plain files (outside a repo) the same mtime.
+func cachedPreviewValid(file git.FileStatus, pc PreviewContent, dir, gitRoot string) bool {
+	if file.Status == "committed" {
+		return true
+	}
+	if pc.modTime.IsZero() {
+		return false
+	}
+	if file.GitRoot != "" {
+		gitRoot = file.GitRoot
+	}
+	info, err := os.Stat(filepath.Join(dir, file.Path))
+	if err != nil || !info.ModTime().Equal(pc.modTime) {
+		return false
+	}
+	if file.Status == "plain" {
+		return true
+	}
+	return diffHash(fileDiff(context.Background(), file, gitRoot)) == pc.diffHash
+}
+
+// previewHash fingerprints what a preview is built from, so a refresh that
+// finds the same content and diff can keep the existing preview and its caches
+func previewHash(file git.FileStatus, content []byte, diff []git.DiffLine, linkLabel string) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00", file.Path, file.GitCode, file.Commit, linkLabel)
+	h.Write(content)
+	for _, d := range diff {
+		fmt.Fprintf(h, "\x00%s:%d:%d:%s", d.Type, d.Number, d.OldNumber, d.Content)
+	}
+	return h.Sum64()
+}
+
+// previewLines highlights a file and aligns its raw and highlighted lines
+// with the display rows; dir is the watched directory
+func previewLines(text string, fileLines []string, rows []git.DiffLine, path, dir string, info textInfo) ([]string, []string) {
+	ansi := hasEmbeddedANSI(text)
+	var highlighted []string
+	if ansi {
+		// The file brings its own colors; show them rather than re-highlighting
+		highlighted = passthroughANSI(fileLines)
+	} else {
+		highlighted = highlightContent(text, fileLines, path, dir)
+	}
+	eolNotes := markEOLChanges(rows, info)
+
+	// Align raw/highlighted lines with the display rows. Removed lines come
+	// from the old file, so they are shown unhighlighted.
+	rawLines := make([]string, len(rows))
+	highlightedLines := make([]string, len(rows))
+	for i, row := range rows {
+		rawLines[i] = row.Content
+		if ansi {
+			rawLines[i] = plainANSI(row.Content)
+		}
+		if row.Type == "remove" || row.Number < 1 || row.Number > len(highlighted) {
+			highlightedLines[i] = rawLines[i]
+		} else {
+			highlightedLines[i] = highlighted[row.Number-1]
+		}
+		highlightedLines[i] = linkifyURLs(highlightedLines[i])
+		if note, ok := eolNotes[i]; ok {
+			highlightedLines[i] += dimStyle.Render("  (" + note + ")")
+		}
+	}
+
+	return rawLines, highlightedLines
+}
+
+// highlightContent picks the renderer for a file and returns one highlighted line per raw line
+func highlightContent(content string, rawLines []string, path, dir string) []string {
+	syntax, base := templateFor(path, content)
+	var highlightedLines []string
+	if isMarkdownFile(base) {
+		highlightedLines = highlightMarkdownLines(rawLines, path, dir)
+	} else if isSFCFile(base) {
+		highlightedLines = highlightSFC(rawLines, base)
+	} else {
+		highlightedLines = highlightCode(content, base)
+	}
+	if syntax != nil {
+		highlightedLines = syntax.style(highlightedLines)
+	}
+	return highlightedLines
+}
+
+// HasChanges reports whether the preview contains any added or removed lines
+func (pc PreviewContent) HasChanges() bool {
+	for _, row := range pc.Diff {
+		if row.Type == "add" || row.Type == "remove" {
+			return true
+		}
+	}
+	return false
+}
+
+// buildDiffPreview renders parsed diff records (e.g. a stash or commit patch)
+// as preview content, with file and hunk headers as dim separators
+func buildDiffPreview(diff []git.DiffLine) PreviewContent {
+	if len(diff) == 0 {
+		return PreviewContent{Valid: true, Message: "no changes"}
+	}
+	// Stashes, commits, and snapshots can hold .env files and keys too
+	diff, masked := maskDiff(diff)
+
+	rawLines := make([]string, len(diff))
+	highlightedLines := make([]string, len(diff))
+	currentFile := ""
+	for i, d := range diff {
+		rawLines[i] = d.Content
+		switch d.Type {
+		case "file":
+			currentFile = d.Content
+			highlightedLines[i] = cyanStyle.Render(d.Content)
+		case "hunk":
+			highlightedLines[i] = dimStyle.Render(d.Content)
+		case "context":
+			highlightedLines[i] = highlightCode(d.Content, currentFile)[0]
+		default:
+			highlightedLines[i] = d.Content
+		}
+	}
+
+	stats := git.DiffStats{}
+	for _, d := range diff {
+		switch d.Type {
+		case "add":
+			stats.Added++
+		case "remove":
+			stats.Deleted++
+		}
+	}
+
+	return PreviewContent{
+		Valid:            true,
+		RawLines:         rawLines,
+		HighlightedLines: highlightedLines,
+		Diff:             diff,
+		DiffStats:        stats,
+		Masked:           masked,
+	}
+}
+
+// DiffContext is the default number of unchanged lines the diff-only view
+// keeps around each change (adjustable at runtime with +/-)
+var DiffContext = 3
+
+// Collapsed returns the preview reduced to changed rows plus context lines,
+// with "···" separator rows where unchanged regions were dropped. Previews
+// without changes are returned whole. The result is cached on the preview.
+func (pc *PreviewContent) Collapsed(context int) *PreviewContent {
+	if !pc.HasChanges() {
+		return pc
+	}
+	if pc.collapsed != nil && pc.collapsedContext == context {
+		return pc.collapsed
+	}
+
+	keep := make([]bool, len(pc.Diff))
+	for i, row := range pc.Diff {
+		if row.Type != "add" && row.Type != "remove" {
+			continue
+		}
+		for j := i - context; j <= i+context; j++ {
+			if j >= 0 && j < len(keep) {
+				keep[j] = true
+			}
+		}
+	}
+
+	collapsed := &PreviewContent{Valid: true, DiffStats: pc.DiffStats}
+	skipped := false
+	for i := range pc.Diff {
+		if !keep[i] {
+			skipped = true
+			continue
+		}
+		if skipped && len(collapsed.Diff) > 0 {
+			collapsed.appendSeparator()
+		}
+		skipped = false
+		collapsed.RawLines = append(collapsed.RawLines, pc.RawLines[i])
+		collapsed.HighlightedLines = append(collapsed.HighlightedLines, pc.HighlightedLines[i])
+		collapsed.Diff = append(collapsed.Diff, pc.Diff[i])
+		if kind, ok := pc.Secrets[i]; ok {
+			if collapsed.Secrets == nil {
+				collapsed.Secrets = map[int]string{}
+			}
+			collapsed.Secrets[len(collapsed.Diff)-1] = kind
+		}
+	}
+	if skipped {
+		collapsed.appendSeparator()
+	}
+
+	pc.collapsed = collapsed
+	pc.collapsedContext = context
+	return collapsed
+}
+
+// separatorRow is the content of the rows marking skipped unchanged lines
+const separatorRow = "···"
+
+// appendSeparator adds a separator row marking skipped unchanged lines
+func (pc *PreviewContent) appendSeparator() {
+	pc.RawLines = append(pc.RawLines, separatorRow)
+	pc.HighlightedLines = append(pc.HighlightedLines, dimStyle.Render(separatorRow))
+	pc.Diff = append(pc.Diff, git.DiffLine{Content: separatorRow, Type: "hunk"})
+}
+
+// isSeparator reports whether row i is a separator rather than file content
+func (pc PreviewContent) isSeparator(i int) bool {
+	return i < len(pc.Diff) && pc.Diff[i].Type == "hunk" && pc.Diff[i].Content == separatorRow
+}
@@ -0,0 +1,79 @@
+package ui
+
+import "container/list"
+
+// previewCacheLines bounds the cache by the lines its previews hold, the
+// bulk of their memory (each line is kept raw and highlighted)
+const previewCacheLines = 200_000
+
+// previewCache keeps previews by file path, evicting the least recently
+// used once their lines add up past a budget
+type previewCache struct {
+	maxLines int
+	lines    int
+	order    *list.List // most recently used first
+	entries  map[string]*list.Element
+}
+
+type previewCacheEntry struct {
+	path    string
+	preview PreviewContent
+}
+
+func newPreviewCache(maxLines int) *previewCache {
+	return &previewCache{maxLines: maxLines, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// previewCost is what a preview counts against the budget
+func previewCost(pc PreviewContent) int {
+	return len(pc.RawLines) + 1
+}
+
+// get returns the preview cached for path, marking it recently used
+func (c *previewCache) get(path string) (PreviewContent, bool) {
+	el, ok := c.entries[path]
+	if !ok {
+		return PreviewContent{}, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*previewCacheEntry).preview, true
+}
+
+// has reports whether path is cached without counting as a use
+func (c *previewCache) has(path string) bool {
+	_, ok := c.entries[path]
+	return ok
+}
+
+// put caches a preview, evicting old ones to stay within budget. A preview
+// bigger than the whole budget isn't kept.
+func (c *previewCache) put(path string, pc PreviewContent) {
+	c.remove(path)
+	cost := previewCost(pc)
+	if cost > c.maxLines {
+		return
+	}
+	for c.lines+cost > c.maxLines {
+		c.remove(c.order.Back().Value.(*previewCacheEntry).path)
+	}
+	c.entries[path] = c.order.PushFront(&previewCacheEntry{path: path, preview: pc})
+	c.lines += cost
+}
+
+// remove drops path from the cache, if it's there
+func (c *previewCache) remove(path string) {
+	el, ok := c.entries[path]
+	if !ok {
+		return
+	}
+	c.lines -= previewCost(el.Value.(*previewCacheEntry).preview)
+	c.order.Remove(el)
+	delete(c.entries, path)
+}
+
+// clear empties the cache
+func (c *previewCache) clear() {
+	c.order.Init()
+	c.entries = make(map[string]*list.Element)
+	c.lines = 0
+}
@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kateleext/perch/internal/clipboard"
+	"github.com/kateleext/perch/internal/git"
+)
+
+// openedMsg reports the result of opening a URL in the browser
+type openedMsg struct {
+	url string
+	err error
+}
+
+// openURL hands a URL to the system opener, reaping it once it exits
+func openURL(u string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", u)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", u)
+	default:
+		cmd = exec.Command("xdg-open", u)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// remoteURLFunc returns a function building the selected file's web URL,
+// at the current branch or, when pinned, at the HEAD commit
+func (m *Model) remoteURLFunc(pinned bool, line int) func() (string, error) {
+	if m.selected < 0 || m.selected >= len(m.files) {
+		return nil
+	}
+	file := m.files[m.selected]
+	gitRoot := m.gitRoot
+	if file.GitRoot != "" {
+		gitRoot = file.GitRoot
+	}
+
+	return func() (string, error) {
+		ctx := context.Background()
+		remote, err := git.GetRemoteURL(ctx, gitRoot)
+		if err != nil {
+			return "", err
+		}
+		getRef := git.GetCurrentRef
+		if pinned {
+			getRef = git.GetHeadCommit
+		}
+		ref, err := getRef(ctx, gitRoot)
+		if err != nil {
+			return "", err
+		}
+		// The worktree's line numbers only match HEAD's once mapped through
+		// the file's changes
+		if pinned && line > 0 && file.Status == "uncommitted" && file.GitCode != "  " {
+			if file.GitCode == "??" {
+				return "", fmt.Errorf("%s isn't committed yet", file.Path)
+			}
+			if line, err = git.HeadLine(ctx, gitRoot, file.FullPath, line); err != nil {
+				return "", err
+			}
+		}
+		return git.WebURL(remote, ref, file.FullPath, line)
+	}
+}
+
+// openRemoteCmd opens the selected file on its remote host at the current branch
+func (m *Model) openRemoteCmd() tea.Cmd {
+	buildURL := m.remoteURLFunc(false, 0)
+	if buildURL == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		u, err := buildURL()
+		if err != nil {
+			return openedMsg{err: err}
+		}
+		return openedMsg{url: u, err: openURL(u)}
+	}
+}
+
+// copyPermalinkCmd copies a link to the selected file pinned to the HEAD
+// commit, anchored at the top visible line as it was at HEAD
+func (m *Model) copyPermalinkCmd() tea.Cmd {
+	line, _ := m.visibleLineRange()
+	buildURL := m.remoteURLFunc(true, line)
+	if buildURL == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		u, err := buildURL()
+		if err != nil {
+			return copiedMsg{err: err}
+		}
+		return copiedMsg{what: u, err: clipboard.Copy(u)}
+	}
+}
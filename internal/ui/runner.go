@@ -0,0 +1,159 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// OnChange is a shell command run after each debounced file change ("" = off)
+var OnChange string
+
+// RunTimeout bounds one on-change run, so a hung command can't block the
+// runs after it
+var RunTimeout = 10 * time.Minute
+
+// runGrace is how long after a run finishes its own writes are still
+// expected; the watcher's debounce reports them just after runDoneMsg
+const runGrace = time.Second
+
+// runPaneHeight is the height of the expanded output pane, divider included
+const runPaneHeight = 8
+
+// runState tracks the on-change command
+type runState struct {
+	running  bool
+	output   string
+	exitCode int
+	err      error // set when the command couldn't be started
+	duration time.Duration
+	finished bool      // true once any run has completed
+	doneAt   time.Time // when the last run finished
+	paneOpen bool      // output pane expanded
+	changed  time.Time // first change reported during the current run
+}
+
+// runDoneMsg carries a finished on-change run
+type runDoneMsg struct {
+	output   string
+	exitCode int
+	err      error
+	duration time.Duration
+}
+
+// startRun runs OnChange in the target dir, for a change the watcher saw.
+// A change during a run queues one more run after it; changes within
+// runGrace of a run's end are ignored, so commands that write into the tree
+// (builds, coverage files) don't retrigger themselves forever.
+func (m *Model) startRun() tea.Cmd {
+	if OnChange == "" {
+		return nil
+	}
+	if m.run.running {
+		if m.run.changed.IsZero() {
+			m.run.changed = time.Now()
+		}
+		return nil
+	}
+	if time.Since(m.run.doneAt) < runGrace {
+		return nil
+	}
+	return m.launchRun()
+}
+
+// launchRun starts OnChange now
+func (m *Model) launchRun() tea.Cmd {
+	m.run.running = true
+	m.run.changed = time.Time{}
+	dir := m.dir
+	return func() tea.Msg {
+		start := time.Now()
+		ctx, cancel := context.WithTimeout(context.Background(), RunTimeout)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, "sh", "-c", OnChange)
+		cmd.Dir = dir
+		// Children of sh can hold the output open after it is killed
+		cmd.WaitDelay = 2 * time.Second
+		output, err := cmd.CombinedOutput()
+		msg := runDoneMsg{output: string(output), duration: time.Since(start)}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			msg.err = fmt.Errorf("%s timed out after %s", OnChange, RunTimeout)
+		} else if exitErr, ok := err.(*exec.ExitError); ok {
+			msg.exitCode = exitErr.ExitCode()
+		} else if err != nil {
+			msg.err = err
+		}
+		return msg
+	}
+}
+
+// finishRun records a completed run, starting the next one when files
+// changed while it ran (and not just as it finished)
+func (m *Model) finishRun(msg runDoneMsg) tea.Cmd {
+	changed := m.run.changed
+	m.run.running = false
+	m.run.finished = true
+	m.run.doneAt = time.Now()
+	m.run.output = msg.output
+	m.run.exitCode = msg.exitCode
+	m.run.err = msg.err
+	m.run.duration = msg.duration
+	if !changed.IsZero() && m.run.doneAt.Sub(changed) > runGrace {
+		return m.launchRun()
+	}
+	m.run.changed = time.Time{}
+	return nil
+}
+
+// runStatus is a one-line summary of the last run, e.g. "✓ go test ./... 1.2s"
+func (m Model) runStatus() string {
+	switch {
+	case m.run.running:
+		return dimStyle.Render("… " + OnChange)
+	case !m.run.finished:
+		return dimStyle.Render("· " + OnChange)
+	case m.run.err != nil:
+		return lineDelGutter.Render("✗ " + m.run.err.Error())
+	case m.run.exitCode != 0:
+		return lineDelGutter.Render(fmt.Sprintf("✗ %s exit %d", OnChange, m.run.exitCode))
+	default:
+		return lineAddGutter.Render("✓ "+OnChange) + dimStyle.Render(" "+m.run.duration.Round(100*time.Millisecond).String())
+	}
+}
+
+// paneHeight is how many rows the output pane takes from the preview
+func (m Model) paneHeight() int {
+	if OnChange == "" || !m.run.paneOpen {
+		return 0
+	}
+	return runPaneHeight
+}
+
+// renderRunPane renders the expanded pane: a status divider and the tail of
+// the last run's output
+func (m Model) renderRunPane() string {
+	var lines []string
+	lines = append(lines, padLine(m.runStatus(), keyStyle.Render("R")+dimStyle.Render(" hide  "), m.width))
+
+	output := strings.Split(strings.TrimRight(stripANSIColors(m.run.output), "\n"), "\n")
+	if len(output) > runPaneHeight-1 {
+		output = output[len(output)-(runPaneHeight-1):]
+	}
+	maxLen := m.width - 2
+	for _, line := range output {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		if VisibleWidth(line) > maxLen {
+			line, _, _ = sliceANSIAware(line, maxLen)
+		}
+		lines = append(lines, "  "+dimStyle.Render(line))
+	}
+	for len(lines) < runPaneHeight {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,275 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kateleext/perch/internal/git"
+)
+
+// secretStyle colors the gutter marker of an added line that looks like it
+// holds a secret
+var secretStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#c9a35a"))
+
+// maskGlyph stands in for a hidden value
+const maskGlyph = "•••••"
+
+// assignmentPattern matches KEY=value, export KEY=value, key: value, and
+// "key": "value" lines, capturing the part before the value, the value, and
+// a trailing comma
+var assignmentPattern = regexp.MustCompile(`^(\s*(?:export\s+)?["']?[\w.\-]+["']?\s*[=:]\s*)(.*?)(\s*,?\s*)$`)
+
+// isSensitiveFile reports whether a file's values are masked by default:
+// .env files, anything named like credentials, and key files
+func isSensitiveFile(path string) bool {
+	base := strings.ToLower(filepath.Base(path))
+	switch {
+	case base == ".env", strings.HasPrefix(base, ".env."), strings.HasSuffix(base, ".env"):
+		return base != ".env.example" && base != ".env.sample" && base != ".env.template"
+	case strings.Contains(base, "credentials"), base == ".netrc":
+		return true
+	}
+	return isKeyFile(path)
+}
+
+// isKeyFile reports whether a file holds key material, which is masked
+// whole rather than value by value
+func isKeyFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".pem", ".key", ".p8":
+		return true
+	}
+	switch strings.ToLower(filepath.Base(path)) {
+	case "id_rsa", "id_dsa", "id_ecdsa", "id_ed25519", "id_ecdsa_sk", "id_ed25519_sk":
+		return true
+	}
+	return false
+}
+
+// looksLikeKey reports whether lines start with private key armor, for key
+// files whatever their name
+func looksLikeKey(lines []string) bool {
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		return strings.HasPrefix(line, "-----BEGIN ") && strings.Contains(line, "PRIVATE KEY-----")
+	}
+	return false
+}
+
+// maskLine hides the secret part of one line, reporting whether it did
+func maskLine(line string, keyFile bool) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";") {
+		return line, false
+	}
+	if keyFile {
+		// Keep the BEGIN/END armor so it's clear what the file holds
+		if strings.HasPrefix(trimmed, "-----") {
+			return line, false
+		}
+		return maskGlyph, true
+	}
+	m := assignmentPattern.FindStringSubmatch(line)
+	if m == nil || m[2] == "" || m[2] == "{" || m[2] == "[" {
+		return line, false
+	}
+	return m[1] + maskGlyph + m[3], true
+}
+
+// maskSecrets returns masked copies of a sensitive file's lines and display
+// rows, with the number of rows masked
+func maskSecrets(path string, fileLines []string, rows []git.DiffLine) ([]string, []git.DiffLine, int) {
+	keyFile := isKeyFile(path) || looksLikeKey(fileLines)
+	lines := make([]string, len(fileLines))
+	for i, line := range fileLines {
+		lines[i], _ = maskLine(line, keyFile)
+	}
+	masked := make([]git.DiffLine, len(rows))
+	count := 0
+	for i, row := range rows {
+		masked[i] = row
+		var ok bool
+		if masked[i].Content, ok = maskLine(row.Content, keyFile); ok {
+			count++
+		}
+	}
+	return lines, masked, count
+}
+
+// maskDiff masks the rows of the sensitive files in a diff, which may span
+// several files, returning a copy and the number of rows masked
+func maskDiff(diff []git.DiffLine) ([]git.DiffLine, int) {
+	masked := diff
+	count := 0
+	for start := 0; start < len(diff); {
+		// One file's rows run from its "file" row to the next one
+		end := start + 1
+		for end < len(diff) && diff[end].Type != "file" {
+			end++
+		}
+		path := ""
+		if diff[start].Type == "file" {
+			path = diff[start].Content
+		}
+		var lines []string
+		for _, d := range diff[start:end] {
+			if d.Type == "context" || d.Type == "add" || d.Type == "remove" {
+				lines = append(lines, d.Content)
+			}
+		}
+		keyFile := isKeyFile(path) || looksLikeKey(lines)
+		if keyFile || isSensitiveFile(path) {
+			for i := start; i < end; i++ {
+				d := diff[i]
+				if d.Type != "context" && d.Type != "add" && d.Type != "remove" {
+					continue
+				}
+				if content, ok := maskLine(d.Content, keyFile); ok {
+					if count == 0 {
+						masked = append([]git.DiffLine(nil), diff...)
+					}
+					masked[i].Content = content
+					count++
+				}
+			}
+		}
+		start = end
+	}
+	return masked, count
+}
+
+// toggleSecrets reveals or re-masks the values of a sensitive file
+func (m *Model) toggleSecrets() {
+	if m.preview.revealed == nil {
+		m.statusMessage = "nothing masked in this file"
+		return
+	}
+	m.revealSecrets = !m.revealSecrets
+	if m.revealSecrets {
+		m.statusMessage = "secrets revealed · m to mask"
+	} else {
+		m.statusMessage = "secrets masked"
+	}
+	m.viewport.SetContent(m.renderPreviewContent())
+}
+
+// tokenPatterns are well-known credential formats, checked before entropy
+var tokenPatterns = []struct {
+	kind    string
+	pattern *regexp.Regexp
+}{
+	{"private key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY( BLOCK)?-----`)},
+	{"AWS access key", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{60,})\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"Stripe key", regexp.MustCompile(`\b[sr]k_live_[A-Za-z0-9]{20,}\b`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+}
+
+// tokenSplit separates the candidate tokens of a line for the entropy check
+var tokenSplit = regexp.MustCompile(`[^A-Za-z0-9+/=_\-]+`)
+
+// Random-looking tokens at least this long and this dense in bits per
+// character are flagged
+const (
+	entropyMinLen = 24
+	entropyMin    = 4.0
+)
+
+// skipSecretScan reports whether a file is full of hashes by design
+func skipSecretScan(path string) bool {
+	switch strings.ToLower(filepath.Base(path)) {
+	case "go.sum", "package-lock.json", "yarn.lock", "pnpm-lock.yaml", "cargo.lock", "gemfile.lock", "poetry.lock", "composer.lock":
+		return true
+	}
+	return false
+}
+
+// findSecret names what kind of secret a line seems to contain, or ""
+func findSecret(line string) string {
+	for _, p := range tokenPatterns {
+		if p.pattern.MatchString(line) {
+			return p.kind
+		}
+	}
+	for _, tok := range tokenSplit.Split(line, -1) {
+		if len(tok) >= entropyMinLen && mixedClasses(tok) && shannonEntropy(tok) >= entropyMin {
+			return "high-entropy string"
+		}
+	}
+	return ""
+}
+
+// mixedClasses reports whether a token mixes upper case, lower case, and
+// digits, which hex hashes, UUIDs, and identifiers don't
+func mixedClasses(tok string) bool {
+	var upper, lower, digit bool
+	for _, r := range tok {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	return upper && lower && digit
+}
+
+// shannonEntropy is the bits of information per character of s
+func shannonEntropy(s string) float64 {
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+	n := float64(len(s))
+	h := 0.0
+	for _, c := range counts {
+		p := float64(c) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// scanSecrets finds added rows that look like they hold secrets, keyed by
+// row index
+func scanSecrets(path string, rows []git.DiffLine) map[int]string {
+	if skipSecretScan(path) {
+		return nil
+	}
+	var found map[int]string
+	for i, row := range rows {
+		if row.Type != "add" {
+			continue
+		}
+		if kind := findSecret(row.Content); kind != "" {
+			if found == nil {
+				found = map[int]string{}
+			}
+			found[i] = kind
+		}
+	}
+	return found
+}
+
+// secretNotice summarizes a preview's secret warnings for the header
+func (pc PreviewContent) secretNotice() string {
+	switch len(pc.Secrets) {
+	case 0:
+		return ""
+	case 1:
+		for _, kind := range pc.Secrets {
+			return "! possible " + kind
+		}
+	}
+	return fmt.Sprintf("! %d possible secrets", len(pc.Secrets))
+}
@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Vue and Svelte single-file components hold template, script, and style
+// blocks; one lexer can't do all three, so each block is highlighted as its
+// own language and the lines are put back in place.
+
+var (
+	sfcOpenRegex = regexp.MustCompile(`^<(template|script|style)\b[^>]*>`)
+	sfcLangRegex = regexp.MustCompile(`\blang=["']?([\w-]+)`)
+
+	// Svelte blocks and expressions: {#if}, {:else}, {/each}, {@html}, {expr}
+	svelteTagRegex = regexp.MustCompile(`\{[#:/@]?[^{}]*\}`)
+)
+
+// sfcLangExt maps a block's lang attribute to the extension whose lexer
+// highlights it
+var sfcLangExt = map[string]string{
+	"ts": ".ts", "typescript": ".ts", "tsx": ".tsx", "jsx": ".jsx", "coffee": ".coffee",
+	"scss": ".scss", "sass": ".sass", "less": ".less", "stylus": ".styl", "postcss": ".css",
+	"pug": ".pug", "html": ".html",
+}
+
+// isSFCFile reports whether a file is a Vue or Svelte component
+func isSFCFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".vue", ".svelte":
+		return true
+	}
+	return false
+}
+
+// sfcSection is a run of lines highlighted with one lexer
+type sfcSection struct {
+	start, end int    // line range, end exclusive
+	ext        string // extension picking the lexer
+}
+
+// splitSFC divides a component into sections: each top-level block's body
+// in its own language, and everything else (the block tags, Svelte markup)
+// as HTML
+func splitSFC(lines []string) []sfcSection {
+	var sections []sfcSection
+	add := func(start, end int, ext string) {
+		if end > start {
+			sections = append(sections, sfcSection{start, end, ext})
+		}
+	}
+	markupStart := 0
+	for i := 0; i < len(lines); i++ {
+		m := sfcOpenRegex.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+		tag := m[1]
+		closing := "</" + tag + ">"
+		if strings.Contains(lines[i][len(m[0]):], closing) {
+			continue // one-line block, left as markup
+		}
+		end := i + 1
+		// Top-level blocks close at the left margin; indented closers are nested
+		for end < len(lines) && !strings.HasPrefix(lines[end], closing) {
+			end++
+		}
+		if end == len(lines) {
+			break // unclosed: leave the rest as markup
+		}
+
+		ext := map[string]string{"template": ".html", "script": ".js", "style": ".css"}[tag]
+		if lang := sfcLangRegex.FindStringSubmatch(m[0]); lang != nil {
+			if e, ok := sfcLangExt[strings.ToLower(lang[1])]; ok {
+				ext = e
+			}
+		}
+		add(markupStart, i+1, ".html")
+		add(i+1, end, ext)
+		markupStart = end
+		i = end
+	}
+	add(markupStart, len(lines), ".html")
+	return sections
+}
+
+// highlightSFC highlights each section of a component with its own lexer,
+// styling {{ }} in Vue templates and { } blocks in Svelte markup
+// over the HTML
+func highlightSFC(rawLines []string, path string) []string {
+	tags := handlebarsTagRegex
+	if strings.EqualFold(filepath.Ext(path), ".svelte") {
+		tags = svelteTagRegex
+	}
+	result := make([]string, len(rawLines))
+	for _, s := range splitSFC(rawLines) {
+		highlighted := highlightCode(strings.Join(rawLines[s.start:s.end], "\n"), "block"+s.ext)
+		if s.ext == ".html" {
+			for i, line := range highlighted {
+				highlighted[i] = styleTemplateTags(line, tags)
+			}
+		}
+		copy(result[s.start:s.end], highlighted)
+	}
+	return result
+}
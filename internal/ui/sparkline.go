@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kateleext/perch/internal/git"
+)
+
+// sparkSamples caps how many working-tree size samples the header shows
+const sparkSamples = 24
+
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// recordSizeSample appends the working tree's diff size if it changed since
+// the last sample, keeping the most recent sparkSamples
+func (m *Model) recordSizeSample(stats git.DiffStats) {
+	m.treeStats = stats
+	size := stats.Added + stats.Deleted
+	if n := len(m.sizeSamples); n > 0 && m.sizeSamples[n-1] == size {
+		return
+	}
+	m.sizeSamples = append(m.sizeSamples, size)
+	if len(m.sizeSamples) > sparkSamples {
+		m.sizeSamples = m.sizeSamples[len(m.sizeSamples)-sparkSamples:]
+	}
+}
+
+// sparkline scales values onto block characters, lowest to highest
+func sparkline(values []int) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo = min(lo, v)
+		hi = max(hi, v)
+	}
+	out := make([]rune, len(values))
+	for i, v := range values {
+		level := 0
+		if hi > lo {
+			level = (v - lo) * (len(sparkLevels) - 1) / (hi - lo)
+		}
+		out[i] = sparkLevels[level]
+	}
+	return string(out)
+}
+
+// renderSparkline renders the session's diff-size history (the current
+// totals are in the change summary beside the title)
+func (m Model) renderSparkline() string {
+	if len(m.sizeSamples) < 2 {
+		return ""
+	}
+	return dimStyle.Render(sparkline(m.sizeSamples))
+}
+
+// changeSummary sums up the working tree for the list header, as
+// "4 files changed, +120 −32, 2 untracked" or compactly "4 files +120 −32"
+func (m Model) changeSummary(compact bool) string {
+	changed, untracked := 0, 0
+	for _, f := range m.files {
+		switch {
+		case f.Status != "uncommitted" || f.GitCode == "  ":
+		case f.GitCode == "??":
+			untracked++
+		default:
+			changed++
+		}
+	}
+	if changed == 0 && untracked == 0 {
+		return ""
+	}
+
+	var parts []string
+	if changed > 0 {
+		noun := "files"
+		if changed == 1 {
+			noun = "file"
+		}
+		if compact {
+			parts = append(parts, dimStyle.Render(fmt.Sprintf("%d %s", changed, noun)))
+		} else {
+			parts = append(parts, dimStyle.Render(fmt.Sprintf("%d %s changed", changed, noun)))
+		}
+	}
+	if stats := renderDiffStats(m.treeStats); stats != "" {
+		parts = append(parts, stats)
+	}
+	if untracked > 0 && !compact {
+		parts = append(parts, dimStyle.Render(fmt.Sprintf("%d untracked", untracked)))
+	}
+	if compact {
+		return strings.Join(parts, " ")
+	}
+	return strings.Join(parts, dimStyle.Render(", "))
+}
@@ -0,0 +1,269 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kateleext/perch/internal/git"
+	"github.com/mattn/go-runewidth"
+)
+
+// stashesLoadedMsg carries the stash list for the stash browser
+type stashesLoadedMsg struct {
+	stashes []git.StashEntry
+	err     error
+}
+
+// stashPreviewMsg carries the rendered diff of one stash entry
+type stashPreviewMsg struct {
+	ref     string
+	preview PreviewContent
+}
+
+// stashActionMsg reports the result of apply/pop/drop
+type stashActionMsg struct {
+	action string
+	ref    string
+	err    error
+}
+
+func (m Model) loadStashes() tea.Msg {
+	stashes, err := git.GetStashes(context.Background(), m.gitRoot)
+	return stashesLoadedMsg{stashes: stashes, err: err}
+}
+
+// loadStashPreview loads the selected stash's patch in the background
+func (m Model) loadStashPreview() tea.Cmd {
+	if m.stashSelected < 0 || m.stashSelected >= len(m.stashes) {
+		return nil
+	}
+	ref := m.stashes[m.stashSelected].Ref
+	gitRoot := m.gitRoot
+	return func() tea.Msg {
+		diff, err := git.GetStashDiff(context.Background(), gitRoot, ref)
+		if err != nil {
+			return stashPreviewMsg{ref: ref, preview: PreviewContent{Valid: true, Message: "couldn't read " + ref}}
+		}
+		return stashPreviewMsg{ref: ref, preview: buildDiffPreview(diff)}
+	}
+}
+
+// runStashAction applies, pops, or drops a stash entry
+func (m Model) runStashAction(action, ref string) tea.Cmd {
+	gitRoot := m.gitRoot
+	return func() tea.Msg {
+		var err error
+		switch action {
+		case "applied":
+			err = git.ApplyStash(context.Background(), gitRoot, ref)
+		case "popped":
+			err = git.PopStash(context.Background(), gitRoot, ref)
+		case "dropped":
+			err = git.DropStash(context.Background(), gitRoot, ref)
+		}
+		return stashActionMsg{action: action, ref: ref, err: err}
+	}
+}
+
+// toastFor is how long a quick stash/pop confirmation outlives refreshes
+const toastFor = 3 * time.Second
+
+// quickStashMsg reports a quick stash or pop from the file list
+type quickStashMsg struct {
+	text string
+	err  error
+}
+
+// stashMessage names a quick stash after the files in it, e.g.
+// "perch: main.go, util.go +3 more"
+func stashMessage(files []git.FileStatus) string {
+	var names []string
+	for _, f := range files {
+		if f.Status == "uncommitted" {
+			names = append(names, filepath.Base(f.Path))
+		}
+	}
+	if len(names) > 2 {
+		return fmt.Sprintf("perch: %s +%d more", strings.Join(names[:2], ", "), len(names)-2)
+	}
+	return "perch: " + strings.Join(names, ", ")
+}
+
+// quickStash stashes the working tree without opening the stash browser
+func (m *Model) quickStash() tea.Cmd {
+	n := m.dirtyCount()
+	if n == 0 {
+		m.statusMessage = "nothing to stash"
+		return nil
+	}
+	gitRoot, message := m.gitRoot, stashMessage(m.files)
+	return func() tea.Msg {
+		if err := git.PushStash(context.Background(), gitRoot, message); err != nil {
+			return quickStashMsg{err: err}
+		}
+		noun := "files"
+		if n == 1 {
+			noun = "file"
+		}
+		return quickStashMsg{text: fmt.Sprintf("stashed %d %s as %q", n, noun, message)}
+	}
+}
+
+// quickPop pops the newest stash without opening the stash browser
+func (m Model) quickPop() tea.Cmd {
+	gitRoot := m.gitRoot
+	return func() tea.Msg {
+		stashes, err := git.GetStashes(context.Background(), gitRoot)
+		if err != nil {
+			return quickStashMsg{err: err}
+		}
+		if len(stashes) == 0 {
+			return quickStashMsg{text: "no stash to pop"}
+		}
+		if err := git.PopStash(context.Background(), gitRoot, stashes[0].Ref); err != nil {
+			return quickStashMsg{err: err}
+		}
+		// Named stashes are listed as "On main: <message>"
+		message := stashes[0].Message
+		if _, rest, ok := strings.Cut(message, ": "); ok && strings.HasPrefix(message, "On ") {
+			message = rest
+		}
+		return quickStashMsg{text: "popped " + message}
+	}
+}
+
+// enterStashMode swaps the file list for the stash browser
+func (m *Model) enterStashMode() tea.Cmd {
+	m.mode = modeStash
+	m.stashSelected = 0
+	m.stashScroll = 0
+	m.confirmDrop = false
+	m.preview = PreviewContent{Valid: true, Message: "loading stashes…"}
+	m.viewport.SetContent(m.renderPreviewContent())
+	return m.loadStashes
+}
+
+// exitStashMode returns to the file list and restores its preview
+func (m *Model) exitStashMode() {
+	m.mode = modeFiles
+	m.confirmDrop = false
+	m.lastSelectedFile = -1
+	m.updatePreview()
+}
+
+// updateStash handles keys while the stash browser is open
+func (m Model) updateStash(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	confirming := m.confirmDrop
+	m.confirmDrop = false
+
+	switch key {
+	case "q", "ctrl+c":
+		return m, m.quit()
+	case "esc", "s":
+		m.statusMessage = ""
+		m.exitStashMode()
+		return m, nil
+	case "up":
+		if m.stashSelected > 0 {
+			m.stashSelected--
+			if m.stashSelected < m.stashScroll {
+				m.stashScroll = m.stashSelected
+			}
+			return m, m.loadStashPreview()
+		}
+	case "down":
+		if m.stashSelected < len(m.stashes)-1 {
+			m.stashSelected++
+			if visible := m.listRows() - 1; m.stashSelected >= m.stashScroll+visible {
+				m.stashScroll = m.stashSelected - visible + 1
+			}
+			return m, m.loadStashPreview()
+		}
+	case "j":
+		m.viewport.LineDown(1)
+	case "k":
+		m.viewport.LineUp(1)
+	case "g":
+		m.viewport.GotoTop()
+	case "G":
+		m.viewport.GotoBottom()
+	case "ctrl+d":
+		m.viewport.HalfViewDown()
+	case "ctrl+u":
+		m.viewport.HalfViewUp()
+	case "a", "p", "d":
+		if m.stashSelected < 0 || m.stashSelected >= len(m.stashes) {
+			return m, nil
+		}
+		ref := m.stashes[m.stashSelected].Ref
+		switch key {
+		case "a":
+			return m, m.runStashAction("applied", ref)
+		case "p":
+			return m, m.runStashAction("popped", ref)
+		case "d":
+			// Dropping is destructive, so it takes a second press
+			if !confirming {
+				m.confirmDrop = true
+				m.statusMessage = "press d again to drop " + ref
+				return m, nil
+			}
+			return m, m.runStashAction("dropped", ref)
+		}
+	}
+	return m, nil
+}
+
+// renderStashList renders the stash browser in place of the file list
+func (m Model) renderStashList() string {
+	var lines []string
+	header := dimStyle.Render("STASHES")
+	hint := keyStyle.Render("a") + dimStyle.Render(" apply  ") +
+		keyStyle.Render("p") + dimStyle.Render(" pop  ") +
+		keyStyle.Render("d") + dimStyle.Render(" drop  ") +
+		keyStyle.Render("esc") + dimStyle.Render(" back")
+	lines = append(lines, padLine(header, hint, m.width))
+
+	if len(m.stashes) == 0 {
+		lines = append(lines, "  "+dimStyle.Render("no stashes"))
+	}
+
+	end := m.stashScroll + m.listRows() - 1
+	if end > len(m.stashes) {
+		end = len(m.stashes)
+	}
+	maxLen := m.width - 8
+	if maxLen < 10 {
+		maxLen = 10
+	}
+	for i := m.stashScroll; i < end; i++ {
+		s := m.stashes[i]
+		text := runewidth.Truncate(s.Ref+"  "+s.Message, maxLen, "...")
+		if i == m.stashSelected {
+			lines = append(lines, padLine(selectedStyle.Render(cursorMarker()+text), dimStyle.Render(s.TimeAgo), m.width))
+		} else {
+			lines = append(lines, padLine("  "+text, dimStyle.Render(s.TimeAgo), m.width))
+		}
+	}
+
+	for len(lines) < m.listRows() {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// renderStashHeader renders the preview header for the selected stash
+func (m Model) renderStashHeader() string {
+	if m.stashSelected < 0 || m.stashSelected >= len(m.stashes) {
+		return "\n"
+	}
+	s := m.stashes[m.stashSelected]
+	header := "  " + cyanStyle.Render(s.Ref) + "  " + dimStyle.Render(s.TimeAgo)
+	hint := keyStyle.Render("j k") + dimStyle.Render(" scroll  ")
+	return padLine(header, hint, m.width) + "\n"
+}
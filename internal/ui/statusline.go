@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kateleext/perch/internal/git"
+)
+
+// StatusLine puts a one-line repo summary (branch, dirty files,
+// ahead/behind, stashes) at the "top" or "bottom" of the screen; "" hides it
+var StatusLine string
+
+// repoStatus is what the status line shows beyond the file list
+type repoStatus struct {
+	branch  string
+	stashes int
+}
+
+// loadRepoStatus reads the branch and stash count for the status line
+func loadRepoStatus(dir string) repoStatus {
+	ctx := context.Background()
+	var s repoStatus
+	if ref, err := git.GetCurrentRef(ctx, dir); err == nil {
+		s.branch = ref
+		if len(ref) == 40 && !strings.Contains(ref, "/") {
+			s.branch = ref[:7] + " (detached)"
+		}
+	}
+	if stashes, err := git.GetStashes(ctx, dir); err == nil {
+		s.stashes = len(stashes)
+	}
+	return s
+}
+
+// statusLineHeight is the rows the status line takes
+func (m Model) statusLineHeight() int {
+	if StatusLine == "" || m.plainDir {
+		return 0
+	}
+	return 1
+}
+
+// dirtyCount counts the uncommitted files in the list, leaving out clean
+// pinned or followed entries
+func (m Model) dirtyCount() int {
+	n := 0
+	for _, f := range m.files {
+		if f.Status == "uncommitted" && f.GitCode != "  " {
+			n++
+		}
+	}
+	return n
+}
+
+// renderStatusLine renders the status line, or "" when it's off
+func (m Model) renderStatusLine() string {
+	if m.statusLineHeight() == 0 {
+		return ""
+	}
+	plural := func(n int, one, many string) string {
+		if n == 1 {
+			return fmt.Sprintf("%d %s", n, one)
+		}
+		return fmt.Sprintf("%d %s", n, many)
+	}
+	var parts []string
+	if m.repoStatus.branch != "" {
+		if Accessible {
+			parts = append(parts, "branch "+m.repoStatus.branch)
+		} else {
+			parts = append(parts, cyanStyle.Render("⎇ "+m.repoStatus.branch))
+		}
+	}
+	if n := m.dirtyCount(); n > 0 {
+		parts = append(parts, plural(n, "dirty file", "dirty files"))
+	} else {
+		parts = append(parts, "clean")
+	}
+	if Accessible {
+		if s := m.sync.accessible(); s != "" {
+			parts = append(parts, s)
+		}
+	} else if s := m.sync.render(); s != "" {
+		parts = append(parts, s)
+	}
+	if m.repoStatus.stashes > 0 {
+		parts = append(parts, plural(m.repoStatus.stashes, "stash", "stashes"))
+	}
+	sep := dimStyle.Render(" · ")
+	if Accessible {
+		sep = ", "
+	}
+	return padLine(" "+strings.Join(parts, sep), "", m.width) + "\n"
+}
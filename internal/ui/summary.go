@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kateleext/perch/internal/git"
+)
+
+// summaryLoadedMsg carries today's aggregated activity
+type summaryLoadedMsg struct {
+	summary git.DaySummary
+	err     error
+}
+
+// startOfDay returns local midnight for t
+func startOfDay(t time.Time) time.Time {
+	y, mo, d := t.Date()
+	return time.Date(y, mo, d, 0, 0, 0, 0, t.Location())
+}
+
+func (m Model) loadSummary() tea.Msg {
+	summary, err := git.GetDaySummary(context.Background(), m.dir, startOfDay(time.Now()))
+	return summaryLoadedMsg{summary: summary, err: err}
+}
+
+// enterSummaryMode swaps the file list for today's progress summary
+func (m *Model) enterSummaryMode() tea.Cmd {
+	m.mode = modeSummary
+	m.summary = git.DaySummary{}
+	m.preview = PreviewContent{Valid: true, Message: "summing up today…"}
+	m.viewport.SetContent(m.renderPreviewContent())
+	return m.loadSummary
+}
+
+// updateSummary handles keys while the summary is open
+func (m Model) updateSummary(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, m.quit()
+	case "esc", "S":
+		m.mode = modeFiles
+		m.lastSelectedFile = -1
+		m.updatePreview()
+	case "j", "down":
+		m.viewport.LineDown(1)
+	case "k", "up":
+		m.viewport.LineUp(1)
+	case "g":
+		m.viewport.GotoTop()
+	case "G":
+		m.viewport.GotoBottom()
+	case "ctrl+d":
+		m.viewport.HalfViewDown()
+	case "ctrl+u":
+		m.viewport.HalfViewUp()
+	}
+	return m, nil
+}
+
+// renderBar draws a proportional bar of at most width cells
+func renderBar(value, max, width int) string {
+	if max <= 0 || width <= 0 {
+		return ""
+	}
+	n := value * width / max
+	if n == 0 && value > 0 {
+		n = 1
+	}
+	return strings.Repeat("█", n)
+}
+
+// buildSummaryPreview lists the busiest files with change bars
+func (m Model) buildSummaryPreview(summary git.DaySummary) PreviewContent {
+	if len(summary.Files) == 0 {
+		return PreviewContent{Valid: true, Message: "nothing yet today"}
+	}
+
+	maxChange := summary.Files[0].Added + summary.Files[0].Deleted
+	barWidth := m.width / 4
+	var raw, highlighted []string
+	for _, f := range summary.Files {
+		counts := fmt.Sprintf("+%d −%d", f.Added, f.Deleted)
+		bar := renderBar(f.Added+f.Deleted, maxChange, barWidth)
+		raw = append(raw, f.Path+"  "+counts)
+		highlighted = append(highlighted, padLine(
+			f.Path+"  "+lineAddGutter.Render(fmt.Sprintf("+%d", f.Added))+" "+lineDelGutter.Render(fmt.Sprintf("−%d", f.Deleted)),
+			cyanStyle.Render(bar)+"  ", m.width-gutterWidth))
+	}
+	return PreviewContent{Valid: true, RawLines: raw, HighlightedLines: highlighted}
+}
+
+// renderSummaryList renders today's totals and commits in place of the file list
+func (m Model) renderSummaryList() string {
+	var lines []string
+	header := dimStyle.Render("TODAY")
+	hint := keyStyle.Render("esc") + dimStyle.Render(" back")
+	lines = append(lines, padLine(header, hint, m.width))
+
+	s := m.summary
+	commits := "commits"
+	if len(s.Commits) == 1 {
+		commits = "commit"
+	}
+	totals := fmt.Sprintf("  %d files touched · ", len(s.Files)) +
+		lineAddGutter.Render(fmt.Sprintf("+%d", s.Added)) + " " + lineDelGutter.Render(fmt.Sprintf("−%d", s.Deleted)) +
+		fmt.Sprintf(" · %d %s", len(s.Commits), commits)
+	lines = append(lines, totals)
+
+	for _, c := range s.Commits {
+		if len(lines) >= m.listRows() {
+			break
+		}
+		lines = append(lines, padLine("  "+dimStyle.Render(c.Hash)+" "+c.Subject, dimStyle.Render(c.TimeAgo), m.width))
+	}
+
+	for len(lines) < m.listRows() {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
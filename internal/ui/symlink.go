@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kateleext/perch/internal/git"
+)
+
+// symlinkInfo describes a symlink in the worktree
+type symlinkInfo struct {
+	target   string // the link's contents, as written
+	resolved string // the file it ends up at, or "" when the link is broken
+	problem  string // why the target isn't previewed, if it isn't
+}
+
+// readSymlink returns nil for anything but a symlink. Targets are only
+// previewed when they are regular files inside the repo.
+func readSymlink(path, gitRoot string) *symlinkInfo {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return nil
+	}
+	target, err := os.Readlink(path)
+	if err != nil {
+		return nil
+	}
+	link := &symlinkInfo{target: target}
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		link.problem = "broken link: target doesn't exist"
+		return link
+	}
+	root, err := filepath.EvalSymlinks(gitRoot)
+	if err != nil {
+		root = gitRoot
+	}
+	if rel, err := filepath.Rel(root, resolved); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		link.problem = "target is outside the repo"
+		return link
+	}
+	if st, err := os.Stat(resolved); err != nil || !st.Mode().IsRegular() {
+		link.problem = "target isn't a regular file"
+		return link
+	}
+	link.resolved = resolved
+	return link
+}
+
+// symlinkLabel is the preview header's "symlink → target", noting where
+// the link pointed before when its diff changed the target
+func symlinkLabel(link *symlinkInfo, diff []git.DiffLine, typeChanged bool) string {
+	label := "symlink → " + link.target
+	if typeChanged {
+		return label + " (was a file)"
+	}
+	// A symlink's diff is its target path: one line out, one line in
+	for _, row := range diff {
+		if row.Type == "remove" && row.Content != link.target {
+			return label + " (was " + row.Content + ")"
+		}
+	}
+	return label
+}
@@ -0,0 +1,20 @@
+package ui
+
+// toggleTail pins the preview to the bottom of the file, like tail -f
+func (m *Model) toggleTail() {
+	m.tail = !m.tail
+	if m.tail {
+		m.statusMessage = "tailing: new lines keep the view at the bottom"
+		m.viewport.GotoBottom()
+	} else {
+		m.statusMessage = "stopped tailing"
+	}
+}
+
+// tailOnGrowth re-pins the preview to the bottom after a reload if the file
+// gained lines, so scrolling up through an idle file isn't undone
+func (m *Model) tailOnGrowth(prevLines int) {
+	if m.tail && m.viewport.TotalLineCount() > prevLines {
+		m.viewport.GotoBottom()
+	}
+}
@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	// Matches ERB tags: <%, <%=, <%#, <%-, -%>, etc.
+	erbTagRegex = regexp.MustCompile(`<%[#=-]?.*?-?%>`)
+
+	// Matches Jinja/Django tags: {% %}, {{ }}, {# #}, with optional - trim markers
+	jinjaTagRegex = regexp.MustCompile(`\{%-?.*?-?%\}|\{\{-?.*?-?\}\}|\{#.*?#\}`)
+
+	// Matches Go template actions, including {{- -}} trims and {{/* comments */}}
+	goTemplateRegex = regexp.MustCompile(`\{\{-?.*?-?\}\}`)
+
+	// Matches Liquid tags: {% %} and {{ }}, with optional - trim markers
+	liquidTagRegex = regexp.MustCompile(`\{%-?.*?-?%\}|\{\{-?.*?-?\}\}`)
+
+	// Matches Handlebars/Mustache tags: {{{raw}}}, {{#block}}, {{/block}},
+	// {{^inverse}}, {{>partial}}, {{!-- comments --}}, and plain {{ }}
+	handlebarsTagRegex = regexp.MustCompile(`\{\{\{.*?\}\}\}|\{\{!--.*?--\}\}|\{\{~?[#/^>!&]?.*?~?\}\}`)
+
+	// Matches EJS tags: <% %>, <%= %>, <%- %>, <%# %>, <%_ _%>
+	ejsTagRegex = regexp.MustCompile(`<%[_=#%-]?.*?[_-]?%>`)
+
+	// Muted purple/magenta for template tags - works well with Catppuccin
+	templateTagStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("139"))
+)
+
+// templateSyntax is a template language whose tags are emphasized over the
+// highlighting of the file it generates
+type templateSyntax struct {
+	tags *regexp.Regexp
+	exts []string // extensions marking a template, e.g. ".j2" in nginx.conf.j2
+	// chroma has a lexer for the template itself, so the full name is kept
+	// for highlighting (except when the output is markdown)
+	lexer bool
+	// output is the extension assumed for what the template generates when
+	// the name doesn't say, e.g. page.liquid highlights as HTML
+	output string
+}
+
+var (
+	erbSyntax        = &templateSyntax{tags: erbTagRegex, exts: []string{".erb"}, lexer: true}
+	jinjaSyntax      = &templateSyntax{tags: jinjaTagRegex, exts: []string{".j2", ".jinja", ".jinja2"}}
+	goSyntax         = &templateSyntax{tags: goTemplateRegex, exts: []string{".tmpl", ".gotmpl", ".tpl"}}
+	liquidSyntax     = &templateSyntax{tags: liquidTagRegex, exts: []string{".liquid"}, output: ".html"}
+	handlebarsSyntax = &templateSyntax{tags: handlebarsTagRegex, exts: []string{".hbs", ".handlebars", ".mustache"}, output: ".html"}
+	ejsSyntax        = &templateSyntax{tags: ejsTagRegex, exts: []string{".ejs"}, output: ".html"}
+)
+
+// templateSyntaxes are checked in order by extension
+var templateSyntaxes = []*templateSyntax{erbSyntax, jinjaSyntax, goSyntax, liquidSyntax, handlebarsSyntax, ejsSyntax}
+
+// templateFor returns a file's template syntax (nil if it isn't one) and
+// the path to highlight its content as: index.html.j2 highlights as HTML
+func templateFor(path, content string) (*templateSyntax, string) {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, syntax := range templateSyntaxes {
+		for _, e := range syntax.exts {
+			if ext != e {
+				continue
+			}
+			base := path[:len(path)-len(ext)]
+			if syntax.lexer && !isMarkdownFile(base) {
+				base = path
+			}
+			if filepath.Ext(base) == "" && syntax.output != "" {
+				base += syntax.output
+			}
+			return syntax, base
+		}
+	}
+	if isHelmTemplate(path, content) {
+		return goSyntax, path
+	}
+	// Django templates are plain .html with tags in them
+	if (ext == ".html" || ext == ".htm") && jinjaTagRegex.MatchString(content) {
+		return jinjaSyntax, path
+	}
+	return nil, path
+}
+
+// isHelmTemplate reports whether a file is one of a Helm chart's templates:
+// YAML (or NOTES.txt) under a templates/ directory, with actions in it
+func isHelmTemplate(path, content string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".txt":
+	default:
+		return false
+	}
+	dir := "/" + filepath.ToSlash(filepath.Dir(path)) + "/"
+	return strings.Contains(dir, "/templates/") && strings.Contains(content, "{{")
+}
+
+// style applies tag styling to already-highlighted lines
+func (t *templateSyntax) style(lines []string) []string {
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		result[i] = styleTemplateTags(line, t.tags)
+	}
+	return result
+}
+
+// styleTemplateTags applies template tag styling to an already-highlighted
+// line. Tags are found in the plain text, since the base lexer may have split
+// them with color codes (or flagged them as errors), and each one replaces
+// the highlighted columns it covers.
+func styleTemplateTags(line string, tags *regexp.Regexp) string {
+	plain := stripANSIColors(line)
+	matches := tags.FindAllStringIndex(plain, -1)
+	if len(matches) == 0 {
+		return line
+	}
+
+	var result strings.Builder
+	rest := line
+	col := 0
+	for _, match := range matches {
+		startCol := VisibleWidth(plain[:match[0]])
+		endCol := VisibleWidth(plain[:match[1]])
+
+		// Keep the highlighting up to the tag, then drop the tag's own
+		before, after, active := sliceANSIAware(rest, startCol-col)
+		result.WriteString(before)
+		_, after, inTag := sliceANSIAware(after, endCol-startCol)
+		active += inTag
+
+		// Reset any existing styling, apply tag style, then restore what
+		// was active after the tag
+		result.WriteString("\033[0m")
+		result.WriteString(templateTagStyle.Render(plain[match[0]:match[1]]))
+		result.WriteString("\033[0m")
+		result.WriteString(active)
+
+		rest = after
+		col = endCol
+	}
+	result.WriteString(rest)
+
+	return result.String()
+}
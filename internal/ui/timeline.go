@@ -0,0 +1,293 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kateleext/perch/internal/debuglog"
+	"github.com/kateleext/perch/internal/git"
+	"github.com/kateleext/perch/internal/snapshot"
+)
+
+// Snapshots records each dirty file's content as it changes, for the
+// per-file timeline (T)
+var Snapshots = true
+
+// timelineView lists the recorded versions of one file
+type timelineView struct {
+	path           string // relative to the snapshot store's root
+	name           string // display path
+	versions       []snapshot.Version
+	selected       int
+	scroll         int
+	confirmRestore bool // true after the first "r"
+}
+
+// timelineLoadedMsg carries a file's recorded versions
+type timelineLoadedMsg struct {
+	path     string
+	versions []snapshot.Version
+	err      error
+}
+
+// timelinePreviewMsg carries the diff from a version to the file as it is now
+type timelinePreviewMsg struct {
+	hash    string
+	preview PreviewContent
+}
+
+// snapshotFailedMsg reports that recording versions failed
+type snapshotFailedMsg struct {
+	err error
+}
+
+// timelineRestoredMsg reports a version written back over its file
+type timelineRestoredMsg struct {
+	version snapshot.Version
+	err     error
+}
+
+// snapshotPath is f's path relative to the snapshot store's root
+func (m Model) snapshotPath(f git.FileStatus) (string, bool) {
+	if m.snapshots == nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(m.snapshots.Root(), filepath.Join(m.dir, f.Path))
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// snapshotCmd records the dirty files of a load in the background. Secrets
+// and key files are left out so their values never reach the store.
+func (m Model) snapshotCmd(files []git.FileStatus) tea.Cmd {
+	if m.snapshots == nil {
+		return nil
+	}
+	type pending struct {
+		path    string
+		modTime time.Time
+	}
+	var record []pending
+	for _, f := range files {
+		if f.Status == "committed" || strings.Contains(f.GitCode, "D") || isSensitiveFile(f.Path) {
+			continue
+		}
+		if path, ok := m.snapshotPath(f); ok {
+			record = append(record, pending{path, f.ModTime})
+		}
+	}
+	if len(record) == 0 {
+		return nil
+	}
+	store := m.snapshots
+	return func() tea.Msg {
+		var failed error
+		for _, p := range record {
+			if _, err := store.Record(p.path, p.modTime); err != nil {
+				debuglog.Printf("snapshot %s: %v", p.path, err)
+				failed = err
+			}
+		}
+		if failed != nil {
+			return snapshotFailedMsg{err: failed}
+		}
+		return nil
+	}
+}
+
+// enterTimelineMode opens the recorded versions of the selected file
+func (m *Model) enterTimelineMode() tea.Cmd {
+	if m.selected < 0 || m.selected >= len(m.files) {
+		return nil
+	}
+	f := m.files[m.selected]
+	path, ok := m.snapshotPath(f)
+	if !ok {
+		m.statusMessage = "snapshots are off"
+		return nil
+	}
+	m.timeline = timelineView{path: path, name: f.Path}
+	m.mode = modeTimeline
+	m.preview = PreviewContent{Valid: true, Message: "loading versions…"}
+	m.viewport.SetContent(m.renderPreviewContent())
+	store := m.snapshots
+	return func() tea.Msg {
+		// Catch up on an edit the last refresh hasn't recorded yet
+		if info, err := os.Stat(filepath.Join(store.Root(), path)); err == nil {
+			store.Record(path, info.ModTime())
+		}
+		versions, err := store.History(path)
+		return timelineLoadedMsg{path: path, versions: versions, err: err}
+	}
+}
+
+// exitTimelineMode returns to the file list and restores its preview
+func (m *Model) exitTimelineMode() {
+	m.mode = modeFiles
+	m.timeline = timelineView{}
+	m.lastSelectedFile = -1
+	m.updatePreview()
+}
+
+// loadTimelinePreview diffs the selected version against the file now
+func (m Model) loadTimelinePreview() tea.Cmd {
+	t := m.timeline
+	if t.selected < 0 || t.selected >= len(t.versions) {
+		return nil
+	}
+	v := t.versions[t.selected]
+	store := m.snapshots
+	return func() tea.Msg {
+		current := filepath.Join(store.Root(), t.path)
+		if _, err := os.Stat(current); err != nil {
+			current = os.DevNull
+		}
+		diff, err := git.DiffFiles(context.Background(), store.ObjectPath(v.Hash), current)
+		if err != nil {
+			return timelinePreviewMsg{hash: v.Hash, preview: PreviewContent{Valid: true, Message: "couldn't read this version"}}
+		}
+		// Name the file by its path so the preview highlights it
+		for i := range diff {
+			if diff[i].Type == "file" {
+				diff[i].Content = t.name
+			}
+		}
+		if len(diff) == 0 {
+			return timelinePreviewMsg{hash: v.Hash, preview: PreviewContent{Valid: true, Message: "same as the file now"}}
+		}
+		return timelinePreviewMsg{hash: v.Hash, preview: buildDiffPreview(diff)}
+	}
+}
+
+// restoreVersion writes the selected version back over its file
+func (m Model) restoreVersion() tea.Cmd {
+	v := m.timeline.versions[m.timeline.selected]
+	store := m.snapshots
+	return func() tea.Msg {
+		return timelineRestoredMsg{version: v, err: store.Restore(v)}
+	}
+}
+
+// updateTimeline handles keys while a file's timeline is open
+func (m Model) updateTimeline(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	t := &m.timeline
+	confirming := t.confirmRestore
+	t.confirmRestore = false
+
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, m.quit()
+	case "esc", "T":
+		m.statusMessage = ""
+		m.exitTimelineMode()
+		return m, nil
+	case "up":
+		if t.selected > 0 {
+			t.selected--
+			if t.selected < t.scroll {
+				t.scroll = t.selected
+			}
+			return m, m.loadTimelinePreview()
+		}
+	case "down":
+		if t.selected < len(t.versions)-1 {
+			t.selected++
+			if visible := m.listRows() - 1; t.selected >= t.scroll+visible {
+				t.scroll = t.selected - visible + 1
+			}
+			return m, m.loadTimelinePreview()
+		}
+	case "j":
+		m.viewport.LineDown(1)
+	case "k":
+		m.viewport.LineUp(1)
+	case "g":
+		m.viewport.GotoTop()
+	case "G":
+		m.viewport.GotoBottom()
+	case "ctrl+d":
+		m.viewport.HalfViewDown()
+	case "ctrl+u":
+		m.viewport.HalfViewUp()
+	case "r":
+		if t.selected >= len(t.versions) {
+			return m, nil
+		}
+		// Restoring overwrites the file, so it takes a second press
+		if !confirming {
+			t.confirmRestore = true
+			m.statusMessage = "press r again to restore this version over " + t.name
+			return m, nil
+		}
+		m.statusMessage = ""
+		return m, m.restoreVersion()
+	}
+	return m, nil
+}
+
+// versionTime labels a version by the time it was recorded
+func versionTime(t, now time.Time) string {
+	if t.YearDay() == now.YearDay() && t.Year() == now.Year() {
+		return t.Format("15:04:05")
+	}
+	return t.Format("Jan 2 15:04:05")
+}
+
+// renderTimelineList renders the versions in place of the file list
+func (m Model) renderTimelineList() string {
+	t := m.timeline
+	var lines []string
+	header := dimStyle.Render("VERSIONS " + t.name)
+	hint := keyStyle.Render("r r") + dimStyle.Render(" restore  ") + keyStyle.Render("esc") + dimStyle.Render(" back")
+	lines = append(lines, padLine(header, hint, m.width))
+
+	if len(t.versions) == 0 {
+		lines = append(lines, "  "+dimStyle.Render("no versions recorded yet"))
+	}
+
+	now := time.Now()
+	end := t.scroll + m.listRows() - 1
+	if end > len(t.versions) {
+		end = len(t.versions)
+	}
+	for i := t.scroll; i < end; i++ {
+		v := t.versions[i]
+		label := versionTime(v.Time.Local(), now)
+		if i == 0 {
+			label += "  latest"
+		}
+		size := dimStyle.Render(formatSize(v.Size))
+		if i == t.selected {
+			lines = append(lines, padLine(selectedStyle.Render(cursorMarker()+label), size, m.width))
+		} else {
+			lines = append(lines, padLine("  "+label, size, m.width))
+		}
+	}
+
+	for len(lines) < m.listRows() {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// renderTimelineHeader renders the preview header for the selected version
+func (m Model) renderTimelineHeader() string {
+	t := m.timeline
+	if t.selected < 0 || t.selected >= len(t.versions) {
+		return "\n"
+	}
+	v := t.versions[t.selected]
+	header := "  " + cyanStyle.Render(fmt.Sprintf("%s → now", versionTime(v.Time.Local(), time.Now())))
+	if stats := renderDiffStats(m.preview.DiffStats); stats != "" {
+		header += "  " + stats
+	}
+	hint := keyStyle.Render("j k") + dimStyle.Render(" scroll  ")
+	return padLine(header, hint, m.width) + "\n"
+}
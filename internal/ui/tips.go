@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// firstRunTips are the keys a new user needs before anything else
+var firstRunTips = [][2]string{
+	{"↑↓", "navigate files"},
+	{"shift+↑", "jump to the newest change"},
+	{"j/k g/G", "scroll the preview, top/bottom"},
+	{"+/-", "resize the panes"},
+	{"d", "diff-only view"},
+	{"z", "zen mode: preview only"},
+	{"q", "quit"},
+}
+
+var tipsBorder = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("109")).
+	Padding(0, 1)
+
+// updateTips dismisses the first-run card on any key; ctrl+c still quits
+func (m Model) updateTips(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.showTips = false
+	if msg.String() == "ctrl+c" && !m.embedded {
+		return m, m.quit()
+	}
+	return m, nil
+}
+
+// renderTipsCard lists the core keys, with a dismiss hint
+func (m Model) renderTipsCard() string {
+	lines := []string{cyanStyle.Render("welcome to perch"), ""}
+	for _, tip := range firstRunTips {
+		lines = append(lines, cyanStyle.Render(padRight(tip[0], 9))+tip[1])
+	}
+	lines = append(lines, "", dimStyle.Render("press any key to start"))
+	card := strings.Join(lines, "\n")
+	if Accessible {
+		return card
+	}
+	return tipsBorder.Render(card)
+}
+
+// withTipsCard draws the first-run card over the middle of the frame
+func (m Model) withTipsCard(frame string) string {
+	card := strings.Split(m.renderTipsCard(), "\n")
+	cardWidth := lipgloss.Width(strings.Join(card, "\n"))
+	frameLines := strings.Split(frame, "\n")
+	if cardWidth > m.width || len(card) > len(frameLines) {
+		return frame
+	}
+	x := (m.width - cardWidth) / 2
+	y := (len(frameLines) - len(card)) / 2
+	for i, row := range card {
+		line := frameLines[y+i]
+		left, rest, _ := sliceANSIAware(line, x)
+		if pad := x - lipgloss.Width(left); pad > 0 {
+			left += strings.Repeat(" ", pad)
+		}
+		_, right, active := sliceANSIAware(rest, cardWidth)
+		row += strings.Repeat(" ", cardWidth-lipgloss.Width(row))
+		frameLines[y+i] = left + ansiReset + row + ansiReset + active + right
+	}
+	return strings.Join(frameLines, "\n")
+}
+
+// padRight pads s with spaces to n columns
+func padRight(s string, n int) string {
+	if w := lipgloss.Width(s); w < n {
+		return s + strings.Repeat(" ", n-w)
+	}
+	return s + " "
+}
@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kateleext/perch/internal/git"
+)
+
+// seenFiles maps a path to the mtime it had when its preview was last shown
+type seenFiles map[string]time.Time
+
+// seedSeen counts every file in the first load as seen, so only later
+// changes are badged
+func seedSeen(files []git.FileStatus) seenFiles {
+	seen := make(seenFiles, len(files))
+	for _, f := range files {
+		seen[f.Path] = f.ModTime
+	}
+	return seen
+}
+
+// unseen reports whether f changed since its preview was last shown.
+// Committed files aren't badged; their changes were seen as uncommitted.
+func (s seenFiles) unseen(f git.FileStatus) bool {
+	if s == nil || f.Status == "committed" {
+		return false
+	}
+	at, ok := s[f.Path]
+	return !ok || f.ModTime.After(at)
+}
+
+// markSeen clears the badge of the file whose preview is showing
+func (m *Model) markSeen() {
+	if m.seen == nil || m.mode != modeFiles || m.selected < 0 || m.selected >= len(m.files) {
+		return
+	}
+	f := m.files[m.selected]
+	m.seen[f.Path] = f.ModTime
+}
+
+// markAllSeen clears every badge and returns how many there were
+func (m *Model) markAllSeen() int {
+	if m.seen == nil {
+		return 0
+	}
+	n := 0
+	for _, f := range m.files {
+		if m.seen.unseen(f) {
+			n++
+		}
+		m.seen[f.Path] = f.ModTime
+	}
+	return n
+}
+
+// reviewProgress counts the changed files and how many of them were
+// previewed since their last change
+func (m Model) reviewProgress() (reviewed, total int) {
+	for _, f := range m.files {
+		if f.Status == "committed" {
+			continue
+		}
+		total++
+		if !m.seen.unseen(f) {
+			reviewed++
+		}
+	}
+	return reviewed, total
+}
+
+// renderReviewProgress renders "3/9 reviewed" for the list header while
+// anything is still unseen
+func (m Model) renderReviewProgress() string {
+	reviewed, total := m.reviewProgress()
+	if reviewed == total {
+		return ""
+	}
+	if Accessible {
+		return fmt.Sprintf("%d of %d reviewed", reviewed, total)
+	}
+	return cyanStyle.Render("●") + dimStyle.Render(fmt.Sprintf(" %d/%d reviewed", reviewed, total))
+}
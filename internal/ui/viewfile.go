@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/kateleext/perch/internal/git"
+)
+
+// SetViewFile pins perch to one file (relative to the watched dir): the list
+// holds only that file and the preview fills the screen
+func (m *Model) SetViewFile(path string) {
+	m.viewFile = filepath.ToSlash(path)
+	m.zen = true
+}
+
+// pinFile narrows a load to the viewed file, standing in a clean entry when
+// git has nothing to say about it
+func (m Model) pinFile(files []git.FileStatus) []git.FileStatus {
+	for _, f := range files {
+		if f.Path == m.viewFile {
+			return []git.FileStatus{f}
+		}
+	}
+	return []git.FileStatus{m.cleanEntry(m.viewFile)}
+}
+
+// cleanEntry builds a list entry for a file git reports no changes for
+func (m Model) cleanEntry(path string) git.FileStatus {
+	abs := filepath.Join(m.dir, path)
+	full, err := filepath.Rel(m.gitRoot, abs)
+	if err != nil {
+		full = path
+	}
+	f := git.FileStatus{
+		Status:   "uncommitted",
+		GitCode:  "  ",
+		Path:     path,
+		FullPath: filepath.ToSlash(full),
+		GitRoot:  m.gitRoot,
+		IsFile:   true,
+	}
+	if info, err := os.Stat(abs); err == nil {
+		f.ModTime = info.ModTime()
+	}
+	return f
+}
@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// bgSelANSI is the background for rows in a visual selection
+const bgSelANSI = "\033[48;5;237m"
+
+// enterVisual starts a selection on the top visible preview row
+func (m *Model) enterVisual() {
+	if len(m.displayPreview().WrappedLinesForWidth(m.wrapWidth())) == 0 {
+		return
+	}
+	m.visualActive = true
+	m.visualAnchor = m.viewport.YOffset
+	m.visualCursor = m.viewport.YOffset
+}
+
+// visualSelected reports whether visual row i is inside the selection
+func (m *Model) visualSelected(i int) bool {
+	if !m.visualActive {
+		return false
+	}
+	lo, hi := m.visualAnchor, m.visualCursor
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	return i >= lo && i <= hi
+}
+
+// updateVisual handles keys while a visual selection is active
+func (m Model) updateVisual(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	total := len(m.displayPreview().WrappedLinesForWidth(m.wrapWidth()))
+	switch msg.String() {
+	case "ctrl+c":
+		return m, m.quit()
+	case "esc", "v", "q":
+		m.visualActive = false
+	case "j", "down":
+		if m.visualCursor < total-1 {
+			m.visualCursor++
+		}
+	case "k", "up":
+		if m.visualCursor > 0 {
+			m.visualCursor--
+		}
+	case "ctrl+d":
+		m.visualCursor = min(m.visualCursor+m.viewport.Height/2, total-1)
+	case "ctrl+u":
+		m.visualCursor = max(m.visualCursor-m.viewport.Height/2, 0)
+	case "g":
+		m.visualCursor = 0
+	case "G":
+		m.visualCursor = total - 1
+	case "y", "enter":
+		text, n := m.selectedText()
+		m.visualActive = false
+		m.viewport.SetContent(m.renderPreviewContent())
+		return m, copyTextCmd(text, strconv.Itoa(n)+" lines")
+	}
+
+	// Keep the cursor on screen
+	if m.visualCursor < m.viewport.YOffset {
+		m.viewport.SetYOffset(m.visualCursor)
+	} else if m.visualCursor >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(m.visualCursor - m.viewport.Height + 1)
+	}
+	m.viewport.SetContent(m.renderPreviewContent())
+	return m, nil
+}
+
+// selectedText returns the raw (unwrapped, ANSI-free) lines covered by the
+// selection and how many there are, leaving out collapsed-region separators
+func (m *Model) selectedText() (string, int) {
+	pc := m.displayPreview()
+	wrapped := pc.WrappedLinesForWidth(m.wrapWidth())
+	var lines []string
+	last := -1
+	for i, vl := range wrapped {
+		if !m.visualSelected(i) || vl.LogicalIndex == last {
+			continue
+		}
+		last = vl.LogicalIndex
+		if vl.LogicalIndex < len(pc.RawLines) && !pc.isSeparator(vl.LogicalIndex) {
+			lines = append(lines, stripANSIColors(pc.RawLines[vl.LogicalIndex]))
+		}
+	}
+	return strings.Join(lines, "\n"), len(lines)
+}
@@ -0,0 +1,83 @@
+package ui
+
+import "strings"
+
+// ANSI codes for whitespace markers
+const (
+	wsDimANSI  = "\033[38;5;238m" // tabs: as subtle as the gutter dots
+	wsWarnANSI = "\033[38;5;167m" // trailing whitespace and mixed indentation
+)
+
+// visualizeWhitespace marks tabs (→), trailing whitespace, and mixed
+// tab/space indentation in a highlighted line segment. Markers keep each
+// character's display width so wrapping and padding are unaffected.
+// Leading whitespace is only checked on first segments and trailing
+// whitespace on last segments, since continuation indents are synthetic.
+func visualizeWhitespace(s string, first, last bool) string {
+	var runes []rune
+	for i := 0; i < len(s); {
+		if isANSIStart(s, i) {
+			i = skipANSI(s, i)
+			continue
+		}
+		r, size := decodeRune(s, i)
+		runes = append(runes, r)
+		i += size
+	}
+
+	leadingEnd := 0
+	hasTab, hasSpace := false, false
+	if first {
+		for leadingEnd < len(runes) && (runes[leadingEnd] == ' ' || runes[leadingEnd] == '\t') {
+			if runes[leadingEnd] == '\t' {
+				hasTab = true
+			} else {
+				hasSpace = true
+			}
+			leadingEnd++
+		}
+	}
+	mixed := hasTab && hasSpace
+
+	trailingStart := len(runes)
+	if last {
+		for trailingStart > 0 && (runes[trailingStart-1] == ' ' || runes[trailingStart-1] == '\t') {
+			trailingStart--
+		}
+	}
+
+	var b strings.Builder
+	var active strings.Builder
+	k := 0
+	for i := 0; i < len(s); {
+		if isANSIStart(s, i) {
+			start := i
+			i = skipANSI(s, i)
+			ansi := s[start:i]
+			b.WriteString(ansi)
+			if ansi == ansiReset {
+				active.Reset()
+			} else {
+				active.WriteString(ansi)
+			}
+			continue
+		}
+		r, size := decodeRune(s, i)
+		problem := k >= trailingStart || (mixed && k < leadingEnd)
+		switch {
+		case r == '\t':
+			color := wsDimANSI
+			if problem {
+				color = wsWarnANSI
+			}
+			b.WriteString(color + tabGlyph + "   " + ansiReset + active.String())
+		case r == ' ' && problem:
+			b.WriteString(wsWarnANSI + dotGlyph + ansiReset + active.String())
+		default:
+			b.WriteString(s[i : i+size])
+		}
+		k++
+		i += size
+	}
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
+)
+
+// Glyphs perch draws with that are East Asian "ambiguous" width: one column
+// in most terminals, two where ambiguous characters are set to wide. They
+// fall back to ASCII when wide, so the gutter and padding math holds.
+var (
+	dotGlyph  = "·" // context-line gutter and trailing-space marker
+	ruleGlyph = "─" // dividers
+	tabGlyph  = "→" // tab marker
+)
+
+// SetAmbiguousWidth sets how ambiguous-width characters are measured:
+// "narrow", "wide", or "auto" to follow the locale (RUNEWIDTH_EASTASIAN or
+// a CJK LANG), matching what the terminal is likely configured for
+func SetAmbiguousWidth(mode string) error {
+	var wide bool
+	switch mode {
+	case "auto":
+		wide = runewidth.EastAsianWidth
+	case "narrow":
+	case "wide":
+		wide = true
+	default:
+		return fmt.Errorf("invalid ambiguous width %q (want auto, narrow, or wide)", mode)
+	}
+
+	runewidth.DefaultCondition.EastAsianWidth = wide
+	uniseg.EastAsianAmbiguousWidth = 1
+	dotGlyph, ruleGlyph, tabGlyph = "·", "─", "→"
+	if wide {
+		uniseg.EastAsianAmbiguousWidth = 2
+		dotGlyph, ruleGlyph, tabGlyph = ".", "-", ">"
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package ui
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kateleext/perch/internal/git"
+)
+
+// WIPInterval commits the working tree to git.WIPRef this often (0 = off)
+var WIPInterval time.Duration
+
+// wipTickMsg triggers the next WIP save
+type wipTickMsg struct{}
+
+// wipSavedMsg reports a finished WIP save; commit is "" when nothing changed
+type wipSavedMsg struct {
+	commit string
+	err    error
+}
+
+// saveWIP commits the working tree to the WIP ref in the background
+func (m Model) saveWIP() tea.Msg {
+	commit, err := git.SaveWIP(context.Background(), m.dir)
+	return wipSavedMsg{commit: commit, err: err}
+}
+
+func wipTickCmd() tea.Cmd {
+	return tea.Tick(WIPInterval, func(time.Time) tea.Msg {
+		return wipTickMsg{}
+	})
+}
@@ -0,0 +1,145 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kateleext/perch/internal/git"
+)
+
+// worktreesLoadedMsg carries the repo's worktree list
+type worktreesLoadedMsg struct {
+	worktrees []git.Worktree
+	err       error
+}
+
+func (m Model) loadWorktrees() tea.Msg {
+	worktrees, err := git.ListWorktrees(context.Background(), m.gitRoot)
+	return worktreesLoadedMsg{worktrees: worktrees, err: err}
+}
+
+// enterWorktreeMode swaps the file list for the worktree picker
+func (m *Model) enterWorktreeMode() tea.Cmd {
+	m.mode = modeWorktrees
+	m.worktreeSelected = 0
+	m.preview = PreviewContent{Valid: true, Message: "loading worktrees…"}
+	m.viewport.SetContent(m.renderPreviewContent())
+	return m.loadWorktrees
+}
+
+// showWorktreePreview describes the selected worktree in the preview pane
+func (m *Model) showWorktreePreview() {
+	if m.worktreeSelected < 0 || m.worktreeSelected >= len(m.worktrees) {
+		m.preview = PreviewContent{Valid: true, Message: "no worktrees"}
+	} else {
+		wt := m.worktrees[m.worktreeSelected]
+		branch := wt.Branch
+		if branch == "" {
+			branch = "detached"
+		}
+		msg := fmt.Sprintf("%s\n%s · %s", wt.Path, branch, wt.Head)
+		if wt.Current {
+			msg += "\n(current)"
+		} else {
+			msg += "\nenter to switch"
+		}
+		m.preview = PreviewContent{Valid: true, Message: msg}
+	}
+	m.viewport.SetContent(m.renderPreviewContent())
+}
+
+// WatchDir moves the file watcher to dir; set by the program that runs it
+var WatchDir func(dir string)
+
+// switchWorktree re-targets perch at another worktree of the same repo
+func (m *Model) switchWorktree(path string) tea.Cmd {
+	m.dir = path
+	m.gitRoot, _ = git.GetGitRoot(context.Background(), path)
+	m.linkedWorktree = git.IsLinkedWorktree(context.Background(), path)
+	m.files = nil
+	m.selected = 0
+	m.listScroll = 0
+	m.previewCache.clear()
+	m.mode = modeFiles
+	m.lastSelectedFile = -1
+	m.preview = PreviewContent{}
+	m.pr = nil // refilled for the new branch on the next PR refresh
+	m.viewport.SetContent("")
+	if WatchDir != nil {
+		WatchDir(path)
+	}
+	return m.loadFiles
+}
+
+// updateWorktrees handles keys while the worktree picker is open
+func (m Model) updateWorktrees(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, m.quit()
+	case "esc", "w":
+		m.mode = modeFiles
+		m.lastSelectedFile = -1
+		m.updatePreview()
+	case "up":
+		if m.worktreeSelected > 0 {
+			m.worktreeSelected--
+			m.showWorktreePreview()
+		}
+	case "down":
+		if m.worktreeSelected < len(m.worktrees)-1 {
+			m.worktreeSelected++
+			m.showWorktreePreview()
+		}
+	case "enter":
+		if m.worktreeSelected < len(m.worktrees) {
+			wt := m.worktrees[m.worktreeSelected]
+			if wt.Current {
+				m.mode = modeFiles
+				m.lastSelectedFile = -1
+				m.updatePreview()
+				return m, nil
+			}
+			return m, m.switchWorktree(wt.Path)
+		}
+	}
+	return m, nil
+}
+
+// renderWorktreeList renders the worktree picker in place of the file list
+func (m Model) renderWorktreeList() string {
+	var lines []string
+	header := dimStyle.Render("WORKTREES")
+	hint := keyStyle.Render("enter") + dimStyle.Render(" switch  ") +
+		keyStyle.Render("esc") + dimStyle.Render(" back")
+	lines = append(lines, padLine(header, hint, m.width))
+
+	for i, wt := range m.worktrees {
+		if len(lines) >= m.listRows() {
+			break
+		}
+		branch := wt.Branch
+		if branch == "" {
+			branch = "(detached " + wt.Head + ")"
+		}
+		marker := "  "
+		if wt.Current {
+			marker = "● "
+			if Accessible {
+				marker = "* "
+			}
+		}
+		text := marker + branch + "  " + truncatePath(wt.Path, 2)
+		if i == m.worktreeSelected {
+			lines = append(lines, selectedStyle.Render(cursorMarker()+text))
+		} else {
+			lines = append(lines, "  "+text)
+		}
+	}
+
+	for len(lines) < m.listRows() {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -1,9 +1,11 @@
 package ui
 
 import (
+	"sort"
 	"strings"
 
-	"github.com/mattn/go-runewidth"
+	"github.com/kateleext/perch/internal/git"
+	"github.com/rivo/uniseg"
 )
 
 // VisualLine represents one physical line in the viewport
@@ -41,12 +43,19 @@ func countLeadingSpaces(s string) int {
 	return count
 }
 
-// runeVisualWidth returns the visual width of a rune, handling tabs specially
-func runeVisualWidth(r rune) int {
-	if r == '\t' {
-		return 4 // treat tab as 4 spaces for consistency
+// clusterAt returns the byte length and column width of the grapheme cluster
+// starting at s[i], so emoji ZWJ sequences, flags, and combining marks are
+// measured (and never split) as one character. Clusters stop at escapes.
+func clusterAt(s string, i int) (size, width int) {
+	if s[i] == '\t' {
+		return 1, 4 // treat tab as 4 spaces for consistency
 	}
-	return runewidth.RuneWidth(r)
+	end := len(s)
+	if j := strings.IndexByte(s[i+1:], 0x1b); j >= 0 {
+		end = i + 1 + j
+	}
+	cluster, _, width, _ := uniseg.FirstGraphemeClusterInString(s[i:end], -1)
+	return len(cluster), width
 }
 
 // VisibleWidth returns visual column width, ignoring ANSI sequences
@@ -58,8 +67,8 @@ func VisibleWidth(s string) int {
 			i = skipANSI(s, i)
 			continue
 		}
-		r, size := decodeRune(s, i)
-		width += runeVisualWidth(r)
+		size, w := clusterAt(s, i)
+		width += w
 		i += size
 	}
 	return width
@@ -99,13 +108,25 @@ func isANSIStart(s string, i int) bool {
 	if i+1 >= len(s) {
 		return false
 	}
-	return s[i] == 0x1b && s[i+1] == '['
+	return s[i] == 0x1b && (s[i+1] == '[' || s[i+1] == ']')
 }
 
 func skipANSI(s string, i int) int {
 	if !isANSIStart(s, i) {
 		return i + 1
 	}
+	if s[i+1] == ']' {
+		// OSC (hyperlinks): runs to BEL or ST (ESC \)
+		for j := i + 2; j < len(s); j++ {
+			if s[j] == 0x07 {
+				return j + 1
+			}
+			if s[j] == 0x1b && j+1 < len(s) && s[j+1] == '\\' {
+				return j + 2
+			}
+		}
+		return len(s)
+	}
 	j := i + 2
 	for j < len(s) {
 		b := s[j]
@@ -126,6 +147,7 @@ func sliceANSIAware(s string, maxWidth int) (content string, remainder string, a
 
 	var result strings.Builder
 	var currentANSI strings.Builder
+	openLink := "" // OSC 8 hyperlink still open at the cut
 	width := 0
 	i := 0
 	cutPoint := -1
@@ -137,8 +159,14 @@ func sliceANSIAware(s string, maxWidth int) (content string, remainder string, a
 			i = skipANSI(s, i)
 			ansi := s[start:i]
 			result.WriteString(ansi)
-			// Track active ANSI (reset clears it)
-			if ansi == "\033[0m" {
+			// Track active ANSI (reset clears it); links are tracked apart
+			// since an SGR reset doesn't end them
+			if strings.HasPrefix(ansi, osc8Prefix) {
+				openLink = ansi
+				if ansi == osc8Close {
+					openLink = ""
+				}
+			} else if ansi == "\033[0m" {
 				currentANSI.Reset()
 			} else {
 				currentANSI.WriteString(ansi)
@@ -146,8 +174,7 @@ func sliceANSIAware(s string, maxWidth int) (content string, remainder string, a
 			continue
 		}
 
-		r, size := decodeRune(s, i)
-		rw := runeVisualWidth(r)
+		size, rw := clusterAt(s, i)
 
 		if width+rw > maxWidth {
 			cutPoint = i
@@ -169,6 +196,10 @@ func sliceANSIAware(s string, maxWidth int) (content string, remainder string, a
 		content += "\033[0m"
 		activeANSI = currentANSI.String()
 	}
+	if openLink != "" {
+		content += osc8Close
+		activeANSI += openLink
+	}
 
 	if cutPoint < len(s) {
 		remainder = s[cutPoint:]
@@ -193,7 +224,7 @@ func wrapHighlightedLine(line string, logicalIndex int, maxWidth int, diffStatus
 	case "deleted":
 		firstGutter = "- "
 	default:
-		firstGutter = "· "
+		firstGutter = dotGlyph + " "
 	}
 	contGutter := "  "
 
@@ -260,32 +291,144 @@ func wrapHighlightedLine(line string, logicalIndex int, maxWidth int, diffStatus
 }
 
 // wrapAllLines wraps all highlighted lines for a given width
-func wrapAllLines(highlighted []string, rawLines []string, diffLines map[int]string, maxWidth int) []VisualLine {
+func wrapAllLines(highlighted []string, rawLines []string, diff []git.DiffLine, maxWidth int) []VisualLine {
 	var result []VisualLine
+	for i := range highlighted {
+		result = append(result, wrapLogicalLine(highlighted, rawLines, diff, i, maxWidth)...)
+	}
+	return result
+}
 
-	for i, line := range highlighted {
-		lineNum := i + 1
-		diffStatus := ""
-		if status, ok := diffLines[lineNum]; ok {
-			diffStatus = status
+// wrapLogicalLine wraps line i of a preview, styled by its diff record
+func wrapLogicalLine(highlighted []string, rawLines []string, diff []git.DiffLine, i int, maxWidth int) []VisualLine {
+	diffStatus := ""
+	if i < len(diff) {
+		switch diff[i].Type {
+		case "add":
+			diffStatus = "added"
+		case "remove":
+			diffStatus = "deleted"
 		}
+	}
 
-		// Get raw line for indent calculation
-		rawLine := ""
-		if i < len(rawLines) {
-			rawLine = rawLines[i]
+	// Get raw line for indent calculation
+	rawLine := ""
+	if i < len(rawLines) {
+		rawLine = rawLines[i]
+	}
+
+	// If highlighted line is empty but raw line has content (e.g., markdown
+	// code fences, table rows), use raw line so diff highlighting is visible
+	displayLine := highlighted[i]
+	if displayLine == "" && rawLine != "" {
+		displayLine = rawLine
+	}
+
+	return wrapHighlightedLine(displayLine, i, maxWidth, diffStatus, rawLine)
+}
+
+// wrapIndex maps a preview's logical lines to visual rows at one width
+// without wrapping the whole file up front: lines that plainly fit take
+// one row, and the rest are wrapped (and kept) as they are counted. Rows
+// themselves are only built for the lines being shown.
+type wrapIndex struct {
+	highlighted []string
+	rawLines    []string
+	diff        []git.DiffLine
+	width       int
+	starts      []int                // first row of each logical line, then the total
+	wrapped     map[int][]VisualLine // logical lines wrapped so far
+}
+
+// newWrapIndex counts the rows of every line of a preview at maxWidth
+func newWrapIndex(highlighted []string, rawLines []string, diff []git.DiffLine, maxWidth int) *wrapIndex {
+	w := &wrapIndex{
+		highlighted: highlighted,
+		rawLines:    rawLines,
+		diff:        diff,
+		width:       maxWidth,
+		starts:      make([]int, len(highlighted)+1),
+		wrapped:     make(map[int][]VisualLine),
+	}
+	contentWidth := maxWidth - gutterWidth
+	if maxWidth <= gutterWidth {
+		contentWidth = 10
+	}
+	row := 0
+	for i, line := range highlighted {
+		w.starts[i] = row
+		if line == "" && i < len(rawLines) {
+			line = rawLines[i]
+		}
+		if fitsOnOneRow(line, contentWidth) {
+			row++
+		} else {
+			row += len(w.line(i))
 		}
+	}
+	w.starts[len(highlighted)] = row
+	return w
+}
 
-		// If highlighted line is empty but raw line has content (e.g., markdown
-		// code fences, table rows), use raw line so diff highlighting is visible
-		displayLine := line
-		if displayLine == "" && rawLine != "" {
-			displayLine = rawLine
+// fitsOnOneRow is a quick check that s can't need wrapping: every byte
+// outside escape sequences takes at most a column (wide characters are
+// several bytes), except tabs, which take four. It errs towards false.
+func fitsOnOneRow(s string, width int) bool {
+	if len(s) < width && strings.IndexByte(s, '\t') < 0 {
+		return true
+	}
+	cols := 0
+	for i := 0; i < len(s); {
+		if isANSIStart(s, i) {
+			i = skipANSI(s, i)
+			continue
+		}
+		if s[i] == '\t' {
+			cols += 4
+		} else {
+			cols++
 		}
+		if cols >= width {
+			return false
+		}
+		i++
+	}
+	return true
+}
 
-		wrapped := wrapHighlightedLine(displayLine, i, maxWidth, diffStatus, rawLine)
-		result = append(result, wrapped...)
+// total returns the number of visual rows
+func (w *wrapIndex) total() int {
+	return w.starts[len(w.starts)-1]
+}
+
+// line returns the rows of logical line i, wrapping it on first use
+func (w *wrapIndex) line(i int) []VisualLine {
+	if rows, ok := w.wrapped[i]; ok {
+		return rows
 	}
+	rows := wrapLogicalLine(w.highlighted, w.rawLines, w.diff, i, w.width)
+	w.wrapped[i] = rows
+	return rows
+}
 
+// row returns visual row v
+func (w *wrapIndex) row(v int) VisualLine {
+	// The last logical line starting at or before v holds it
+	i := sort.Search(len(w.highlighted), func(i int) bool { return w.starts[i+1] > v })
+	rows := w.line(i)
+	return rows[min(v-w.starts[i], len(rows)-1)]
+}
+
+// rowOf returns the first visual row of logical line i
+func (w *wrapIndex) rowOf(i int) int {
+	return w.starts[min(max(i, 0), len(w.starts)-1)]
+}
+
+// all returns every visual row, wrapping whatever hasn't been yet
+func (w *wrapIndex) all() []VisualLine {
+	result := make([]VisualLine, 0, w.total())
+	for i := range w.highlighted {
+		result = append(result, w.line(i)...)
+	}
 	return result
 }
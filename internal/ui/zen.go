@@ -0,0 +1,24 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// cycleFile moves the selection by delta, wrapping at either end, so files
+// can be stepped through while the list is hidden
+func (m *Model) cycleFile(delta int) tea.Cmd {
+	if len(m.files) < 2 {
+		return nil
+	}
+	m.selected = (m.selected + delta + len(m.files)) % len(m.files)
+	m.ensureSelectedVisible()
+	m.previewPending = m.selected
+	return debouncePreviewCmd(m.selected)
+}
+
+// zenPosition shows where the selected file sits in the hidden list
+func (m Model) zenPosition() string {
+	return fmt.Sprintf("%d/%d", m.selected+1, len(m.files))
+}
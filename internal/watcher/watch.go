@@ -1,20 +1,28 @@
 package watcher
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/kateleext/perch/internal/debuglog"
+	"github.com/kateleext/perch/internal/git"
+	"github.com/kateleext/perch/internal/ignore"
 )
 
 // Watcher wraps fsnotify and sends change events
 type Watcher struct {
-	fsw     *fsnotify.Watcher
-	dir     string
-	Changes chan struct{}
-	done    chan struct{}
+	fsw      *fsnotify.Watcher
+	dir      string
+	Changes  chan struct{}
+	NewRepos chan struct{} // signalled when a .git entry is created or removed
+	done     chan struct{}
+	retarget chan string
+	hidden   *ignore.Matcher // .perchignore rules, reloaded when the file changes
+	gitDirs  []string        // git dir and common dir, watched for index/ref changes
 }
 
 // shouldIgnore returns true for paths we don't want to watch
@@ -45,31 +53,108 @@ func New(dir string) (*Watcher, error) {
 	}
 
 	w := &Watcher{
-		fsw:     fsw,
-		dir:     dir,
-		Changes: make(chan struct{}, 1),
-		done:    make(chan struct{}),
+		fsw:      fsw,
+		dir:      dir,
+		Changes:  make(chan struct{}, 1),
+		NewRepos: make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		retarget: make(chan string, 1),
+		hidden:   ignore.LoadDir(dir),
 	}
+	if err := w.watchTree(); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+	return w, nil
+}
 
-	// Add directory and subdirectories
-	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+// watchTree adds w.dir, its subdirectories, and its git dirs
+func (w *Watcher) watchTree() error {
+	err := filepath.Walk(w.dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // Skip errors
 		}
 		if info.IsDir() {
-			if shouldIgnore(path) {
+			if shouldIgnore(path) || w.isHidden(path, true) {
 				return filepath.SkipDir
 			}
-			fsw.Add(path)
+			w.fsw.Add(path)
 		}
 		return nil
 	})
 	if err != nil {
-		fsw.Close()
-		return nil, err
+		return err
+	}
+	w.watchGitDirs()
+	return nil
+}
+
+// Retarget moves the watch to another directory, e.g. after switching
+// worktrees; it is applied by the Start loop
+func (w *Watcher) Retarget(dir string) {
+	// A newer target replaces one not yet applied
+	select {
+	case <-w.retarget:
+	default:
 	}
+	w.retarget <- dir
+}
 
-	return w, nil
+// applyRetarget drops every watch and watches dir instead
+func (w *Watcher) applyRetarget(dir string) {
+	for _, path := range w.fsw.WatchList() {
+		w.fsw.Remove(path)
+	}
+	w.dir = dir
+	w.hidden = ignore.LoadDir(dir)
+	w.gitDirs = nil
+	if err := w.watchTree(); err != nil {
+		debuglog.Printf("watch: retarget %s: %v", dir, err)
+	}
+}
+
+// watchGitDirs watches the repo's git dir (index, HEAD) and the common dir's
+// refs, so staging, commits, and branch switches from elsewhere refresh perch
+func (w *Watcher) watchGitDirs() {
+	ctx := context.Background()
+	gitDir, err := git.GetGitDir(ctx, w.dir)
+	if err != nil {
+		return
+	}
+	w.gitDirs = []string{gitDir}
+	if commonDir, err := git.GetGitCommonDir(ctx, w.dir); err == nil && commonDir != gitDir {
+		w.gitDirs = append(w.gitDirs, commonDir)
+	}
+
+	for _, d := range w.gitDirs {
+		w.fsw.Add(d)
+		filepath.Walk(filepath.Join(d, "refs"), func(path string, info os.FileInfo, err error) error {
+			if err == nil && info.IsDir() {
+				w.fsw.Add(path)
+			}
+			return nil
+		})
+	}
+}
+
+// gitEvent reports whether path is inside a watched git dir, and if so
+// whether it is one of the files a refresh should follow
+func (w *Watcher) gitEvent(path string) (inGitDir, relevant bool) {
+	for _, d := range w.gitDirs {
+		rel, err := filepath.Rel(d, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if strings.HasSuffix(rel, ".lock") {
+			return true, false
+		}
+		switch rel {
+		case "index", "HEAD", "ORIG_HEAD", "packed-refs":
+			return true, true
+		}
+		return true, rel == "refs" || strings.HasPrefix(rel, "refs"+string(filepath.Separator))
+	}
+	return false, false
 }
 
 // Start begins watching for changes
@@ -84,6 +169,14 @@ func (w *Watcher) Start() {
 			}
 		}()
 
+		// Debounce: wait 100ms for more changes before notifying
+		restartDebounce := func() {
+			if debounceTimer != nil {
+				debounceTimer.Stop()
+			}
+			debounceTimer = time.NewTimer(100 * time.Millisecond)
+		}
+
 		for {
 			var debounce <-chan time.Time
 			if debounceTimer != nil {
@@ -93,13 +186,48 @@ func (w *Watcher) Start() {
 			select {
 			case <-w.done:
 				return
+			case dir := <-w.retarget:
+				debuglog.Printf("watch: retarget %s", dir)
+				w.applyRetarget(dir)
+				// Events pending for the old tree no longer matter
+				if debounceTimer != nil {
+					debounceTimer.Stop()
+					debounceTimer = nil
+				}
 			case event, ok := <-w.fsw.Events:
 				if !ok {
 					return
 				}
 
-				// Skip ignored paths
-				if shouldIgnore(event.Name) {
+				debuglog.Printf("watch: %s %s", event.Op, event.Name)
+
+				// Index and ref updates inside the git dir; everything else there
+				// (objects, logs, lock files) is noise
+				if inGitDir, relevant := w.gitEvent(event.Name); inGitDir {
+					if !relevant {
+						continue
+					}
+					if event.Op&fsnotify.Create != 0 {
+						if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+							w.fsw.Add(event.Name)
+						}
+					}
+					restartDebounce()
+					continue
+				}
+
+				// A .git entry appearing or vanishing means the nested repo set changed
+				if filepath.Base(event.Name) == ".git" && event.Op&(fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 {
+					w.signalNewRepo()
+				}
+
+				// Pick up edits to .perchignore (the change itself still refreshes)
+				if event.Name == filepath.Join(w.dir, ignore.FileName) {
+					w.hidden = ignore.LoadDir(w.dir)
+				}
+
+				// Skip ignored paths and files outside --include/--exclude
+				if shouldIgnore(event.Name) || w.isHidden(event.Name, false) || !w.inPathspec(event.Name) {
 					continue
 				}
 
@@ -108,16 +236,17 @@ func (w *Watcher) Start() {
 					info, err := os.Stat(event.Name)
 					if err == nil && info.IsDir() && !shouldIgnore(event.Name) {
 						w.fsw.Add(event.Name)
+						// The .git inside may have been created before the watch was added
+						if _, err := os.Stat(filepath.Join(event.Name, ".git")); err == nil {
+							w.signalNewRepo()
+						}
 					}
 				}
 
-				// Debounce: wait 100ms for more changes before notifying
-				if debounceTimer != nil {
-					debounceTimer.Stop()
-				}
-				debounceTimer = time.NewTimer(100 * time.Millisecond)
+				restartDebounce()
 
 			case <-debounce:
+				debuglog.Printf("watch: debounced change notification")
 				// Send change notification (non-blocking)
 				select {
 				case w.Changes <- struct{}{}:
@@ -125,19 +254,53 @@ func (w *Watcher) Start() {
 				}
 				debounceTimer = nil
 
-			case _, ok := <-w.fsw.Errors:
+			case err, ok := <-w.fsw.Errors:
 				if !ok {
 					return
 				}
+				debuglog.Printf("watch: error %v", err)
 				// Ignore errors, keep watching
 			}
 		}
 	}()
 }
 
+// isHidden reports whether a path is excluded by .perchignore
+func (w *Watcher) isHidden(path string, isDir bool) bool {
+	rel, err := filepath.Rel(w.dir, path)
+	if err != nil || rel == "." {
+		return false
+	}
+	return w.hidden.Match(filepath.ToSlash(rel), isDir)
+}
+
+// inPathspec reports whether a changed file passes the pathspec. Directories
+// always do, so new ones are still watched.
+func (w *Watcher) inPathspec(path string) bool {
+	spec := git.ActivePathspec()
+	if spec.IsEmpty() {
+		return true
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return true
+	}
+	rel, err := filepath.Rel(w.dir, path)
+	if err != nil {
+		return true
+	}
+	return spec.Match(filepath.ToSlash(rel))
+}
+
+// signalNewRepo notifies NewRepos without blocking
+func (w *Watcher) signalNewRepo() {
+	select {
+	case w.NewRepos <- struct{}{}:
+	default:
+	}
+}
+
 // Close stops the watcher
 func (w *Watcher) Close() error {
 	close(w.done)
-	close(w.Changes)
 	return w.fsw.Close()
 }
@@ -0,0 +1,90 @@
+// Package perchgit is perch's view of a working tree as a library: the
+// uncommitted files, the files touched by the last few commits, and the
+// same for submodules and nested repos, merged and sorted newest first.
+//
+//	files, err := perchgit.Scan(ctx, "/path/to/repo", perchgit.Options{})
+//	for _, f := range files {
+//		stats := perchgit.Stats(ctx, f)
+//		fmt.Printf("%s  %s  +%d -%d\n", f.Path, f.ChangeType(), stats.Added, stats.Deleted)
+//	}
+//
+// It shells out to git, so git must be on PATH. Scan honors the target
+// directory's .perchignore like the TUI does.
+package perchgit
+
+import (
+	"context"
+
+	"github.com/kateleext/perch/internal/git"
+	"github.com/kateleext/perch/internal/ignore"
+)
+
+// File is one changed file; Path is relative to the scanned directory and
+// FullPath to the file's own GitRoot
+type File = git.FileStatus
+
+// DiffLine is one parsed line of a unified diff
+type DiffLine = git.DiffLine
+
+// DiffStats counts added and deleted lines
+type DiffStats = git.DiffStats
+
+// DefaultNestedDepth is how deep Scan looks for nested repos unless told otherwise
+const DefaultNestedDepth = 6
+
+// Options tunes a Scan; the zero value matches perch's defaults
+type Options struct {
+	NoNested    bool     // don't look for nested repos that aren't submodules
+	NestedDepth int      // max directory depth walked for nested repos (0 = DefaultNestedDepth, <0 = unlimited)
+	Skip        []string // extra gitignore-style globs of files to hide, e.g. "*.generated.go"
+	Include     []string // only list paths matching these git pathspecs, e.g. "*.go"
+	Exclude     []string // never list paths matching these, e.g. "vendor/**"
+}
+
+// Scan lists the files changed in dir, most recently modified first
+func Scan(ctx context.Context, dir string, opts Options) ([]File, error) {
+	depth := opts.NestedDepth
+	switch {
+	case depth == 0:
+		depth = DefaultNestedDepth
+	case depth < 0:
+		depth = 0
+	}
+	return git.GetStatusWith(ctx, dir, git.ScanOptions{
+		Nested:      !opts.NoNested,
+		NestedDepth: depth,
+		Skip:        ignore.Parse(opts.Skip),
+		Paths:       git.Pathspec{Include: opts.Include, Exclude: opts.Exclude},
+	})
+}
+
+// Diff returns the working-tree diff of an uncommitted file (against its
+// pre-rename path for renames); committed files have no diff
+func Diff(ctx context.Context, f File) ([]DiffLine, error) {
+	switch {
+	case f.Status != "uncommitted":
+		return nil, nil
+	case f.OldFullPath != "":
+		return git.GetRenameDiff(ctx, f.GitRoot, f.OldFullPath, f.FullPath)
+	default:
+		return git.GetFileDiff(ctx, f.GitRoot, f.FullPath)
+	}
+}
+
+// Stats counts the lines Diff would show as added and deleted
+func Stats(ctx context.Context, f File) DiffStats {
+	switch {
+	case f.Status != "uncommitted":
+		return DiffStats{}
+	case f.OldFullPath != "":
+		return git.GetRenameDiffStats(ctx, f.GitRoot, f.OldFullPath, f.FullPath)
+	default:
+		return git.GetDiffStats(ctx, f.GitRoot, f.FullPath)
+	}
+}
+
+// Patch returns an uncommitted file's change as a unified diff that
+// `git apply` accepts
+func Patch(ctx context.Context, f File) (string, error) {
+	return git.GetFilePatch(ctx, f.GitRoot, f.OldFullPath, f.FullPath)
+}
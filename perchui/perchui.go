@@ -0,0 +1,109 @@
+// Package perchui exposes perch's file list and preview as a bubbletea
+// component that other programs can embed as a pane.
+//
+//	pane := perchui.New("/path/to/repo")
+//
+//	func (m app) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+//		switch msg := msg.(type) {
+//		case tea.WindowSizeMsg:
+//			m.pane.SetSize(msg.Width/2, msg.Height)
+//			return m, nil
+//		case perchui.SelectionMsg:
+//			m.status = msg.File.Path
+//		}
+//		var cmd tea.Cmd
+//		m.pane, cmd = m.pane.Update(msg)
+//		return m, cmd
+//	}
+//
+// The pane never quits the host program: q and ctrl+c are left to the
+// parent, and anything else that would quit perch sends QuitRequestMsg
+// instead. The pane saves no session and records no snapshots. Call Refresh (or send RefreshMsg) when the tree changes; perch
+// also polls every 2 seconds on its own.
+package perchui
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kateleext/perch/internal/ui"
+	"github.com/kateleext/perch/perchgit"
+)
+
+// File is one changed file as shown in the list
+type File = perchgit.File
+
+// SelectionMsg is emitted whenever the selection settles on a file
+type SelectionMsg = ui.SelectionMsg
+
+// RefreshMsg asks the pane to reload its file list
+type RefreshMsg = ui.RefreshMsg
+
+// QuitRequestMsg is sent where standalone perch would quit; handle it to
+// close the pane or exit, or ignore it
+type QuitRequestMsg = ui.QuitRequestMsg
+
+// Backend supplies the file list; the default runs git in dir (see
+// perchgit.Scan, which a Backend can wrap to filter or annotate files)
+type Backend interface {
+	Status(ctx context.Context, dir string) ([]File, error)
+}
+
+// BackendFunc adapts a plain function to Backend
+type BackendFunc func(ctx context.Context, dir string) ([]File, error)
+
+// Status calls f
+func (f BackendFunc) Status(ctx context.Context, dir string) ([]File, error) {
+	return f(ctx, dir)
+}
+
+// Option configures a Model
+type Option func(*ui.Model)
+
+// WithBackend replaces git as the source of the file list
+func WithBackend(b Backend) Option {
+	return func(m *ui.Model) {
+		m.SetStatusSource(b.Status)
+	}
+}
+
+// Model is an embeddable perch pane
+type Model struct {
+	inner ui.Model
+}
+
+// New creates a pane watching dir
+func New(dir string, opts ...Option) Model {
+	inner := ui.NewEmbedded(dir)
+	for _, opt := range opts {
+		opt(&inner)
+	}
+	return Model{inner: inner}
+}
+
+// Init starts loading the file list
+func (m Model) Init() tea.Cmd {
+	return m.inner.Init()
+}
+
+// Update handles a message; forward everything the pane should see
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	updated, cmd := m.inner.Update(msg)
+	m.inner = updated.(ui.Model)
+	return m, cmd
+}
+
+// View renders the pane at its current size
+func (m Model) View() string {
+	return m.inner.View()
+}
+
+// SetSize lays the pane out in width×height cells
+func (m *Model) SetSize(width, height int) {
+	m.inner.SetSize(width, height)
+}
+
+// Refresh returns a command that reloads the file list
+func (m Model) Refresh() tea.Cmd {
+	return func() tea.Msg { return RefreshMsg{} }
+}